@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"user-management/internal/app"
+	"user-management/internal/db"
+	"user-management/internal/rbac"
+	"user-management/internal/user"
+
+	"github.com/spf13/cobra"
+)
+
+var adminCmd = &cobra.Command{
+	Use:   "admin",
+	Short: "Administrative tasks",
+}
+
+var adminCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create an user with the admin role",
+	Long: `Create an user with the admin role.
+
+Used to bootstrap a fresh installation, as every API endpoint requires an authenticated user.
+The password is read from standard input when --password is not set.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return createAdmin(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(adminCmd)
+	adminCmd.AddCommand(adminCreateCmd)
+
+	adminCreateCmd.Flags().String("email", "", "Email of the admin user")
+	adminCreateCmd.Flags().String("first-name", "Admin", "First name of the admin user")
+	adminCreateCmd.Flags().String("last-name", "User", "Last name of the admin user")
+	adminCreateCmd.Flags().String("password", "", "Password of the admin user")
+	adminCreateCmd.MarkFlagRequired("email")
+}
+
+func createAdmin(cmd *cobra.Command) error {
+	flags := cmd.Flags()
+	email, _ := flags.GetString("email")
+	firstName, _ := flags.GetString("first-name")
+	lastName, _ := flags.GetString("last-name")
+	password, _ := flags.GetString("password")
+
+	if password == "" {
+		fmt.Fprint(cmd.ErrOrStderr(), "Password: ")
+		line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("failed to read password: %w", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	dbConn := db.Connect(cfg.Database.ConnString())
+	defer dbConn.Close()
+
+	newApp, err := app.NewApp(dbConn, cfg)
+	if err != nil {
+		return err
+	}
+
+	req := user.UserCreateRequest{
+		FirstName: firstName,
+		LastName:  lastName,
+		Email:     email,
+		Password:  password,
+	}
+
+	if err := newApp.Validator.Struct(req); err != nil {
+		return fmt.Errorf("invalid admin user: %w", err)
+	}
+
+	ctx := cmd.Context()
+
+	created, err := newApp.UserService.CreateUser(ctx, &req)
+	if err != nil {
+		return fmt.Errorf("failed to create admin user: %w", err)
+	}
+
+	// The admin is created by the operator, so there is no email to verify.
+	if err := newApp.UserService.MarkEmailVerified(ctx, created.UserId); err != nil {
+		return fmt.Errorf("failed to activate admin user: %w", err)
+	}
+
+	if err := newApp.RoleService.AssignRole(ctx, created.UserId, rbac.RoleAdmin); err != nil {
+		return fmt.Errorf("failed to assign admin role: %w", err)
+	}
+
+	fmt.Fprintf(os.Stdout, "Created admin user %s (%s)\n", created.Email, created.UserId)
+	return nil
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+	"user-management/internal/apikey"
+	"user-management/internal/app"
+	"user-management/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+var apiKeysCmd = &cobra.Command{
+	Use:   "apikeys",
+	Short: "Manage API keys",
+}
+
+var apiKeysCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Mint an API key for an user",
+	Long: `Mint an API key for an user.
+
+The key is printed once and cannot be recovered afterwards. Scopes must be
+permissions the user holds, for example --scopes instruments:read,instruments:write.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return createAPIKey(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(apiKeysCmd)
+	apiKeysCmd.AddCommand(apiKeysCreateCmd)
+
+	apiKeysCreateCmd.Flags().String("email", "", "Email of the user owning the key")
+	apiKeysCreateCmd.Flags().String("name", "", "Name describing what the key is used for")
+	apiKeysCreateCmd.Flags().StringSlice("scopes", nil, "Permissions granted to the key")
+	apiKeysCreateCmd.Flags().Duration("expires-in", 0, "Lifetime of the key, 0 for no expiry")
+	apiKeysCreateCmd.MarkFlagRequired("email")
+	apiKeysCreateCmd.MarkFlagRequired("name")
+	apiKeysCreateCmd.MarkFlagRequired("scopes")
+}
+
+func createAPIKey(cmd *cobra.Command) error {
+	flags := cmd.Flags()
+	email, _ := flags.GetString("email")
+	name, _ := flags.GetString("name")
+	scopes, _ := flags.GetStringSlice("scopes")
+	expiresIn, _ := flags.GetDuration("expires-in")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	dbConn := db.Connect(cfg.Database.ConnString())
+	defer dbConn.Close()
+
+	newApp, err := app.NewApp(dbConn, cfg)
+	if err != nil {
+		return err
+	}
+
+	req := apikey.APIKeyCreateRequest{
+		Name:   name,
+		Scopes: scopes,
+	}
+
+	if expiresIn > 0 {
+		expiresAt := time.Now().UTC().Add(expiresIn)
+		req.ExpiresAt = &expiresAt
+	}
+
+	if err := newApp.Validator.Struct(req); err != nil {
+		return fmt.Errorf("invalid api key: %w", err)
+	}
+
+	ctx := cmd.Context()
+
+	owner, err := newApp.UserService.GetUserByEmail(ctx, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("no user with email %s", email)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	created, err := newApp.APIKeyService.Create(ctx, owner.UserId, &req)
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Created API key %s (%s) for %s\n", created.Name, created.Id, owner.Email)
+	fmt.Fprintln(os.Stdout, created.Key)
+	return nil
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"user-management/internal/app"
+	"user-management/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Audit log tasks",
+}
+
+var auditVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify the hash chain of the audit log",
+	Long: `Recompute the hash chain of the audit log from the first record.
+
+Exits with an error naming the first record that does not match when a record was
+edited, inserted or removed outside of the application.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return verifyAudit(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditVerifyCmd)
+}
+
+func verifyAudit(cmd *cobra.Command) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	dbConn := db.Connect(cfg.Database.ConnString())
+	defer dbConn.Close()
+
+	newApp, err := app.NewApp(dbConn, cfg)
+	if err != nil {
+		return err
+	}
+
+	result, err := newApp.AuditService.Verify(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to verify audit log: %w", err)
+	}
+
+	if !result.Valid {
+		return fmt.Errorf("audit log hash chain is broken at record %d, %d records checked", result.BrokenAtSeq, result.Checked)
+	}
+
+	fmt.Fprintf(os.Stdout, "Audit log hash chain is intact, %d records checked\n", result.Checked)
+	return nil
+}
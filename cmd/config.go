@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"user-management/internal/config"
+
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+	Long: `Inspect the configuration read from the config file, USRM_* environment variables
+and *_FILE secret files, on top of the defaults.`,
+}
+
+var configPrintCmd = &cobra.Command{
+	Use:   "print",
+	Short: "Print the effective configuration with secrets redacted",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		return printConfig(format)
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:          "validate",
+	Short:        "Validate the configuration",
+	Long:         `Validate the configuration and list every invalid key. Exits with an error when the configuration is invalid.`,
+	SilenceUsage: true,
+	Args:         cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := loadConfig(); err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stdout, "Configuration is valid")
+		return nil
+	},
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the config file",
+	Long:  `Print the JSON Schema of the config file, for editors to complete and check config.yaml.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return writeJSON(config.Schema())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configPrintCmd, configValidateCmd, configSchemaCmd)
+	configPrintCmd.Flags().String("format", "yaml", "Output format, yaml or json")
+}
+
+func printConfig(format string) error {
+	cfg, err := readConfig()
+	if err != nil {
+		return err
+	}
+
+	values := config.Redacted(*cfg)
+	switch format {
+	case "yaml":
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		if err := enc.Encode(values); err != nil {
+			return err
+		}
+		return enc.Close()
+	case "json":
+		return writeJSON(values)
+	default:
+		return fmt.Errorf("unknown format %q, expected yaml or json", format)
+	}
+}
+
+func writeJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"user-management/internal/app"
+	httputils "user-management/internal/common/httputils"
+	"user-management/internal/db"
+	"user-management/internal/instrument"
+	"user-management/internal/transfer"
+
+	"github.com/spf13/cobra"
+)
+
+var instrumentsCmd = &cobra.Command{
+	Use:   "instruments",
+	Short: "Import and export instruments",
+}
+
+var instrumentsImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Create and update instruments from a CSV or NDJSON file",
+	Long: `Create and update instruments from a CSV or NDJSON file, - reads standard input.
+
+Records are matched to instruments by symbol. Instruments that do not exist are created,
+existing instruments are updated where the record differs, so a reference list kept in a
+spreadsheet can be synced by importing its CSV export. An empty exchange and price keep
+the values of an existing instrument. The columns are symbol, name, type, exchange and
+last_price, further columns, like those of an export, are ignored.
+
+Records are validated like requests to the API. Invalid records are rejected and, with
+--rejects, written to a report with the reason, the other records are still imported.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return importInstruments(cmd, args[0])
+	},
+}
+
+var instrumentsExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write instruments to a CSV or NDJSON file",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return exportInstruments(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(instrumentsCmd)
+	instrumentsCmd.AddCommand(instrumentsImportCmd)
+	instrumentsCmd.AddCommand(instrumentsExportCmd)
+
+	addImportFlags(instrumentsImportCmd)
+	addExportFlags(instrumentsExportCmd)
+}
+
+func importInstruments(cmd *cobra.Command, path string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	dbConn := db.Connect(cfg.Database.ConnString())
+	defer dbConn.Close()
+
+	newApp, err := app.NewApp(dbConn, cfg)
+	if err != nil {
+		return err
+	}
+
+	return runImport(cmd, path, "instruments", func(ctx context.Context, rec transfer.Record) (transfer.Action, error) {
+		i, err := instrument.ImportFromRecord(rec)
+		if err != nil {
+			return transfer.Rejected, err
+		}
+		if err := httputils.Validate(&i, newApp.Validator); err != nil {
+			return transfer.Rejected, err
+		}
+		return newApp.InstrumentService.ImportInstrument(ctx, &i, dryRun)
+	})
+}
+
+func exportInstruments(cmd *cobra.Command) error {
+	flags := cmd.Flags()
+	filter, _ := flags.GetString("filter")
+	includeDeleted, _ := flags.GetBool("include-deleted")
+
+	values, err := url.ParseQuery(filter)
+	if err != nil {
+		return fmt.Errorf("invalid filter: %w", err)
+	}
+	f, err := instrument.ParseFilter(values)
+	if err != nil {
+		return fmt.Errorf("invalid filter: %w", err)
+	}
+	f.IncludeDeleted = includeDeleted
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	dbConn := db.Connect(cfg.Database.ConnString())
+	defer dbConn.Close()
+
+	newApp, err := app.NewApp(dbConn, cfg)
+	if err != nil {
+		return err
+	}
+
+	return runExport(cmd, "instruments", instrument.ExportColumns, func(ctx context.Context, fn func(instrument.Instrument) error) error {
+		return newApp.InstrumentService.ExportInstruments(ctx, f, fn)
+	})
+}
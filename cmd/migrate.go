@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+	"user-management/internal/db"
+	"user-management/internal/db/migrate"
+	"user-management/internal/db/migrations"
+
+	"github.com/spf13/cobra"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate the database schema",
+	Long: `Apply and revert the migrations embedded in the binary.
+
+The applied versions are recorded in the SCHEMA_VERSIONS table. Concurrent runs wait for
+each other, so several instances can migrate on startup with serve --auto-migrate.`,
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply all pending migrations",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMigrator(cmd, func(ctx context.Context, m *migrate.Migrator) ([]migrate.Step, error) {
+			return m.Up(ctx)
+		})
+	},
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Revert the most recent migrations",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		steps, _ := cmd.Flags().GetInt("steps")
+		return runMigrator(cmd, func(ctx context.Context, m *migrate.Migrator) ([]migrate.Step, error) {
+			return m.Down(ctx, steps)
+		})
+	},
+}
+
+var migrateToCmd = &cobra.Command{
+	Use:   "to <version>",
+	Short: "Apply or revert migrations until the given version, 0 reverts all",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[0])
+		}
+		return runMigrator(cmd, func(ctx context.Context, m *migrate.Migrator) ([]migrate.Step, error) {
+			return m.To(ctx, version)
+		})
+	},
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "List the migrations and when they were applied",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return migrationStatus(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	migrateCmd.AddCommand(migrateUpCmd, migrateDownCmd, migrateToCmd, migrateStatusCmd)
+	migrateDownCmd.Flags().Int("steps", 1, "Number of migrations to revert")
+}
+
+// newMigrator returns a migrator of the embedded migrations.
+func newMigrator(dbConn *sql.DB) (*migrate.Migrator, error) {
+	return migrate.New(dbConn, migrations.FS)
+}
+
+func runMigrator(cmd *cobra.Command, run func(ctx context.Context, m *migrate.Migrator) ([]migrate.Step, error)) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	dbConn := db.Connect(cfg.Database.ConnString())
+	defer dbConn.Close()
+
+	m, err := newMigrator(dbConn)
+	if err != nil {
+		return err
+	}
+
+	steps, err := run(cmd.Context(), m)
+	for _, step := range steps {
+		verb := "Applied"
+		if step.Direction == migrate.Down {
+			verb = "Reverted"
+		}
+		fmt.Fprintf(os.Stdout, "%s %04d_%s\n", verb, step.Migration.Version, step.Migration.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	if len(steps) == 0 {
+		fmt.Fprintln(os.Stdout, "Database is up to date")
+	}
+	return nil
+}
+
+func migrationStatus(cmd *cobra.Command) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	dbConn := db.Connect(cfg.Database.ConnString())
+	defer dbConn.Close()
+
+	m, err := newMigrator(dbConn)
+	if err != nil {
+		return err
+	}
+
+	statuses, err := m.Status(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to read migration status: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, s := range statuses {
+		name, appliedAt := s.Name, "pending"
+		if name == "" {
+			name = "(unknown to this build)"
+		}
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, name, appliedAt)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+	"user-management/internal/app"
+	"user-management/internal/config"
+	"user-management/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+var purgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Hard delete soft deleted users and instruments",
+	Long: `Hard delete users and instruments that were soft deleted longer ago than the retention period.
+
+Purged rows cannot be restored. Data belonging to purged users, such as tokens, API keys and
+status history, is removed with them. Expired idempotency keys are removed as well.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return purge(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(purgeCmd)
+	purgeCmd.Flags().Duration("retention.softDeleted", config.Default().Retention.SoftDeleted, "How long soft deleted rows are kept")
+}
+
+func purge(cmd *cobra.Command) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	if cfg.Retention.SoftDeleted <= 0 {
+		return fmt.Errorf("retention.softDeleted must be positive, got %s", cfg.Retention.SoftDeleted)
+	}
+
+	dbConn := db.Connect(cfg.Database.ConnString())
+	defer dbConn.Close()
+
+	newApp, err := app.NewApp(dbConn, cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	deletedBefore := time.Now().UTC().Add(-cfg.Retention.SoftDeleted)
+
+	users, err := newApp.UserService.PurgeDeleted(ctx, deletedBefore)
+	if err != nil {
+		return fmt.Errorf("failed to purge users: %w", err)
+	}
+
+	instruments, err := newApp.InstrumentService.PurgeDeleted(ctx, deletedBefore)
+	if err != nil {
+		return fmt.Errorf("failed to purge instruments: %w", err)
+	}
+
+	keys, err := newApp.IdempotencyStore.PurgeExpired(ctx, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to purge idempotency keys: %w", err)
+	}
+
+	fmt.Fprintf(os.Stdout, "Purged %d users and %d instruments deleted before %s\n", users, instruments, deletedBefore.Format(time.RFC3339))
+	fmt.Fprintf(os.Stdout, "Purged %d expired idempotency keys\n", keys)
+	return nil
+}
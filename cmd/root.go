@@ -6,11 +6,16 @@ import (
 	"log/slog"
 	"os"
 	"strings"
+	"user-management/internal/config"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+// envPrefix prefixes the environment variables overriding config keys, for
+// example USRM_SERVER_PORT.
+const envPrefix = "USRM"
+
 var cfgFile string
 
 var rootCmd = &cobra.Command{
@@ -30,12 +35,17 @@ func Execute() {
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default locations: ., $HOME/.user-management/)")
+	rootCmd.PersistentFlags().String("database.dsn", config.Default().Database.Dsn, "DB DSN")
 }
 
 func initializeConfig(cmd *cobra.Command) error {
-	viper.SetEnvPrefix("USRM")
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "*", "-", "*"))
+	viper.SetEnvPrefix(envPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
 	viper.AutomaticEnv()
+	// Viper only reads the environment variables of keys it knows of.
+	for key, value := range config.Keys() {
+		viper.SetDefault(key, value)
+	}
 
 	if cfgFile != "" {
 		viper.SetConfigFile(cfgFile)
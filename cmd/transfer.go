@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"user-management/internal/transfer"
+
+	"github.com/spf13/cobra"
+)
+
+// exportProgressEvery is the number of rows between progress reports of
+// exports.
+const exportProgressEvery = 10000
+
+func addImportFlags(cmd *cobra.Command) {
+	cmd.Flags().String("format", "", "File format, csv or ndjson, taken from the file extension when unset")
+	cmd.Flags().Bool("dry-run", false, "Validate the file and report what would change without writing")
+	cmd.Flags().String("rejects", "", "File the rejected records are written to, with the reason they were rejected")
+}
+
+func addExportFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("output", "o", "-", "File to write, - for standard output")
+	cmd.Flags().String("format", "", "File format, csv or ndjson, taken from the file extension when unset and csv for standard output")
+	cmd.Flags().String("filter", "", "Filter and sort with the query parameters of the list endpoint, for example \"status=Active&sort=email\"")
+	cmd.Flags().Bool("include-deleted", false, "Also export soft deleted rows")
+}
+
+// runImport imports the file at path, - for standard input, applying each
+// record with apply. Progress is written to standard error. It fails when
+// records were rejected, so scripts notice incomplete imports.
+func runImport(cmd *cobra.Command, path string, name string, apply func(ctx context.Context, rec transfer.Record) (transfer.Action, error)) error {
+	flags := cmd.Flags()
+	formatName, _ := flags.GetString("format")
+	dryRun, _ := flags.GetBool("dry-run")
+	rejectsPath, _ := flags.GetString("rejects")
+
+	format, err := transfer.ParseFormat(formatName, path)
+	if err != nil {
+		return err
+	}
+
+	in := cmd.InOrStdin()
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+
+	var rejects *transfer.Rejects
+	if rejectsPath != "" {
+		file, err := os.Create(rejectsPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		rejects = transfer.NewRejects(format, file)
+	}
+
+	progress := func(s transfer.Summary) {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Importing %s: %s\n", name, s)
+	}
+
+	summary, err := transfer.Import(cmd.Context(), transfer.NewReader(format, in), rejects, apply, progress)
+	if rejects != nil {
+		if err := rejects.Flush(); err != nil {
+			return fmt.Errorf("failed to write rejected records: %w", err)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("import stopped after %s: %w", summary, err)
+	}
+
+	if dryRun {
+		fmt.Fprintf(cmd.OutOrStdout(), "Dry run of %s import, nothing was written: %s\n", name, summary)
+	} else {
+		fmt.Fprintf(cmd.OutOrStdout(), "Imported %s: %s\n", name, summary)
+	}
+
+	if rejected := summary.Actions[transfer.Rejected]; rejected > 0 {
+		if rejectsPath == "" {
+			return fmt.Errorf("%d records were rejected, set --rejects to see why", rejected)
+		}
+		return fmt.Errorf("%d records were rejected, see %s", rejected, rejectsPath)
+	}
+	return nil
+}
+
+// runExport writes the rows export passes to its callback with the given
+// columns.
+func runExport[T any](cmd *cobra.Command, name string, columns []transfer.Column[T], export func(ctx context.Context, fn func(T) error) error) error {
+	flags := cmd.Flags()
+	path, _ := flags.GetString("output")
+	formatName, _ := flags.GetString("format")
+
+	format := transfer.CSV
+	if formatName != "" || path != "-" {
+		var err error
+		if format, err = transfer.ParseFormat(formatName, path); err != nil {
+			return err
+		}
+	}
+
+	var out io.Writer = cmd.OutOrStdout()
+	if path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	w := transfer.NewWriter(format, out, columns)
+	exported := 0
+	err := export(cmd.Context(), func(v T) error {
+		exported++
+		if exported%exportProgressEvery == 0 {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Exported %d %s\n", exported, name)
+		}
+		return w.Write(v)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export %s: %w", name, err)
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Exported %d %s\n", exported, name)
+	return nil
+}
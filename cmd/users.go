@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"user-management/internal/app"
+	httputils "user-management/internal/common/httputils"
+	"user-management/internal/db"
+	"user-management/internal/transfer"
+	"user-management/internal/user"
+
+	"github.com/spf13/cobra"
+)
+
+var usersCmd = &cobra.Command{
+	Use:   "users",
+	Short: "Import and export users",
+}
+
+var usersImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Create and update users from a CSV or NDJSON file",
+	Long: `Create and update users from a CSV or NDJSON file, - reads standard input.
+
+Records are matched to users by email. Users that do not exist are created with the
+default status, existing users are updated where the record differs. Empty values keep
+the value of an existing user. The columns are firstName, lastName, email, phone, age
+and status, further columns, like those of an export, are ignored.
+
+Records are validated like requests to the API. Invalid records are rejected and, with
+--rejects, written to a report with the reason, the other records are still imported.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return importUsers(cmd, args[0])
+	},
+}
+
+var usersExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write users to a CSV or NDJSON file",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return exportUsers(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(usersCmd)
+	usersCmd.AddCommand(usersImportCmd)
+	usersCmd.AddCommand(usersExportCmd)
+
+	addImportFlags(usersImportCmd)
+	addExportFlags(usersExportCmd)
+}
+
+func importUsers(cmd *cobra.Command, path string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	dbConn := db.Connect(cfg.Database.ConnString())
+	defer dbConn.Close()
+
+	newApp, err := app.NewApp(dbConn, cfg)
+	if err != nil {
+		return err
+	}
+
+	return runImport(cmd, path, "users", func(ctx context.Context, rec transfer.Record) (transfer.Action, error) {
+		u, err := user.ImportFromRecord(rec)
+		if err != nil {
+			return transfer.Rejected, err
+		}
+		if err := httputils.Validate(&u, newApp.Validator); err != nil {
+			return transfer.Rejected, err
+		}
+		return newApp.UserService.ImportUser(ctx, &u, dryRun)
+	})
+}
+
+func exportUsers(cmd *cobra.Command) error {
+	flags := cmd.Flags()
+	filter, _ := flags.GetString("filter")
+	includeDeleted, _ := flags.GetBool("include-deleted")
+
+	values, err := url.ParseQuery(filter)
+	if err != nil {
+		return fmt.Errorf("invalid filter: %w", err)
+	}
+	f, err := user.ParseFilter(values)
+	if err != nil {
+		return fmt.Errorf("invalid filter: %w", err)
+	}
+	f.IncludeDeleted = includeDeleted
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	dbConn := db.Connect(cfg.Database.ConnString())
+	defer dbConn.Close()
+
+	newApp, err := app.NewApp(dbConn, cfg)
+	if err != nil {
+		return err
+	}
+
+	return runExport(cmd, "users", user.ExportColumns, func(ctx context.Context, fn func(user.User) error) error {
+		return newApp.UserService.ExportUsers(ctx, f, fn)
+	})
+}
@@ -0,0 +1,159 @@
+package account
+
+import (
+	"log/slog"
+	"net/http"
+	httputils "user-management/internal/common/httputils"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type Handler struct {
+	service  *Service
+	validate *validator.Validate
+}
+
+func NewHandler(service *Service, validate *validator.Validate) *Handler {
+	return &Handler{
+		service:  service,
+		validate: validate,
+	}
+}
+
+// RequestPasswordReset godoc
+// @Summary Request a password reset
+// @Description Mail a password reset link to the user. Always answers 202, whether the email belongs to an user or not
+// @Tags account
+// @Accept  json
+// @Produce  json
+// @Param request body PasswordResetRequest true "Email"
+// @Success 202
+// @Failure      400  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Router /auth/password-reset [post]
+func (h *Handler) RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req PasswordResetRequest
+	if err := httputils.DecodeAndValidateRequest(r, &req, h.validate); err != nil {
+		slog.WarnContext(r.Context(), "Password reset request failed", "error", err)
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	if err := h.service.RequestPasswordReset(r.Context(), req.Email); err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ConfirmPasswordReset godoc
+// @Summary Reset a password
+// @Description Set a new password with the token from the reset link. Ends every session of the user
+// @Tags account
+// @Accept  json
+// @Produce  json
+// @Param request body PasswordResetConfirmRequest true "Token and new password"
+// @Success 204
+// @Failure      400  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Router /auth/password-reset/confirm [post]
+func (h *Handler) ConfirmPasswordReset(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req PasswordResetConfirmRequest
+	if err := httputils.DecodeAndValidateRequest(r, &req, h.validate); err != nil {
+		slog.WarnContext(r.Context(), "Password reset failed", "error", err)
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	err := h.service.ResetPassword(r.Context(), req.Token, req.Password)
+	writeTokenResult(w, r, err)
+}
+
+// VerifyEmail godoc
+// @Summary Verify an email address
+// @Description Activate the user with the token from the verification mail
+// @Tags account
+// @Accept  json
+// @Produce  json
+// @Param request body VerifyEmailRequest true "Token"
+// @Success 204
+// @Failure      400  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Router /auth/verify-email [post]
+func (h *Handler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req VerifyEmailRequest
+	if err := httputils.DecodeAndValidateRequest(r, &req, h.validate); err != nil {
+		slog.WarnContext(r.Context(), "Email verification failed", "error", err)
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	err := h.service.VerifyEmail(r.Context(), req.Token)
+	writeTokenResult(w, r, err)
+}
+
+// VerifyEmailLink godoc
+// @Summary Verify an email address from the link
+// @Description Target of the link in the verification mail
+// @Tags account
+// @Produce  json
+// @Param token query string true "Token"
+// @Success 204
+// @Failure      400  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Router /auth/verify-email [get]
+func (h *Handler) VerifyEmailLink(w http.ResponseWriter, r *http.Request) {
+
+	raw := r.URL.Query().Get("token")
+	if raw == "" {
+		httputils.WriteError(w, http.StatusBadRequest, "Missing token", r)
+		return
+	}
+
+	err := h.service.VerifyEmail(r.Context(), raw)
+	writeTokenResult(w, r, err)
+}
+
+// ResendVerification godoc
+// @Summary Resend the verification mail
+// @Description Mail a new verification link to an user waiting for verification. Always answers 202
+// @Tags account
+// @Accept  json
+// @Produce  json
+// @Param request body ResendVerificationRequest true "Email"
+// @Success 202
+// @Failure      400  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Router /auth/verify-email/resend [post]
+func (h *Handler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req ResendVerificationRequest
+	if err := httputils.DecodeAndValidateRequest(r, &req, h.validate); err != nil {
+		slog.WarnContext(r.Context(), "Resending verification failed", "error", err)
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	if err := h.service.ResendVerification(r.Context(), req.Email); err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func writeTokenResult(w http.ResponseWriter, r *http.Request, err error) {
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,67 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"user-management/internal/db/sqlc"
+
+	"github.com/google/uuid"
+)
+
+type Repository struct {
+	queries *sqlc.Queries
+}
+
+func NewRepository(q *sqlc.Queries) *Repository {
+	return &Repository{queries: q}
+}
+
+func (r *Repository) Create(ctx context.Context, userId uuid.UUID, purpose string, tokenHash string, expiresAt time.Time) error {
+
+	params := sqlc.CreateAccountTokenParams{
+		ID:        uuid.New(),
+		UserID:    userId,
+		Purpose:   purpose,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	return r.queries.CreateAccountToken(ctx, params)
+}
+
+func (r *Repository) GetByHash(ctx context.Context, purpose string, tokenHash string) (sqlc.AccountToken, error) {
+
+	params := sqlc.FindAccountTokenByHashParams{
+		TokenHash: tokenHash,
+		Purpose:   purpose,
+	}
+
+	return r.queries.FindAccountTokenByHash(ctx, params)
+}
+
+// Use marks the token as used and reports whether it was still unused.
+func (r *Repository) Use(ctx context.Context, id uuid.UUID) (bool, error) {
+
+	params := sqlc.UseAccountTokenParams{
+		UsedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+		ID:     id,
+	}
+
+	rows, err := r.queries.UseAccountToken(ctx, params)
+	return rows > 0, err
+}
+
+// InvalidateAll marks every unused token of the user for the purpose as used,
+// so only the latest link sent works.
+func (r *Repository) InvalidateAll(ctx context.Context, userId uuid.UUID, purpose string) error {
+
+	params := sqlc.InvalidateAccountTokensParams{
+		UsedAt:  sql.NullTime{Time: time.Now().UTC(), Valid: true},
+		UserID:  userId,
+		Purpose: purpose,
+	}
+
+	return r.queries.InvalidateAccountTokens(ctx, params)
+}
@@ -0,0 +1,221 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+	"user-management/internal/common/apperror"
+	"user-management/internal/config"
+	"user-management/internal/mail"
+	"user-management/internal/user"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultEmailVerificationTTL = 48 * time.Hour
+	defaultPasswordResetTTL     = time.Hour
+
+	defaultVerifyEmailURL   = "http://localhost:8080/auth/verify-email"
+	defaultResetPasswordURL = "http://localhost:8080/reset-password"
+
+	mailTimeout = time.Minute
+)
+
+var ErrInvalidAccountToken = apperror.New(apperror.Validation, "invalid_token", "invalid or expired token")
+
+// Service runs the email verification and password reset flows. Both send a
+// link with a single use token, which is only stored as a SHA-256 hash.
+type Service struct {
+	repo   *Repository
+	users  *user.Service
+	mailer mail.Mailer
+
+	verificationTTL  time.Duration
+	resetTTL         time.Duration
+	verifyEmailURL   string
+	resetPasswordURL string
+}
+
+func NewService(repo *Repository, users *user.Service, mailer mail.Mailer, cfg *config.Config) *Service {
+	s := &Service{
+		repo:             repo,
+		users:            users,
+		mailer:           mailer,
+		verificationTTL:  cfg.Auth.EmailVerificationTTL,
+		resetTTL:         cfg.Auth.PasswordResetTTL,
+		verifyEmailURL:   cfg.Mail.VerifyEmailURL,
+		resetPasswordURL: cfg.Mail.ResetPasswordURL,
+	}
+
+	if s.verificationTTL <= 0 {
+		s.verificationTTL = defaultEmailVerificationTTL
+	}
+	if s.resetTTL <= 0 {
+		s.resetTTL = defaultPasswordResetTTL
+	}
+	if s.verifyEmailURL == "" {
+		s.verifyEmailURL = defaultVerifyEmailURL
+	}
+	if s.resetPasswordURL == "" {
+		s.resetPasswordURL = defaultResetPasswordURL
+	}
+
+	return s
+}
+
+// SendVerification mails a verification link to an user waiting for
+// verification. Links sent before stop working.
+func (s *Service) SendVerification(ctx context.Context, u user.User) error {
+	if u.Status != user.PendingVerification {
+		return nil
+	}
+
+	raw, err := s.issue(ctx, u.UserId, PurposeEmailVerification, s.verificationTTL)
+	if err != nil {
+		return err
+	}
+
+	s.deliver(ctx, mail.Message{
+		To:      u.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hello %s,\n\nverify your email address by opening the link below. It expires in %s.\n\n%s\n",
+			u.FirstName, s.verificationTTL, link(s.verifyEmailURL, raw)),
+	})
+	return nil
+}
+
+// ResendVerification sends a new verification link. Unknown emails are ignored
+// so the response does not reveal which accounts exist.
+func (s *Service) ResendVerification(ctx context.Context, email string) error {
+	u, err := s.users.GetUserByEmail(ctx, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.SendVerification(ctx, u)
+}
+
+func (s *Service) VerifyEmail(ctx context.Context, raw string) error {
+	userId, err := s.consume(ctx, PurposeEmailVerification, raw)
+	if err != nil {
+		return err
+	}
+
+	return s.users.MarkEmailVerified(ctx, userId)
+}
+
+// RequestPasswordReset mails a reset link to an active user. Unknown emails
+// are ignored so the response does not reveal which accounts exist.
+func (s *Service) RequestPasswordReset(ctx context.Context, email string) error {
+	u, err := s.users.GetUserByEmail(ctx, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if u.Status != user.Active {
+		slog.InfoContext(ctx, "Password reset requested for inactive user", "userId", u.UserId)
+		return nil
+	}
+
+	raw, err := s.issue(ctx, u.UserId, PurposePasswordReset, s.resetTTL)
+	if err != nil {
+		return err
+	}
+
+	s.deliver(ctx, mail.Message{
+		To:      u.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hello %s,\n\nreset your password by opening the link below. It expires in %s.\n"+
+			"If you did not ask for a new password, ignore this email.\n\n%s\n",
+			u.FirstName, s.resetTTL, link(s.resetPasswordURL, raw)),
+	})
+	return nil
+}
+
+// ResetPassword sets a new password with a reset token. Like every password
+// change, it ends every session of the user.
+func (s *Service) ResetPassword(ctx context.Context, raw string, password string) error {
+	userId, err := s.consume(ctx, PurposePasswordReset, raw)
+	if err != nil {
+		return err
+	}
+
+	return s.users.SetPassword(ctx, userId.String(), password)
+}
+
+func (s *Service) issue(ctx context.Context, userId uuid.UUID, purpose string, ttl time.Duration) (string, error) {
+	if err := s.repo.InvalidateAll(ctx, userId, purpose); err != nil {
+		return "", err
+	}
+
+	raw, err := generateToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.repo.Create(ctx, userId, purpose, hashToken(raw), time.Now().UTC().Add(ttl)); err != nil {
+		return "", err
+	}
+
+	return raw, nil
+}
+
+func (s *Service) consume(ctx context.Context, purpose string, raw string) (uuid.UUID, error) {
+	stored, err := s.repo.GetByHash(ctx, purpose, hashToken(raw))
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, ErrInvalidAccountToken
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if stored.UsedAt.Valid || time.Now().UTC().After(stored.ExpiresAt) {
+		return uuid.Nil, ErrInvalidAccountToken
+	}
+
+	used, err := s.repo.Use(ctx, stored.ID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !used {
+		return uuid.Nil, ErrInvalidAccountToken
+	}
+
+	return stored.UserID, nil
+}
+
+// deliver sends the mail in the background, so slow mail servers do not hold up
+// the request and response times do not reveal whether a mail was sent.
+func (s *Service) deliver(ctx context.Context, msg mail.Message) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), mailTimeout)
+
+	go func() {
+		defer cancel()
+		if err := s.mailer.Send(ctx, msg); err != nil {
+			slog.Error("Failed to send mail", "subject", msg.Subject, "error", err)
+		}
+	}()
+}
+
+func link(base string, raw string) string {
+	u, err := url.Parse(base)
+	if err != nil {
+		return base + "?token=" + url.QueryEscape(raw)
+	}
+
+	q := u.Query()
+	q.Set("token", raw)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
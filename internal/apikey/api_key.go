@@ -0,0 +1,63 @@
+package apikey
+
+import (
+	"strings"
+	"time"
+	"user-management/internal/db/sqlc"
+
+	"github.com/google/uuid"
+)
+
+type APIKey struct {
+	Id         uuid.UUID  `json:"id"`
+	UserId     uuid.UUID  `json:"userId"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// CreatedAPIKey carries the plaintext key, which is only returned once on creation.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+func FromSQLC(k sqlc.ApiKey) APIKey {
+	return APIKey{
+		Id:         k.ID,
+		UserId:     k.UserID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     splitScopes(k.Scopes),
+		ExpiresAt:  nullTime(k.ExpiresAt.Time, k.ExpiresAt.Valid),
+		LastUsedAt: nullTime(k.LastUsedAt.Time, k.LastUsedAt.Valid),
+		CreatedAt:  k.CreatedAt,
+	}
+}
+
+func FromSQLCList(keys []sqlc.ApiKey) []APIKey {
+	mapped := make([]APIKey, len(keys))
+	for i, k := range keys {
+		mapped[i] = FromSQLC(k)
+	}
+	return mapped
+}
+
+// Scopes are stored as a space separated list, like OAuth scopes.
+func joinScopes(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
+
+func splitScopes(scopes string) []string {
+	return strings.Fields(scopes)
+}
+
+func nullTime(t time.Time, valid bool) *time.Time {
+	if !valid {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,9 @@
+package apikey
+
+import "time"
+
+type APIKeyCreateRequest struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,required"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
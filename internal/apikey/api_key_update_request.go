@@ -0,0 +1,6 @@
+package apikey
+
+type APIKeyUpdateRequest struct {
+	Name   string   `json:"name,omitempty" validate:"omitempty,max=100"`
+	Scopes []string `json:"scopes,omitempty" validate:"omitempty,dive,required"`
+}
@@ -0,0 +1,230 @@
+package apikey
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	httputils "user-management/internal/common/httputils"
+	"user-management/internal/middleware"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	service  *Service
+	validate *validator.Validate
+}
+
+func NewHandler(service *Service, validate *validator.Validate) *Handler {
+	return &Handler{
+		service:  service,
+		validate: validate,
+	}
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Create an API key owned by the current user. The key is only returned in this response
+// @Tags api-keys
+// @Accept  json
+// @Produce  json
+// @Param request body APIKeyCreateRequest true "API key"
+// @Success 201 {object} CreatedAPIKey
+// @Failure      400  {object}  httputils.Problem
+// @Failure      403  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Router /api-keys [post]
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	owner, ok := keyOwner(w, r)
+	if !ok {
+		return
+	}
+
+	var req APIKeyCreateRequest
+	if err := httputils.DecodeAndValidateRequest(r, &req, h.validate); err != nil {
+		slog.WarnContext(r.Context(), "API key creation failed", "error", err)
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	created, err := h.service.Create(r.Context(), owner, &req)
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// GetAPIKeys godoc
+// @Summary Get API keys
+// @Description Get the API keys of the current user
+// @Tags api-keys
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} APIKey
+// @Failure      403  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Router /api-keys [get]
+func (h *Handler) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+
+	owner, ok := keyOwner(w, r)
+	if !ok {
+		return
+	}
+
+	keys, err := h.service.List(r.Context(), owner)
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(keys)
+}
+
+// GetAPIKeyById godoc
+// @Summary Get an API key
+// @Description Get an API key of the current user by ID
+// @Tags api-keys
+// @Accept  json
+// @Produce  json
+// @Param id path string true "API key ID"
+// @Success 200 {object} APIKey
+// @Failure      400  {object}  httputils.Problem
+// @Failure      403  {object}  httputils.Problem
+// @Failure      404  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Router /api-keys/{id} [get]
+func (h *Handler) GetAPIKeyById(w http.ResponseWriter, r *http.Request) {
+
+	owner, ok := keyOwner(w, r)
+	if !ok {
+		return
+	}
+
+	id, uuiderr := httputils.ParseUUIDFromURL(r, "id")
+	if uuiderr != nil {
+		httputils.WriteError(w, http.StatusBadRequest, "Invalid API key ID format", r)
+		return
+	}
+
+	key, err := h.service.Get(r.Context(), owner, id)
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(key)
+}
+
+// UpdateAPIKeyById godoc
+// @Summary Update an API key
+// @Description Rename an API key of the current user or replace its scopes
+// @Tags api-keys
+// @Accept  json
+// @Produce  json
+// @Param id path string true "API key ID"
+// @Param request body APIKeyUpdateRequest true "Fields to update"
+// @Success 200 {object} APIKey
+// @Failure      400  {object}  httputils.Problem
+// @Failure      403  {object}  httputils.Problem
+// @Failure      404  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Router /api-keys/{id} [patch]
+func (h *Handler) UpdateAPIKeyById(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	owner, ok := keyOwner(w, r)
+	if !ok {
+		return
+	}
+
+	id, uuiderr := httputils.ParseUUIDFromURL(r, "id")
+	if uuiderr != nil {
+		httputils.WriteError(w, http.StatusBadRequest, "Invalid API key ID format", r)
+		return
+	}
+
+	var req APIKeyUpdateRequest
+	if err := httputils.DecodeAndValidateRequest(r, &req, h.validate); err != nil {
+		slog.WarnContext(r.Context(), "API key update failed", "error", err)
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	updated, err := h.service.Update(r.Context(), owner, id, &req)
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updated)
+}
+
+// DeleteAPIKeyById godoc
+// @Summary Revoke an API key
+// @Description Delete an API key of the current user. Requests using it are rejected immediately
+// @Tags api-keys
+// @Accept  json
+// @Produce  json
+// @Param id path string true "API key ID"
+// @Success 204
+// @Failure      400  {object}  httputils.Problem
+// @Failure      403  {object}  httputils.Problem
+// @Failure      404  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Router /api-keys/{id} [delete]
+func (h *Handler) DeleteAPIKeyById(w http.ResponseWriter, r *http.Request) {
+
+	owner, ok := keyOwner(w, r)
+	if !ok {
+		return
+	}
+
+	id, uuiderr := httputils.ParseUUIDFromURL(r, "id")
+	if uuiderr != nil {
+		httputils.WriteError(w, http.StatusBadRequest, "Invalid API key ID format", r)
+		return
+	}
+
+	if err := h.service.Revoke(r.Context(), owner, id); err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// keyOwner returns the user whose keys are managed. Keys can only be managed
+// with an access token, so a leaked key cannot mint more keys.
+func keyOwner(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	principal, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		httputils.WriteError(w, http.StatusUnauthorized, "Missing credentials", r)
+		return uuid.Nil, false
+	}
+
+	if principal.APIKeyID != uuid.Nil {
+		httputils.WriteError(w, http.StatusForbidden, "API keys cannot manage API keys", r)
+		return uuid.Nil, false
+	}
+
+	return principal.UserID, true
+}
@@ -0,0 +1,62 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// Keys look like usrm_<prefix>_<secret>. The prefix is stored in plain text to
+// find the key, the whole key is only stored as a SHA-256 hash.
+const keyPrefix = "usrm_"
+
+const (
+	prefixBytes = 6
+	secretBytes = 32
+)
+
+func generateKey() (key string, prefix string, err error) {
+	p := make([]byte, prefixBytes)
+	if _, err := rand.Read(p); err != nil {
+		return "", "", err
+	}
+
+	s := make([]byte, secretBytes)
+	if _, err := rand.Read(s); err != nil {
+		return "", "", err
+	}
+
+	prefix = hex.EncodeToString(p)
+	return keyPrefix + prefix + "_" + base64.RawURLEncoding.EncodeToString(s), prefix, nil
+}
+
+// parseKey returns the lookup prefix of a key, or false if it is not formatted as one.
+func parseKey(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, keyPrefix)
+	if !ok {
+		return "", false
+	}
+
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || len(prefix) != 2*prefixBytes || secret == "" {
+		return "", false
+	}
+
+	if _, err := hex.DecodeString(prefix); err != nil {
+		return "", false
+	}
+
+	return prefix, true
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func matchesHash(key string, keyHash string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashKey(key)), []byte(keyHash)) == 1
+}
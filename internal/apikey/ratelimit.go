@@ -0,0 +1,32 @@
+package apikey
+
+import (
+	"net/http"
+	"time"
+	"user-management/internal/middleware"
+
+	"github.com/go-chi/httprate"
+	"github.com/google/uuid"
+)
+
+// RateLimit limits the requests sent with an API key per key, so a key used
+// from several addresses does not get a budget per address. It must be
+// mounted after middleware.Authenticate, only keys it verified are counted.
+// Requests authenticated otherwise pass through.
+func RateLimit(requests int, window time.Duration) func(http.Handler) http.Handler {
+	limit := httprate.Limit(requests, window, httprate.WithKeyFuncs(func(r *http.Request) (string, error) {
+		principal, _ := middleware.PrincipalFrom(r.Context())
+		return "apikey:" + principal.APIKeyID.String(), nil
+	}))
+
+	return func(next http.Handler) http.Handler {
+		limited := limit(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if principal, ok := middleware.PrincipalFrom(r.Context()); ok && principal.APIKeyID != uuid.Nil {
+				limited.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"user-management/internal/db/sqlc"
+
+	"github.com/google/uuid"
+)
+
+type Repository struct {
+	queries *sqlc.Queries
+}
+
+func NewRepository(q *sqlc.Queries) *Repository {
+	return &Repository{queries: q}
+}
+
+func (r *Repository) Create(ctx context.Context, userId uuid.UUID, name string, prefix string, keyHash string, scopes []string, expiresAt *time.Time) (sqlc.ApiKey, error) {
+
+	params := sqlc.CreateApiKeyParams{
+		ID:        uuid.New(),
+		UserID:    userId,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   keyHash,
+		Scopes:    joinScopes(scopes),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if expiresAt != nil {
+		params.ExpiresAt = sql.NullTime{Time: expiresAt.UTC(), Valid: true}
+	}
+
+	return r.queries.CreateApiKey(ctx, params)
+}
+
+func (r *Repository) GetByPrefix(ctx context.Context, prefix string) (sqlc.ApiKey, error) {
+	return r.queries.FindApiKeyByPrefix(ctx, prefix)
+}
+
+func (r *Repository) GetById(ctx context.Context, userId uuid.UUID, id uuid.UUID) (sqlc.ApiKey, error) {
+
+	params := sqlc.FindApiKeyByIdParams{
+		ID:     id,
+		UserID: userId,
+	}
+
+	return r.queries.FindApiKeyById(ctx, params)
+}
+
+func (r *Repository) GetAllForUser(ctx context.Context, userId uuid.UUID) ([]sqlc.ApiKey, error) {
+	return r.queries.ListUserApiKeys(ctx, userId)
+}
+
+func (r *Repository) Update(ctx context.Context, userId uuid.UUID, id uuid.UUID, name string, scopes []string) (sqlc.ApiKey, error) {
+
+	params := sqlc.UpdateApiKeyParams{
+		Name:   name,
+		Scopes: joinScopes(scopes),
+		ID:     id,
+		UserID: userId,
+	}
+
+	return r.queries.UpdateApiKey(ctx, params)
+}
+
+func (r *Repository) Delete(ctx context.Context, userId uuid.UUID, id uuid.UUID) (bool, error) {
+
+	params := sqlc.DeleteApiKeyParams{
+		ID:     id,
+		UserID: userId,
+	}
+
+	rows, err := r.queries.DeleteApiKey(ctx, params)
+	return rows > 0, err
+}
+
+func (r *Repository) Touch(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+
+	params := sqlc.TouchApiKeyParams{
+		LastUsedAt: sql.NullTime{Time: usedAt, Valid: true},
+		ID:         id,
+	}
+
+	return r.queries.TouchApiKey(ctx, params)
+}
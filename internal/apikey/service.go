@@ -0,0 +1,194 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+	"user-management/internal/common/apperror"
+	"user-management/internal/middleware"
+	"user-management/internal/rbac"
+	"user-management/internal/user"
+
+	"github.com/google/uuid"
+)
+
+// Scheme is the Authorization scheme API keys are sent with.
+const Scheme = "ApiKey"
+
+// lastUsedInterval limits how often last used timestamps are written, so busy
+// keys do not turn every request into a write.
+const lastUsedInterval = time.Minute
+
+var (
+	ErrAPIKeyNotFound  = apperror.New(apperror.NotFound, "api_key_not_found", "api key not found")
+	ErrInvalidAPIKey   = apperror.New(apperror.Unauthorized, "invalid_api_key", "invalid api key")
+	ErrScopeNotGranted = apperror.New(apperror.Validation, "scope_not_granted", "scope is not granted to the key owner")
+	ErrExpiryInThePast = apperror.New(apperror.Validation, "expiry_in_the_past", "expiry must be in the future")
+)
+
+type Service struct {
+	repo   *Repository
+	users  *user.Service
+	access *rbac.Service
+}
+
+func NewService(repo *Repository, users *user.Service, access *rbac.Service) *Service {
+	return &Service{repo: repo, users: users, access: access}
+}
+
+// Create mints a key for the user. Scopes are limited to the permissions the
+// user holds, as a key never grants more than its owner has.
+func (s *Service) Create(ctx context.Context, userId uuid.UUID, req *APIKeyCreateRequest) (CreatedAPIKey, error) {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return CreatedAPIKey{}, ErrExpiryInThePast
+	}
+
+	if err := s.checkScopes(ctx, userId, req.Scopes); err != nil {
+		return CreatedAPIKey{}, err
+	}
+
+	key, prefix, err := generateKey()
+	if err != nil {
+		return CreatedAPIKey{}, err
+	}
+
+	saved, err := s.repo.Create(ctx, userId, req.Name, prefix, hashKey(key), slices.Compact(slices.Sorted(slices.Values(req.Scopes))), req.ExpiresAt)
+	if err != nil {
+		return CreatedAPIKey{}, err
+	}
+
+	return CreatedAPIKey{APIKey: FromSQLC(saved), Key: key}, nil
+}
+
+func (s *Service) List(ctx context.Context, userId uuid.UUID) ([]APIKey, error) {
+	keys, err := s.repo.GetAllForUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	return FromSQLCList(keys), nil
+}
+
+func (s *Service) Get(ctx context.Context, userId uuid.UUID, id uuid.UUID) (APIKey, error) {
+	key, err := s.repo.GetById(ctx, userId, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return APIKey{}, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return APIKey{}, err
+	}
+	return FromSQLC(key), nil
+}
+
+func (s *Service) Update(ctx context.Context, userId uuid.UUID, id uuid.UUID, req *APIKeyUpdateRequest) (APIKey, error) {
+	existing, err := s.Get(ctx, userId, id)
+	if err != nil {
+		return APIKey{}, err
+	}
+
+	if req.Name != "" {
+		existing.Name = req.Name
+	}
+
+	if req.Scopes != nil {
+		if err := s.checkScopes(ctx, userId, req.Scopes); err != nil {
+			return APIKey{}, err
+		}
+		existing.Scopes = slices.Compact(slices.Sorted(slices.Values(req.Scopes)))
+	}
+
+	updated, err := s.repo.Update(ctx, userId, id, existing.Name, existing.Scopes)
+	if err != nil {
+		return APIKey{}, err
+	}
+	return FromSQLC(updated), nil
+}
+
+func (s *Service) Revoke(ctx context.Context, userId uuid.UUID, id uuid.UUID) error {
+	deleted, err := s.repo.Delete(ctx, userId, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (s *Service) Scheme() string {
+	return Scheme
+}
+
+// Authenticate resolves an API key to its owner. The principal only carries
+// the scopes the owner still holds, so revoking a role also narrows its keys.
+func (s *Service) Authenticate(ctx context.Context, credential string) (*middleware.Principal, error) {
+	prefix, ok := parseKey(credential)
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+
+	stored, err := s.repo.GetByPrefix(ctx, prefix)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !matchesHash(credential, stored.KeyHash) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now().UTC()
+	if stored.ExpiresAt.Valid && now.After(stored.ExpiresAt.Time) {
+		return nil, fmt.Errorf("%w: expired", ErrInvalidAPIKey)
+	}
+
+	owner, err := s.users.GetUserById(ctx, stored.UserID.String())
+	if err != nil {
+		return nil, err
+	}
+	if owner.Status != user.Active {
+		return nil, user.ErrUserNotActive
+	}
+
+	roles, permissions, err := s.access.GetUserAccess(ctx, owner.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !stored.LastUsedAt.Valid || now.Sub(stored.LastUsedAt.Time) >= lastUsedInterval {
+		if err := s.repo.Touch(ctx, stored.ID, now); err != nil {
+			slog.WarnContext(ctx, "Failed to record api key use", "keyId", stored.ID, "error", err)
+		}
+	}
+
+	scopes := splitScopes(stored.Scopes)
+
+	return &middleware.Principal{
+		UserID:   owner.UserId,
+		Email:    owner.Email,
+		Roles:    roles,
+		APIKeyID: stored.ID,
+		Permissions: slices.DeleteFunc(permissions, func(p string) bool {
+			return !slices.Contains(scopes, p)
+		}),
+	}, nil
+}
+
+func (s *Service) checkScopes(ctx context.Context, userId uuid.UUID, scopes []string) error {
+	_, permissions, err := s.access.GetUserAccess(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	for _, scope := range scopes {
+		if !slices.Contains(permissions, scope) {
+			return ErrScopeNotGranted.WithMessage(fmt.Sprintf("scope %s is not granted to the key owner", scope))
+		}
+	}
+	return nil
+}
@@ -2,66 +2,260 @@ package app
 
 import (
 	"database/sql"
+	"net/http"
+	"time"
+	"user-management/internal/account"
+	"user-management/internal/apikey"
+	"user-management/internal/audit"
+	"user-management/internal/auth"
+	httputils "user-management/internal/common/httputils"
+	"user-management/internal/config"
+	"user-management/internal/db"
+	"user-management/internal/db/migrate"
+	"user-management/internal/db/migrations"
+	"user-management/internal/db/query"
 	"user-management/internal/db/sqlc"
+	"user-management/internal/health"
+	"user-management/internal/idempotency"
 	"user-management/internal/instrument"
+	"user-management/internal/mail"
+	"user-management/internal/mfa"
 	"user-management/internal/middleware"
+	"user-management/internal/rbac"
+	"user-management/internal/token"
 	"user-management/internal/user"
 	"user-management/internal/validation"
 
 	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
 	"github.com/go-playground/validator/v10"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
+// healthCheckTimeout limits each readiness check.
+const healthCheckTimeout = 2 * time.Second
+
 type App struct {
+	Config    *config.Config
 	DB        *sql.DB
 	Validator *validator.Validate
 	Queries   *sqlc.Queries
 
+	TokenIssuer      *token.Issuer
+	IdempotencyStore *idempotency.Store
+	Health           *health.Checker
+
+	UserService       *user.Service
+	InstrumentService *instrument.Service
+	RoleService       *rbac.Service
+	APIKeyService     *apikey.Service
+	AuditService      *audit.Service
+
 	UserHandler       *user.Handler
 	InstrumentHandler *instrument.Handler
+	AuthHandler       *auth.Handler
+	RoleHandler       *rbac.Handler
+	APIKeyHandler     *apikey.Handler
+	MfaHandler        *mfa.Handler
+	AccountHandler    *account.Handler
+	AuditHandler      *audit.Handler
 }
 
-func NewApp(db *sql.DB) *App {
+func NewApp(conn *sql.DB, cfg *config.Config) (*App, error) {
 	validate := validator.New()
 	validation.RegisterValidations(validate)
 
-	queries := sqlc.New(db)
+	queries := sqlc.New(db.Observe(conn))
+
+	tokenIssuer, err := token.NewIssuer(cfg.Auth)
+	if err != nil {
+		return nil, err
+	}
+	tokenRepo := token.NewRepository(queries)
+	tokenService := token.NewService(tokenIssuer, tokenRepo, cfg.Auth.RefreshTokenTTL)
+
+	mailer, err := mail.New(cfg.Mail)
+	if err != nil {
+		return nil, err
+	}
+
+	cursors := query.NewCursorCodec(cfg.Pagination.CursorSecret)
+
+	userRepo := user.NewRepository(conn, queries)
+	userService := user.NewService(userRepo, cursors, cfg.Auth.RequireEmailVerification)
+
+	accountRepo := account.NewRepository(queries)
+	accountService := account.NewService(accountRepo, userService, mailer, cfg)
+	accountHandler := account.NewHandler(accountService, validate)
+
+	instrumentRepo := instrument.NewRepository(conn, queries)
+	instrumentService := instrument.NewService(instrumentRepo, cursors)
+	instrumentHandler := instrument.NewHandler(instrumentService, validate, cfg.Batch.MaxOperations)
 
-	userRepo := user.NewRepository(queries)
-	userService := user.NewService(userRepo)
-	userHandler := user.NewHandler(userService, validate)
+	roleRepo := rbac.NewRepository(queries)
+	roleService := rbac.NewService(roleRepo)
+	roleHandler := rbac.NewHandler(roleService, validate)
 
-	instrumentRepo := instrument.NewRepository(queries)
-	instrumentService := instrument.NewService(instrumentRepo)
-	instrumentHandler := instrument.NewHandler(instrumentService, validate)
+	userHandler := user.NewHandler(userService, validate, accountService, cfg.Batch.MaxOperations)
+
+	apiKeyRepo := apikey.NewRepository(queries)
+	apiKeyService := apikey.NewService(apiKeyRepo, userService, roleService)
+	apiKeyHandler := apikey.NewHandler(apiKeyService, validate)
+
+	mfaRepo := mfa.NewRepository(conn, queries)
+	mfaService := mfa.NewService(mfaRepo, userService, tokenIssuer.Name())
+	mfaHandler := mfa.NewHandler(mfaService, validate)
+
+	auditRepo := audit.NewRepository(queries)
+	auditService := audit.NewService(auditRepo)
+	auditHandler := audit.NewHandler(auditService)
+
+	idempotencyStore := idempotency.NewStore(queries, cfg.Idempotency.TTL, cfg.Idempotency.ClaimTimeout)
+
+	migrator, err := migrate.New(conn, migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+	checker := health.NewChecker(healthCheckTimeout)
+	checker.Register("database", conn.PingContext)
+	checker.Register("migrations", migrator.Check)
+
+	authService := auth.NewService(userService, roleService, tokenService, mfaService)
+	authHandler := auth.NewHandler(authService, tokenIssuer, validate)
 
 	return &App{
-		DB:                db,
+		Config:            cfg,
+		DB:                conn,
 		Validator:         validate,
 		Queries:           queries,
+		TokenIssuer:       tokenIssuer,
+		IdempotencyStore:  idempotencyStore,
+		Health:            checker,
+		UserService:       userService,
+		InstrumentService: instrumentService,
+		RoleService:       roleService,
+		APIKeyService:     apiKeyService,
 		UserHandler:       userHandler,
 		InstrumentHandler: instrumentHandler,
-	}
+		AuthHandler:       authHandler,
+		RoleHandler:       roleHandler,
+		APIKeyHandler:     apiKeyHandler,
+		MfaHandler:        mfaHandler,
+		AccountHandler:    accountHandler,
+		AuditHandler:      auditHandler,
+		AuditService:      auditService,
+	}, nil
 }
 
 func (a *App) RegisterRoutes(r chi.Router) {
 
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		httputils.WriteError(w, http.StatusNotFound, "No route matches the request path", r)
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		httputils.WriteError(w, http.StatusMethodNotAllowed, "The method is not allowed for the request path", r)
+	})
+
+	authenticate := middleware.Authenticate(a.TokenIssuer, a.APIKeyService)
+	// Requests are limited per client IP before they reach the router. Keys
+	// are additionally limited once they are verified, a key in the header
+	// that is not cannot be used to get another budget.
+	if limit := a.Config.Server.RateLimit; limit.Requests > 0 {
+		authenticate = chi.Chain(authenticate, apikey.RateLimit(limit.Requests, limit.Window)).Handler
+	}
+	require := middleware.RequirePermission
+	ifMatch := middleware.IfMatch(a.Config.Server.RequireIfMatch)
+	idempotent := middleware.Idempotency(a.IdempotencyStore)
+
+	// Exports stream rows until the whole table is written, so they are
+	// registered before the request timeout applies to the routes below.
+	r.With(authenticate, require(rbac.PermUsersRead), middleware.IncludeDeleted(rbac.PermUsersDelete)).Get("/users/export", a.UserHandler.ExportUsers)
+	r.With(authenticate, require(rbac.PermInstrumentsRead), middleware.IncludeDeleted(rbac.PermInstrumentsDelete)).Get("/instruments/export", a.InstrumentHandler.ExportInstruments)
+
+	if timeout := a.Config.Server.RequestTimeout; timeout > 0 {
+		r = r.With(chimiddleware.Timeout(timeout))
+	}
+
+	r.Get("/healthz", a.Health.Live)
+	r.Get("/readyz", a.Health.Readiness)
 	r.Get("/swagger/*", httpSwagger.WrapHandler)
+	r.Get("/.well-known/jwks.json", a.AuthHandler.JWKS)
+
+	r.Route("/auth", func(r chi.Router) {
+		r.Post("/login", a.AuthHandler.Login)
+		r.Post("/login/mfa", a.AuthHandler.LoginMfa)
+		r.Post("/refresh", a.AuthHandler.Refresh)
+		r.Post("/logout", a.AuthHandler.Logout)
+
+		r.Post("/password-reset", a.AccountHandler.RequestPasswordReset)
+		r.Post("/password-reset/confirm", a.AccountHandler.ConfirmPasswordReset)
+		r.Get("/verify-email", a.AccountHandler.VerifyEmailLink)
+		r.Post("/verify-email", a.AccountHandler.VerifyEmail)
+		r.Post("/verify-email/resend", a.AccountHandler.ResendVerification)
+	})
+
+	r.Route("/auth/mfa", func(r chi.Router) {
+		r.Use(authenticate)
+
+		r.Get("/", a.MfaHandler.GetMfaStatus)
+		r.Post("/enroll", a.MfaHandler.EnrollMfa)
+		r.Post("/activate", a.MfaHandler.ActivateMfa)
+		r.Post("/recovery-codes", a.MfaHandler.RegenerateRecoveryCodes)
+		r.Post("/disable", a.MfaHandler.DisableMfa)
+	})
+
+	r.With(authenticate, require(rbac.PermRolesRead)).Get("/roles", a.RoleHandler.GetRoles)
+	r.With(authenticate, require(rbac.PermRolesWrite)).Patch("/roles/{role}", a.RoleHandler.UpdateRole)
+
+	r.With(authenticate, require(rbac.PermAuditRead), middleware.Paginate).Get("/audit", a.AuditHandler.GetAuditLog)
+
+	r.Route("/api-keys", func(r chi.Router) {
+		r.Use(authenticate)
+
+		r.Post("/", a.APIKeyHandler.CreateAPIKey)
+		r.Get("/", a.APIKeyHandler.GetAPIKeys)
+		r.Get("/{id}", a.APIKeyHandler.GetAPIKeyById)
+		r.Patch("/{id}", a.APIKeyHandler.UpdateAPIKeyById)
+		r.Delete("/{id}", a.APIKeyHandler.DeleteAPIKeyById)
+	})
+
+	// The batch routes are not below /users and /instruments, so they cannot
+	// be registered on their subrouters.
+	r.With(authenticate, require(rbac.PermUsersWrite), idempotent).Post("/users:batch", a.UserHandler.BatchUsers)
+	r.With(authenticate, require(rbac.PermInstrumentsWrite), idempotent).Post("/instruments:batch", a.InstrumentHandler.BatchInstruments)
 
 	r.Route("/users", func(r chi.Router) {
-		r.Post("/", a.UserHandler.CreateUser)
-		r.With(middleware.Paginate).Get("/", a.UserHandler.GetUsers)
-		r.Get("/{id}", a.UserHandler.GetUserById)
-		r.Patch("/{id}", a.UserHandler.UpdateUserById)
-		r.Delete("/{id}", a.UserHandler.DeleteUserById)
+		r.Use(authenticate)
+
+		r.With(require(rbac.PermUsersWrite), idempotent).Post("/", a.UserHandler.CreateUser)
+		r.With(require(rbac.PermUsersRead), middleware.Paginate, middleware.IncludeDeleted(rbac.PermUsersDelete)).Get("/", a.UserHandler.GetUsers)
+		r.With(require(rbac.PermUsersRead), middleware.IncludeDeleted(rbac.PermUsersDelete)).Get("/{id}", a.UserHandler.GetUserById)
+		r.With(require(rbac.PermUsersWrite), ifMatch).Patch("/{id}", a.UserHandler.UpdateUserById)
+		r.With(require(rbac.PermUsersDelete), ifMatch).Delete("/{id}", a.UserHandler.DeleteUserById)
+		r.With(require(rbac.PermUsersDelete)).Post("/{id}/restore", a.UserHandler.RestoreUser)
+		r.With(middleware.RequireSelfOrPermission("id", rbac.PermUsersWrite)).Put("/{id}/password", a.UserHandler.ChangeUserPassword)
+
+		r.With(require(rbac.PermUsersWrite)).Post("/{id}/suspend", a.UserHandler.SuspendUser)
+		r.With(require(rbac.PermUsersWrite)).Post("/{id}/lock", a.UserHandler.LockUser)
+		r.With(require(rbac.PermUsersWrite)).Post("/{id}/reactivate", a.UserHandler.ReactivateUser)
+		r.With(require(rbac.PermUsersRead)).Get("/{id}/status-history", a.UserHandler.GetUserStatusHistory)
+
+		r.With(middleware.RequireSelfOrPermission("id", rbac.PermRolesRead)).Get("/{id}/roles", a.RoleHandler.GetUserRoles)
+		r.With(require(rbac.PermRolesWrite)).Post("/{id}/roles", a.RoleHandler.AssignUserRole)
+		r.With(require(rbac.PermRolesWrite)).Delete("/{id}/roles/{role}", a.RoleHandler.RemoveUserRole)
+
+		r.With(require(rbac.PermUsersWrite)).Delete("/{id}/mfa", a.MfaHandler.ResetUserMfa)
 	})
 
 	r.Route("/instruments", func(r chi.Router) {
-		r.Post("/", a.InstrumentHandler.CreateInstrument)
-		r.With(middleware.Paginate).Get("/", a.InstrumentHandler.GetInstruments)
-		r.Get("/{id}", a.InstrumentHandler.GetInstrumentById)
-		r.Patch("/{id}", a.InstrumentHandler.UpdateInstrumentById)
-		r.Delete("/{id}", a.InstrumentHandler.DeleteInstrumentById)
+		r.Use(authenticate)
+
+		r.With(require(rbac.PermInstrumentsWrite), idempotent).Post("/", a.InstrumentHandler.CreateInstrument)
+		r.With(require(rbac.PermInstrumentsRead), middleware.Paginate, middleware.IncludeDeleted(rbac.PermInstrumentsDelete)).Get("/", a.InstrumentHandler.GetInstruments)
+		r.With(require(rbac.PermInstrumentsRead), middleware.IncludeDeleted(rbac.PermInstrumentsDelete)).Get("/{id}", a.InstrumentHandler.GetInstrumentById)
+		r.With(require(rbac.PermInstrumentsRead), middleware.IncludeDeleted(rbac.PermInstrumentsDelete)).Get("/by-symbol/{symbol}", a.InstrumentHandler.GetInstrumentBySymbol)
+		r.With(require(rbac.PermInstrumentsWrite), ifMatch).Patch("/{id}", a.InstrumentHandler.UpdateInstrumentById)
+		r.With(require(rbac.PermInstrumentsDelete), ifMatch).Delete("/{id}", a.InstrumentHandler.DeleteInstrumentById)
+		r.With(require(rbac.PermInstrumentsDelete)).Post("/{id}/restore", a.InstrumentHandler.RestoreInstrument)
 	})
 }
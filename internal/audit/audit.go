@@ -0,0 +1,137 @@
+package audit
+
+import (
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"slices"
+	"time"
+	"user-management/internal/db/sqlc"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ActionCreate         = "create"
+	ActionUpdate         = "update"
+	ActionDelete         = "delete"
+	ActionRestore        = "restore"
+	ActionPurge          = "purge"
+	ActionPasswordChange = "password_change"
+)
+
+// Event describes a mutation of an entity. Before and After are the entity as
+// it is returned by the API, so fields hidden from JSON never reach the log.
+// Before is nil for creations and After is nil for purges.
+type Event struct {
+	Action     string
+	EntityType string
+	EntityId   uuid.UUID
+	Before     any
+	After      any
+}
+
+// Change is the before and after value of a single JSON field.
+type Change struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+type Entry struct {
+	Id         uuid.UUID  `json:"id"`
+	Seq        int64      `json:"seq"`
+	ActorId    *uuid.UUID `json:"actorId,omitempty"`
+	RequestId  string     `json:"requestId,omitempty"`
+	Action     string     `json:"action"`
+	EntityType string     `json:"entityType"`
+	EntityId   uuid.UUID  `json:"entityId"`
+	Changes    []Change   `json:"changes"`
+	PrevHash   string     `json:"prevHash"`
+	Hash       string     `json:"hash"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// Diff compares the JSON encoding of before and after field by field and
+// returns the fields that differ, sorted by name. A nil side counts as an
+// object without fields.
+func Diff(before any, after any) ([]Change, error) {
+	b, err := toFields(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := toFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]string, 0, len(b)+len(a))
+	for f := range b {
+		fields = append(fields, f)
+	}
+	for f := range a {
+		if _, ok := b[f]; !ok {
+			fields = append(fields, f)
+		}
+	}
+	slices.Sort(fields)
+
+	changes := []Change{}
+	for _, f := range fields {
+		if !reflect.DeepEqual(b[f], a[f]) {
+			changes = append(changes, Change{Field: f, Before: b[f], After: a[f]})
+		}
+	}
+	return changes, nil
+}
+
+func toFields(v any) (map[string]any, error) {
+	fields := map[string]any{}
+	if v == nil {
+		return fields, nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func FromSQLC(l sqlc.AuditLog) Entry {
+	mapped := Entry{
+		Id:         l.ID,
+		Seq:        l.Seq,
+		RequestId:  l.RequestID,
+		Action:     l.Action,
+		EntityType: l.EntityType,
+		EntityId:   l.EntityID,
+		PrevHash:   l.PrevHash,
+		Hash:       l.Hash,
+		CreatedAt:  l.CreatedAt,
+	}
+
+	if l.ActorID.Valid {
+		mapped.ActorId = &l.ActorID.UUID
+	}
+
+	if err := json.Unmarshal([]byte(l.Changes), &mapped.Changes); err != nil {
+		slog.Error("Invalid audit changes from DB", "id", l.ID, "error", err)
+	}
+	if mapped.Changes == nil {
+		mapped.Changes = []Change{}
+	}
+
+	return mapped
+}
+
+func FromSQLCList(logs []sqlc.AuditLog) []Entry {
+	mapped := make([]Entry, len(logs))
+	for i, l := range logs {
+		mapped[i] = FromSQLC(l)
+	}
+	return mapped
+}
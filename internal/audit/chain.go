@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+	"user-management/internal/db/sqlc"
+	"user-management/internal/middleware"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+)
+
+// Write appends the event to the audit log using q, which must be bound to the
+// transaction making the change so the record commits or rolls back with it.
+//
+// Records form a hash chain: each hash covers the record and the hash of the
+// record before it, so editing or removing a record breaks every later hash.
+// Writers are serialized with a transaction scoped advisory lock.
+func Write(ctx context.Context, q *sqlc.Queries, e Event) error {
+	changes, err := Diff(e.Before, e.After)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	if err := q.LockAuditLog(ctx); err != nil {
+		return err
+	}
+
+	prevHash, err := q.FindLastAuditLogHash(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	params := sqlc.CreateAuditLogParams{
+		ID:         uuid.New(),
+		RequestID:  chimiddleware.GetReqID(ctx),
+		Action:     e.Action,
+		EntityType: e.EntityType,
+		EntityID:   e.EntityId,
+		Changes:    string(encoded),
+		PrevHash:   prevHash,
+		// TIMESTAMP keeps microseconds, the hash must match what is read back.
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+	if principal, ok := middleware.PrincipalFrom(ctx); ok {
+		params.ActorID = uuid.NullUUID{UUID: principal.UserID, Valid: true}
+	}
+
+	params.Hash = Hash(sqlc.AuditLog{
+		ID:         params.ID,
+		ActorID:    params.ActorID,
+		RequestID:  params.RequestID,
+		Action:     params.Action,
+		EntityType: params.EntityType,
+		EntityID:   params.EntityID,
+		Changes:    params.Changes,
+		PrevHash:   params.PrevHash,
+		CreatedAt:  params.CreatedAt,
+	})
+
+	return q.CreateAuditLog(ctx, params)
+}
+
+// Hash returns the chain hash of a record. Seq and Hash itself are not covered.
+func Hash(l sqlc.AuditLog) string {
+	var actor string
+	if l.ActorID.Valid {
+		actor = l.ActorID.UUID.String()
+	}
+
+	canonical, _ := json.Marshal([]string{
+		l.PrevHash,
+		l.ID.String(),
+		actor,
+		l.RequestID,
+		l.Action,
+		l.EntityType,
+		l.EntityID.String(),
+		l.Changes,
+		l.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+	"user-management/internal/db/query"
+
+	"github.com/google/uuid"
+)
+
+// Filter narrows the audit log. Empty fields match every record, From is
+// inclusive and To exclusive.
+type Filter struct {
+	EntityType string
+	EntityId   *uuid.UUID
+	ActorId    *uuid.UUID
+	Action     string
+	From       *time.Time
+	To         *time.Time
+}
+
+// ParseFilter reads the entity, id, actor, action, from and to query
+// parameters. Times are RFC 3339.
+func ParseFilter(q url.Values) (Filter, error) {
+	f := Filter{
+		EntityType: q.Get("entity"),
+		Action:     q.Get("action"),
+	}
+
+	var err error
+	if f.EntityId, err = parseUUID(q, "id"); err != nil {
+		return Filter{}, err
+	}
+	if f.ActorId, err = parseUUID(q, "actor"); err != nil {
+		return Filter{}, err
+	}
+	if f.From, err = parseTime(q, "from"); err != nil {
+		return Filter{}, err
+	}
+	if f.To, err = parseTime(q, "to"); err != nil {
+		return Filter{}, err
+	}
+
+	return f, nil
+}
+
+func parseUUID(q url.Values, name string) (*uuid.UUID, error) {
+	v := q.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return nil, query.ErrInvalidFilter.WithMessage(fmt.Sprintf("invalid %s, expected a UUID", name))
+	}
+	return &id, nil
+}
+
+func parseTime(q url.Values, name string) (*time.Time, error) {
+	v := q.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, query.ErrInvalidFilter.WithMessage(fmt.Sprintf("invalid %s, expected RFC 3339", name))
+	}
+	t = t.UTC()
+	return &t, nil
+}
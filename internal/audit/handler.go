@@ -0,0 +1,60 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	httputils "user-management/internal/common/httputils"
+	"user-management/internal/middleware"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetAuditLog godoc
+// @Summary Get audit log
+// @Description List audit records, newest first. Every change to users and instruments is recorded with the acting user, the request ID and a field level diff
+// @Tags audit
+// @Produce  json
+// @Param entity query string false "Entity type, user or instrument"
+// @Param id query string false "Entity ID"
+// @Param actor query string false "User ID of the actor"
+// @Param action query string false "Action, one of create, update, delete, restore, purge or password_change"
+// @Param from query string false "Only records created at or after this RFC 3339 time"
+// @Param to query string false "Only records created before this RFC 3339 time"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {array} Entry
+// @Failure      400  {object}  httputils.Problem
+// @Failure      401  {object}  httputils.Problem
+// @Failure      403  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /audit [get]
+func (h *Handler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+
+	page := r.Context().Value(middleware.PageKey).(int)
+	limit := r.Context().Value(middleware.LimitKey).(int)
+	offset := (page - 1) * limit
+
+	filter, err := ParseFilter(r.URL.Query())
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	entries, err := h.service.ListEntriesPaged(r.Context(), filter, limit, offset)
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(entries)
+}
@@ -0,0 +1,51 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"user-management/internal/db/sqlc"
+
+	"github.com/google/uuid"
+)
+
+type Repository struct {
+	queries *sqlc.Queries
+}
+
+func NewRepository(q *sqlc.Queries) *Repository {
+	return &Repository{queries: q}
+}
+
+func (r *Repository) GetAllPaged(ctx context.Context, f Filter, limit int, offset int) ([]sqlc.AuditLog, error) {
+
+	params := sqlc.ListAuditLogParams{
+		EntityType: sql.NullString{String: f.EntityType, Valid: f.EntityType != ""},
+		Action:     sql.NullString{String: f.Action, Valid: f.Action != ""},
+		Limit:      int32(limit),
+		Offset:     int32(offset),
+	}
+	if f.EntityId != nil {
+		params.EntityID = uuid.NullUUID{UUID: *f.EntityId, Valid: true}
+	}
+	if f.ActorId != nil {
+		params.ActorID = uuid.NullUUID{UUID: *f.ActorId, Valid: true}
+	}
+	if f.From != nil {
+		params.FromTime = sql.NullTime{Time: *f.From, Valid: true}
+	}
+	if f.To != nil {
+		params.ToTime = sql.NullTime{Time: *f.To, Valid: true}
+	}
+
+	return r.queries.ListAuditLog(ctx, params)
+}
+
+func (r *Repository) GetAfter(ctx context.Context, seq int64, limit int) ([]sqlc.AuditLog, error) {
+
+	params := sqlc.ListAuditLogAfterParams{
+		Seq:   seq,
+		Limit: int32(limit),
+	}
+
+	return r.queries.ListAuditLogAfter(ctx, params)
+}
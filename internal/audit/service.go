@@ -0,0 +1,61 @@
+package audit
+
+import (
+	"context"
+)
+
+const verifyBatchSize = 500
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// VerifyResult reports whether the hash chain is intact. BrokenAtSeq is the
+// first record whose hash or link to the previous record does not match.
+type VerifyResult struct {
+	Checked     int64 `json:"checked"`
+	Valid       bool  `json:"valid"`
+	BrokenAtSeq int64 `json:"brokenAtSeq,omitempty"`
+}
+
+// ListEntriesPaged returns the matching records, newest first.
+func (s *Service) ListEntriesPaged(ctx context.Context, f Filter, limit int, offset int) ([]Entry, error) {
+	logs, err := s.repo.GetAllPaged(ctx, f, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return FromSQLCList(logs), nil
+}
+
+// Verify walks the whole log in order and recomputes the hash chain.
+func (s *Service) Verify(ctx context.Context) (VerifyResult, error) {
+	var result VerifyResult
+	var seq int64
+	prevHash := ""
+
+	for {
+		logs, err := s.repo.GetAfter(ctx, seq, verifyBatchSize)
+		if err != nil {
+			return VerifyResult{}, err
+		}
+
+		for _, l := range logs {
+			result.Checked++
+			if l.PrevHash != prevHash || Hash(l) != l.Hash {
+				result.BrokenAtSeq = l.Seq
+				return result, nil
+			}
+			prevHash = l.Hash
+			seq = l.Seq
+		}
+
+		if len(logs) < verifyBatchSize {
+			result.Valid = true
+			return result, nil
+		}
+	}
+}
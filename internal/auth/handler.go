@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	httputils "user-management/internal/common/httputils"
+	"user-management/internal/mfa"
+	"user-management/internal/token"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type Handler struct {
+	service  *Service
+	issuer   *token.Issuer
+	validate *validator.Validate
+}
+
+func NewHandler(service *Service, issuer *token.Issuer, validate *validator.Validate) *Handler {
+	return &Handler{
+		service:  service,
+		issuer:   issuer,
+		validate: validate,
+	}
+}
+
+// Login godoc
+// @Summary Log in with email and password
+// @Description Authenticate an user with email and password and issue an access and refresh token pair.
+// @Description Users with MFA enabled get a 202 with a challenge to complete with /auth/login/mfa
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param request body LoginRequest true "Credentials"
+// @Success 200 {object} token.TokenPair
+// @Success 202 {object} MfaChallenge
+// @Failure      400  {object}  httputils.Problem
+// @Failure      401  {object}  httputils.Problem
+// @Failure      403  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Router /auth/login [post]
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req LoginRequest
+	if err := httputils.DecodeAndValidateRequest(r, &req, h.validate); err != nil {
+		slog.WarnContext(r.Context(), "Login failed", "error", err)
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	result, err := h.service.Login(r.Context(), &req)
+	if err != nil {
+		writeAuthError(w, r, err)
+		return
+	}
+
+	if result.Challenge != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(result.Challenge)
+		return
+	}
+
+	writeTokens(w, *result.Tokens)
+}
+
+// LoginMfa godoc
+// @Summary Complete a login with MFA
+// @Description Exchange the challenge from /auth/login and a TOTP or recovery code for a token pair. A challenge accepts 5 codes and can be exchanged once, and users are refused for 15 minutes after 10 invalid codes
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param request body MfaLoginRequest true "Challenge and code"
+// @Success 200 {object} token.TokenPair
+// @Failure      400  {object}  httputils.Problem
+// @Failure      401  {object}  httputils.Problem
+// @Failure      403  {object}  httputils.Problem
+// @Failure      429  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Router /auth/login/mfa [post]
+func (h *Handler) LoginMfa(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req MfaLoginRequest
+	if err := httputils.DecodeAndValidateRequest(r, &req, h.validate); err != nil {
+		slog.WarnContext(r.Context(), "MFA login failed", "error", err)
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	tokens, err := h.service.LoginMfa(r.Context(), &req)
+	if err != nil {
+		writeAuthError(w, r, err)
+		return
+	}
+
+	writeTokens(w, tokens)
+}
+
+// Refresh godoc
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new token pair. Refresh tokens are single use
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param request body RefreshRequest true "Refresh token"
+// @Success 200 {object} token.TokenPair
+// @Failure      400  {object}  httputils.Problem
+// @Failure      401  {object}  httputils.Problem
+// @Failure      403  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Router /auth/refresh [post]
+func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req RefreshRequest
+	if err := httputils.DecodeAndValidateRequest(r, &req, h.validate); err != nil {
+		slog.WarnContext(r.Context(), "Token refresh failed", "error", err)
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	tokens, err := h.service.Refresh(r.Context(), &req)
+	if err != nil {
+		writeAuthError(w, r, err)
+		return
+	}
+
+	writeTokens(w, tokens)
+}
+
+// Logout godoc
+// @Summary Log out
+// @Description Revoke a refresh token
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param request body RefreshRequest true "Refresh token"
+// @Success 204
+// @Failure      400  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Router /auth/logout [post]
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req RefreshRequest
+	if err := httputils.DecodeAndValidateRequest(r, &req, h.validate); err != nil {
+		slog.WarnContext(r.Context(), "Logout failed", "error", err)
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	err := h.service.Logout(r.Context(), &req)
+	if err != nil && !errors.Is(err, token.ErrInvalidRefreshToken) {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// JWKS godoc
+// @Summary JSON Web Key Set
+// @Description Public keys used to verify access tokens
+// @Tags auth
+// @Produce  json
+// @Success 200 {object} token.JWKSet
+// @Router /.well-known/jwks.json [get]
+func (h *Handler) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(h.issuer.JWKS())
+}
+
+func writeTokens(w http.ResponseWriter, tokens token.TokenPair) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// writeAuthError reports a failed login. Users without MFA cannot complete an
+// MFA login, which is reported like a wrong code.
+func writeAuthError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, mfa.ErrMfaNotEnabled) {
+		err = mfa.ErrInvalidMfaCode
+	}
+	httputils.WriteProblem(w, r, err)
+}
@@ -0,0 +1,16 @@
+package auth
+
+import "user-management/internal/token"
+
+// LoginResult holds either a token pair or, for users with MFA enabled, the
+// challenge to complete with /auth/login/mfa.
+type LoginResult struct {
+	Tokens    *token.TokenPair
+	Challenge *MfaChallenge
+}
+
+type MfaChallenge struct {
+	MfaRequired bool   `json:"mfaRequired"`
+	MfaToken    string `json:"mfaToken"`
+	ExpiresIn   int    `json:"expiresIn"`
+}
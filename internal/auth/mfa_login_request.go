@@ -0,0 +1,6 @@
+package auth
+
+type MfaLoginRequest struct {
+	MfaToken string `json:"mfaToken" validate:"required"`
+	Code     string `json:"code" validate:"required,max=32"`
+}
@@ -0,0 +1,5 @@
+package auth
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"user-management/internal/common/apperror"
+	"user-management/internal/mfa"
+	"user-management/internal/middleware"
+	"user-management/internal/rbac"
+	"user-management/internal/token"
+	"user-management/internal/user"
+)
+
+var ErrInvalidMfaChallenge = apperror.New(apperror.Unauthorized, "invalid_mfa_challenge", "invalid or expired mfa challenge")
+
+type Service struct {
+	users  *user.Service
+	access *rbac.Service
+	tokens *token.Service
+	mfa    *mfa.Service
+}
+
+func NewService(users *user.Service, access *rbac.Service, tokens *token.Service, mfa *mfa.Service) *Service {
+	return &Service{users: users, access: access, tokens: tokens, mfa: mfa}
+}
+
+// Login checks the password of the user. Users with MFA enabled get a
+// challenge to complete with LoginMfa instead of a token pair.
+func (s *Service) Login(ctx context.Context, req *LoginRequest) (LoginResult, error) {
+	authenticated, err := s.users.Authenticate(ctx, req.Email, req.Password)
+	if err != nil {
+		return LoginResult{}, err
+	}
+
+	if authenticated.MfaEnabled {
+		challenge, expiresIn, err := s.tokens.IssueMfaChallenge(authenticated.UserId)
+		if err != nil {
+			return LoginResult{}, err
+		}
+		return LoginResult{Challenge: &MfaChallenge{MfaRequired: true, MfaToken: challenge, ExpiresIn: expiresIn}}, nil
+	}
+
+	tokens, err := s.issue(ctx, authenticated)
+	if err != nil {
+		return LoginResult{}, err
+	}
+	return LoginResult{Tokens: &tokens}, nil
+}
+
+// LoginMfa completes a login with the challenge from Login and a TOTP or
+// recovery code.
+func (s *Service) LoginMfa(ctx context.Context, req *MfaLoginRequest) (token.TokenPair, error) {
+	challenge, err := s.tokens.VerifyMfaChallenge(req.MfaToken)
+	if err != nil {
+		return token.TokenPair{}, ErrInvalidMfaChallenge
+	}
+
+	if err := s.mfa.VerifyChallenge(ctx, challenge.UserID, challenge.ID, challenge.ExpiresAt, req.Code); err != nil {
+		return token.TokenPair{}, err
+	}
+
+	u, err := s.users.GetUserById(ctx, challenge.UserID.String())
+	if errors.Is(err, sql.ErrNoRows) {
+		return token.TokenPair{}, ErrInvalidMfaChallenge
+	}
+	if err != nil {
+		return token.TokenPair{}, err
+	}
+
+	if u.Status != user.Active {
+		return token.TokenPair{}, user.ErrUserNotActive
+	}
+
+	return s.issue(ctx, u)
+}
+
+// Refresh exchanges a refresh token for a new token pair. The user and its roles
+// are loaded again so that deactivated users cannot keep refreshing their
+// session and role changes are picked up.
+func (s *Service) Refresh(ctx context.Context, req *RefreshRequest) (token.TokenPair, error) {
+	userId, err := s.tokens.Consume(ctx, req.RefreshToken)
+	if err != nil {
+		return token.TokenPair{}, err
+	}
+
+	u, err := s.users.GetUserById(ctx, userId.String())
+	if errors.Is(err, sql.ErrNoRows) {
+		return token.TokenPair{}, token.ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return token.TokenPair{}, err
+	}
+
+	if u.Status != user.Active {
+		return token.TokenPair{}, user.ErrUserNotActive
+	}
+
+	return s.issue(ctx, u)
+}
+
+func (s *Service) Logout(ctx context.Context, req *RefreshRequest) error {
+	return s.tokens.Revoke(ctx, req.RefreshToken)
+}
+
+// issue creates a token pair for the user. Users with a role that requires MFA
+// get no permissions until they enable it, which still lets them enroll.
+func (s *Service) issue(ctx context.Context, u user.User) (token.TokenPair, error) {
+	if !u.MfaEnabled {
+		required, err := s.access.RequiresMfa(ctx, u.UserId)
+		if err != nil {
+			return token.TokenPair{}, err
+		}
+
+		if required {
+			tokens, err := s.tokens.Issue(ctx, middleware.Principal{UserID: u.UserId, Email: u.Email})
+			tokens.MfaEnrollmentRequired = true
+			return tokens, err
+		}
+	}
+
+	roles, permissions, err := s.access.GetUserAccess(ctx, u.UserId)
+	if err != nil {
+		return token.TokenPair{}, err
+	}
+
+	return s.tokens.Issue(ctx, middleware.Principal{
+		UserID:      u.UserId,
+		Email:       u.Email,
+		Roles:       roles,
+		Permissions: permissions,
+	})
+}
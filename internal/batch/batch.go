@@ -0,0 +1,259 @@
+// Package batch implements the batch endpoints, which run many create, update
+// and delete operations of one resource in a single request. A transactional
+// batch applies all of its operations or none, a best effort batch applies
+// each operation on its own and reports the outcome of every operation.
+package batch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"user-management/internal/common/apperror"
+	httputils "user-management/internal/common/httputils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// Mode selects how the operations of a batch are applied.
+type Mode string
+
+const (
+	// Transactional applies all operations in one transaction. The first
+	// failing operation rolls back the batch.
+	Transactional Mode = "transactional"
+	// BestEffort applies each operation in its own transaction.
+	BestEffort Mode = "best_effort"
+)
+
+// Op is the kind of an operation.
+type Op string
+
+const (
+	Create Op = "create"
+	Update Op = "update"
+	Delete Op = "delete"
+)
+
+// Operation is one operation of a batch request. Data holds the create or
+// update request of the resource, ID selects the resource to update or delete
+// and a non zero Version must match its version.
+type Operation struct {
+	Op      Op              `json:"op" validate:"required,oneof=create update delete"`
+	ID      uuid.UUID       `json:"id,omitempty" swaggertype:"string" format:"uuid"`
+	Version int64           `json:"version,omitempty" validate:"gte=0"`
+	Data    json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+}
+
+// Request is the body of a batch endpoint. Mode defaults to transactional.
+type Request struct {
+	Mode       Mode        `json:"mode,omitempty" validate:"omitempty,oneof=transactional best_effort"`
+	Operations []Operation `json:"operations" validate:"required,min=1,dive"`
+}
+
+// Result is the outcome of one operation. Status is the status the single
+// resource endpoint would have answered with, Data the resource for created
+// and updated resources and Error the problem of a failed operation.
+type Result struct {
+	Index  int                `json:"index"`
+	Op     Op                 `json:"op"`
+	Status int                `json:"status"`
+	ID     *uuid.UUID         `json:"id,omitempty" swaggertype:"string" format:"uuid"`
+	Data   any                `json:"data,omitempty"`
+	Error  *httputils.Problem `json:"error,omitempty"`
+}
+
+// Response is the body answered by a batch endpoint, Results are in the order
+// of the operations.
+type Response struct {
+	Mode      Mode     `json:"mode"`
+	Succeeded int      `json:"succeeded"`
+	Failed    int      `json:"failed"`
+	Results   []Result `json:"results"`
+}
+
+// ErrTooManyOperations is returned for batches above the configured size.
+var ErrTooManyOperations = apperror.New(apperror.Validation, "too_many_operations", "too many operations")
+
+// Item is a decoded operation of a batch. Create or Update holds the decoded
+// data of create and update operations.
+type Item[C any, U any] struct {
+	Index   int
+	Op      Op
+	ID      uuid.UUID
+	Version int64
+	Create  *C
+	Update  *U
+}
+
+// Outcome is the result of applying an item. Value is the created or updated
+// resource, Err is nil for applied items.
+type Outcome struct {
+	Index int
+	Op    Op
+	ID    uuid.UUID
+	Value any
+	Err   error
+}
+
+// Failed returns the outcome of an item failing with err.
+func (i Item[C, U]) Failed(err error) Outcome {
+	return Outcome{Index: i.Index, Op: i.Op, ID: i.ID, Err: err}
+}
+
+// Done returns the outcome of an item applied to the resource with the given
+// id. value is the resource itself, nil for deleted resources.
+func (i Item[C, U]) Done(id uuid.UUID, value any) Outcome {
+	return Outcome{Index: i.Index, Op: i.Op, ID: id, Value: value}
+}
+
+// ItemError is the error of a transactional batch failing at the operation
+// with the given index.
+type ItemError struct {
+	Index int
+	Err   error
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("operation %d: %v", e.Index, e.Err)
+}
+
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// Parse checks the size of the batch and decodes and validates the data of
+// its operations into create requests C and update requests U. Operations
+// that cannot be applied are returned as failed outcomes instead of items.
+func Parse[C any, U any](req Request, v *validator.Validate, maxOperations int) ([]Item[C, U], []Outcome, error) {
+	if len(req.Operations) > maxOperations {
+		return nil, nil, ErrTooManyOperations.WithMessage(fmt.Sprintf("a batch can have at most %d operations", maxOperations))
+	}
+
+	var items []Item[C, U]
+	var invalid []Outcome
+	for i, op := range req.Operations {
+		item := Item[C, U]{Index: i, Op: op.Op, ID: op.ID, Version: op.Version}
+
+		var err error
+		switch op.Op {
+		case Create:
+			item.Create = new(C)
+			err = decode(op.Data, item.Create, v)
+		case Update:
+			item.Update = new(U)
+			err = requireID(op.ID)
+			if err == nil {
+				err = decode(op.Data, item.Update, v)
+			}
+		case Delete:
+			err = requireID(op.ID)
+		}
+
+		if err != nil {
+			invalid = append(invalid, item.Failed(err))
+			continue
+		}
+		items = append(items, item)
+	}
+
+	return items, invalid, nil
+}
+
+func decode(data json.RawMessage, dest any, v *validator.Validate) error {
+	if len(data) == 0 {
+		return httputils.ErrValidationFailed.WithFields(httputils.FieldError{Field: "data", Message: "failed on the 'required' rule"})
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		return httputils.ErrInvalidRequest.WithMessage("Invalid JSON: " + err.Error())
+	}
+	return httputils.Validate(dest, v)
+}
+
+func requireID(id uuid.UUID) error {
+	if id == uuid.Nil {
+		return httputils.ErrValidationFailed.WithFields(httputils.FieldError{Field: "id", Message: "failed on the 'required' rule"})
+	}
+	return nil
+}
+
+// Invalid returns the error of a transactional batch with invalid operations,
+// listing the invalid fields of all of them prefixed with their operation.
+func Invalid(outcomes []Outcome) error {
+	var fields []httputils.FieldError
+	for _, o := range outcomes {
+		var appErr *apperror.Error
+		if !errors.As(o.Err, &appErr) || len(appErr.Fields) == 0 {
+			fields = append(fields, httputils.FieldError{Field: operationField(o.Index, ""), Message: o.Err.Error()})
+			continue
+		}
+		for _, f := range appErr.Fields {
+			fields = append(fields, httputils.FieldError{Field: operationField(o.Index, f.Field), Message: f.Message})
+		}
+	}
+	return httputils.ErrValidationFailed.WithFields(fields...)
+}
+
+// Failed returns the error of a transactional batch rolled back by err. The
+// fields of the failing operation are prefixed with the operation, or the
+// operation itself is listed when the error has no fields.
+func Failed(err error) error {
+	var itemErr *ItemError
+	var appErr *apperror.Error
+	if !errors.As(err, &itemErr) || !errors.As(itemErr.Err, &appErr) {
+		return err
+	}
+
+	fields := []httputils.FieldError{{Field: operationField(itemErr.Index, ""), Message: appErr.Message}}
+	if len(appErr.Fields) > 0 {
+		fields = make([]httputils.FieldError, len(appErr.Fields))
+		for i, f := range appErr.Fields {
+			fields[i] = httputils.FieldError{Field: operationField(itemErr.Index, f.Field), Message: f.Message}
+		}
+	}
+	return appErr.WithFields(fields...)
+}
+
+func operationField(index int, field string) string {
+	name := fmt.Sprintf("operations[%d]", index)
+	if field != "" {
+		name += "." + field
+	}
+	return name
+}
+
+// NewResponse converts the outcomes into the response, sorted by the index of
+// their operations.
+func NewResponse(r *http.Request, mode Mode, outcomes []Outcome) Response {
+	sort.Slice(outcomes, func(i, j int) bool { return outcomes[i].Index < outcomes[j].Index })
+
+	resp := Response{Mode: mode, Results: make([]Result, len(outcomes))}
+	for i, o := range outcomes {
+		result := Result{Index: o.Index, Op: o.Op}
+		if o.ID != uuid.Nil {
+			result.ID = &o.ID
+		}
+
+		if o.Err != nil {
+			problem := httputils.NewProblem(r, o.Err)
+			result.Status = problem.Status
+			result.Error = &problem
+			resp.Failed++
+		} else {
+			result.Status = successStatus[o.Op]
+			result.Data = o.Value
+			resp.Succeeded++
+		}
+		resp.Results[i] = result
+	}
+	return resp
+}
+
+// successStatus is the status of an applied operation.
+var successStatus = map[Op]int{
+	Create: http.StatusCreated,
+	Update: http.StatusOK,
+	Delete: http.StatusNoContent,
+}
@@ -0,0 +1,123 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	httputils "user-management/internal/common/httputils"
+	"user-management/internal/middleware"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Run applies the items of a batch. The create items are applied first, all
+// at once with createAll, the other items follow one by one in their order
+// with apply. With stopOnError the first failure is returned as an
+// ItemError, the caller runs Run in a transaction it then rolls back.
+// Otherwise a failing createAll is retried item by item with apply, so only
+// the failing creates are reported, and all items are applied.
+func Run[C any, U any](
+	ctx context.Context,
+	items []Item[C, U],
+	stopOnError bool,
+	createAll func(ctx context.Context, items []Item[C, U]) ([]Outcome, error),
+	apply func(ctx context.Context, item Item[C, U]) Outcome,
+) ([]Outcome, error) {
+
+	var creates, others []Item[C, U]
+	for _, item := range items {
+		if item.Op == Create {
+			creates = append(creates, item)
+		} else {
+			others = append(others, item)
+		}
+	}
+
+	var outcomes []Outcome
+	if len(creates) > 0 {
+		created, err := createAll(ctx, creates)
+		switch {
+		case err == nil:
+			outcomes = append(outcomes, created...)
+		case stopOnError:
+			return nil, err
+		default:
+			slog.WarnContext(ctx, "Batch create failed, creating one by one", "count", len(creates), "error", err)
+			others = append(creates, others...)
+		}
+	}
+
+	for _, item := range others {
+		outcome := apply(ctx, item)
+		if outcome.Err != nil && stopOnError {
+			return nil, &ItemError{Index: item.Index, Err: outcome.Err}
+		}
+		outcomes = append(outcomes, outcome)
+	}
+
+	return outcomes, nil
+}
+
+// Serve handles a batch request. It decodes the request, applies the valid
+// items with apply and writes the response. A transactional batch with
+// invalid operations is rejected before anything is applied, as is a batch
+// with delete operations sent by a principal without deletePermission.
+func Serve[C any, U any](
+	w http.ResponseWriter,
+	r *http.Request,
+	v *validator.Validate,
+	maxOperations int,
+	deletePermission string,
+	apply func(ctx context.Context, mode Mode, items []Item[C, U]) ([]Outcome, error),
+) {
+	var req Request
+	if err := httputils.DecodeAndValidateRequest(r, &req, v); err != nil {
+		slog.WarnContext(r.Context(), "Batch request failed", "error", err)
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	items, invalid, err := Parse[C, U](req, v, maxOperations)
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	if hasOp(req, Delete) {
+		principal, ok := middleware.PrincipalFrom(r.Context())
+		if !ok || !principal.HasPermission(deletePermission) {
+			slog.WarnContext(r.Context(), "Permission denied", "permission", deletePermission, "path", r.URL.Path)
+			httputils.WriteError(w, http.StatusForbidden, "Missing permission "+deletePermission, r)
+			return
+		}
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = Transactional
+	}
+	if mode == Transactional && len(invalid) > 0 {
+		httputils.WriteProblem(w, r, Invalid(invalid))
+		return
+	}
+
+	outcomes, err := apply(r.Context(), mode, items)
+	if err != nil {
+		httputils.WriteProblem(w, r, Failed(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(NewResponse(r, mode, append(outcomes, invalid...)))
+}
+
+func hasOp(req Request, op Op) bool {
+	for _, o := range req.Operations {
+		if o.Op == op {
+			return true
+		}
+	}
+	return false
+}
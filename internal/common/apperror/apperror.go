@@ -0,0 +1,91 @@
+// Package apperror defines the typed errors services return for failures the
+// client can act on. Each error has a Kind, which selects the HTTP status, and
+// a stable Code clients can match on instead of the message.
+package apperror
+
+import "errors"
+
+// Kind classifies an error by the reason the request failed.
+type Kind string
+
+const (
+	Internal           Kind = "internal"
+	NotFound           Kind = "not_found"
+	Conflict           Kind = "conflict"
+	Unprocessable      Kind = "unprocessable"
+	Validation         Kind = "validation"
+	Unauthorized       Kind = "unauthorized"
+	Forbidden          Kind = "forbidden"
+	PreconditionFailed Kind = "precondition_failed"
+	TooManyRequests    Kind = "too_many_requests"
+)
+
+// FieldError describes why a single field of a request is invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is a domain error. Message is safe to show to clients, Err is the
+// underlying cause, which is only logged.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+// New creates an error, usually declared once as a package level sentinel.
+func New(kind Kind, code string, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors with the same kind and code, so a sentinel matches the
+// copies created from it by Wrap and WithFields.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Code == e.Code
+}
+
+// Wrap returns a copy of the error caused by err.
+func (e *Error) Wrap(err error) *Error {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+// WithMessage returns a copy of the error with a more specific message.
+func (e *Error) WithMessage(message string) *Error {
+	c := *e
+	c.Message = message
+	return &c
+}
+
+// WithFields returns a copy of the error describing the invalid fields.
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	c := *e
+	c.Fields = fields
+	return &c
+}
+
+// KindOf returns the kind of the first Error in the chain of err, or Internal
+// when there is none.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return Internal
+}
@@ -2,40 +2,114 @@ package common
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
-	"time"
+	"strings"
+	"user-management/internal/common/apperror"
+	"user-management/internal/tracing"
+
+	"github.com/go-playground/validator/v10"
 )
 
-type FieldError struct {
-	Field   string `json:"field"`
-	Message string `json:"message"`
+// ProblemContentType is the media type of error responses, see RFC 7807.
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix prefixes the code of a problem to form its type URI.
+const problemTypePrefix = "urn:user-management:problem:"
+
+type FieldError = apperror.FieldError
+
+// Problem is an RFC 7807 problem details response. Code is a stable,
+// machine readable identifier of the error, Errors lists invalid fields.
+// TraceID identifies the trace of the request, to find its spans and logs.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+	TraceID  string       `json:"traceId,omitempty"`
 }
 
-type ErrorResponse struct {
-	Timestamp time.Time    `json:"timestamp"`
-	Status    int          `json:"status"`
-	Error     string       `json:"error"`
-	Message   string       `json:"message"`
-	Path      string       `json:"path,omitempty"`
-	Details   []FieldError `json:"details,omitempty"`
+// kindStatus maps the kinds of domain errors to response statuses.
+var kindStatus = map[apperror.Kind]int{
+	apperror.NotFound:           http.StatusNotFound,
+	apperror.Conflict:           http.StatusConflict,
+	apperror.Unprocessable:      http.StatusUnprocessableEntity,
+	apperror.Validation:         http.StatusBadRequest,
+	apperror.Unauthorized:       http.StatusUnauthorized,
+	apperror.Forbidden:          http.StatusForbidden,
+	apperror.PreconditionFailed: http.StatusPreconditionFailed,
+	apperror.TooManyRequests:    http.StatusTooManyRequests,
 }
 
+// WriteError writes a problem with the given status, identified by a code
+// derived from the status.
 func WriteError(w http.ResponseWriter, status int, message string, r *http.Request) {
 	WriteDetailedError(w, status, message, nil, r)
 }
 
 func WriteDetailedError(w http.ResponseWriter, status int, message string, details []FieldError, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-
-	resp := ErrorResponse{
-		Timestamp: time.Now(),
-		Status:    status,
-		Error:     http.StatusText(status),
-		Message:   message,
-		Path:      r.URL.Path,
-		Details:   details,
+	writeProblem(w, r, status, statusCode(status), message, details)
+}
+
+// WriteProblem maps err to a problem, see NewProblem.
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error) {
+	writeJSONProblem(w, NewProblem(r, err))
+}
+
+// NewProblem maps err to a problem. Domain errors use the status of their
+// kind and their own code and message, validation errors of the validator
+// list the invalid fields and bodies over the size limit are answered with
+// 413. Any other error is logged and answered with 500 without revealing its
+// message.
+func NewProblem(r *http.Request, err error) Problem {
+	var appErr *apperror.Error
+	var validationErrs validator.ValidationErrors
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return newProblem(r, http.StatusRequestEntityTooLarge, "request_too_large",
+			fmt.Sprintf("Request body must not be larger than %d bytes", maxBytesErr.Limit), nil)
+	case errors.As(err, &appErr) && appErr.Kind != apperror.Internal:
+		return newProblem(r, kindStatus[appErr.Kind], appErr.Code, appErr.Message, appErr.Fields)
+	case errors.As(err, &validationErrs):
+		return newProblem(r, http.StatusBadRequest, "validation_failed", "Validation failed", ConvertValidationErrors(validationErrs))
+	default:
+		slog.ErrorContext(r.Context(), "Request failed", "method", r.Method, "path", r.URL.Path, "error", err)
+		return newProblem(r, http.StatusInternalServerError, statusCode(http.StatusInternalServerError), "An unexpected error occurred", nil)
 	}
+}
+
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, code string, detail string, fields []FieldError) {
+	writeJSONProblem(w, newProblem(r, status, code, detail, fields))
+}
+
+func writeJSONProblem(w http.ResponseWriter, p Problem) {
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+func newProblem(r *http.Request, status int, code string, detail string, fields []FieldError) Problem {
+	return Problem{
+		Type:     problemTypePrefix + code,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+		Code:     code,
+		Errors:   fields,
+		TraceID:  tracing.TraceID(r.Context()),
+	}
+}
 
-	json.NewEncoder(w).Encode(resp)
+// statusCode derives a code from the status text, for example not_found.
+func statusCode(status int) string {
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
 }
@@ -5,37 +5,51 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"user-management/internal/common/apperror"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 )
 
+// ErrInvalidRequest is returned for request bodies that are missing or are
+// not valid JSON.
+var ErrInvalidRequest = apperror.New(apperror.Validation, "invalid_request", "Invalid request")
+
+// ErrValidationFailed is returned for request bodies with invalid fields,
+// which are listed in its Fields.
+var ErrValidationFailed = apperror.New(apperror.Validation, "validation_failed", "Validation failed")
+
+// DecodeAndValidateRequest reads the JSON body of the request into dest and
+// validates it. It returns ErrInvalidRequest or ErrValidationFailed, which
+// WriteProblem turns into a 400 response, or 413 when the body exceeds the
+// limit set by middleware.MaxBodySize.
 func DecodeAndValidateRequest(r *http.Request, dest interface{}, v *validator.Validate) error {
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read request body: %w", err)
+		return ErrInvalidRequest.Wrap(fmt.Errorf("failed to read request body: %w", err))
 	}
 	defer r.Body.Close()
 
 	if len(body) == 0 {
-		return fmt.Errorf("request body cannot be empty")
+		return ErrInvalidRequest.WithMessage("Request body cannot be empty")
 	}
 
 	if err := json.Unmarshal(body, dest); err != nil {
-		return fmt.Errorf("invalid JSON: %w", err)
+		return ErrInvalidRequest.WithMessage("Invalid JSON: " + err.Error())
 	}
 
+	return Validate(dest, v)
+}
+
+// Validate validates a decoded request and returns ErrValidationFailed with
+// the invalid fields.
+func Validate(dest interface{}, v *validator.Validate) error {
 	if err := v.Struct(dest); err != nil {
 		if _, ok := err.(*validator.InvalidValidationError); ok {
 			return fmt.Errorf("validation setup error: %w", err)
 		}
-
-		var errMsg string
-		for _, e := range err.(validator.ValidationErrors) {
-			errMsg += fmt.Sprintf("Field '%s' failed validation tag '%s'; ", e.Field(), e.Tag())
-		}
-		return fmt.Errorf("validation failed: %s", errMsg)
+		return ErrValidationFailed.WithFields(ConvertValidationErrors(err)...)
 	}
 
 	return nil
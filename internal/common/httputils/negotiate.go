@@ -0,0 +1,85 @@
+package common
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Negotiate returns the media type of offers the Accept header of r prefers.
+// Without an Accept header the first offer is returned. ok is false when the
+// header accepts none of the offers. Ranges are matched as in RFC 9110, the
+// most specific range matching an offer sets its quality, and offers of equal
+// quality are preferred in their order.
+func Negotiate(r *http.Request, offers ...string) (string, bool) {
+	accept := r.Header.Values("Accept")
+	if len(accept) == 0 {
+		return offers[0], true
+	}
+
+	var ranges []mediaRange
+	for _, header := range accept {
+		for _, part := range strings.Split(header, ",") {
+			if mr, ok := parseMediaRange(part); ok {
+				ranges = append(ranges, mr)
+			}
+		}
+	}
+
+	best, bestQuality := "", 0.0
+	for _, offer := range offers {
+		quality, specificity := 0.0, -1
+		for _, mr := range ranges {
+			if s := mr.match(offer); s > specificity {
+				quality, specificity = mr.quality, s
+			}
+		}
+		if quality > bestQuality {
+			best, bestQuality = offer, quality
+		}
+	}
+	return best, best != ""
+}
+
+type mediaRange struct {
+	typ     string
+	subtype string
+	quality float64
+}
+
+func parseMediaRange(s string) (mediaRange, bool) {
+	params := strings.Split(s, ";")
+	typ, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(params[0])), "/")
+	if !ok {
+		return mediaRange{}, false
+	}
+
+	mr := mediaRange{typ: typ, subtype: subtype, quality: 1}
+	for _, p := range params[1:] {
+		name, value, _ := strings.Cut(strings.TrimSpace(p), "=")
+		if strings.EqualFold(name, "q") {
+			q, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return mediaRange{}, false
+			}
+			mr.quality = q
+		}
+	}
+	return mr, true
+}
+
+// match returns how specifically the range matches the media type, -1 when
+// it does not match.
+func (mr mediaRange) match(mediaType string) int {
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	switch {
+	case mr.typ == "*" && mr.subtype == "*":
+		return 0
+	case mr.typ == typ && mr.subtype == "*":
+		return 1
+	case mr.typ == typ && mr.subtype == subtype:
+		return 2
+	default:
+		return -1
+	}
+}
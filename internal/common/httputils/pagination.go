@@ -0,0 +1,42 @@
+package common
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"user-management/internal/db/query"
+)
+
+// WritePageHeaders links the neighbouring pages in the Link header, keeping
+// the other query parameters of the request, and reports the total in
+// X-Total-Count or, when it is estimated, in X-Total-Count-Estimate.
+func WritePageHeaders(w http.ResponseWriter, r *http.Request, info query.PageInfo) {
+	var links []string
+	if info.Next != "" {
+		links = append(links, pageLink(r, info.Next, "next"))
+	}
+	if info.Prev != "" {
+		links = append(links, pageLink(r, info.Prev, "prev"))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+
+	if info.Total != nil {
+		header := "X-Total-Count"
+		if info.Estimated {
+			header = "X-Total-Count-Estimate"
+		}
+		w.Header().Set(header, strconv.FormatInt(*info.Total, 10))
+	}
+}
+
+func pageLink(r *http.Request, cursor string, rel string) string {
+	q := r.URL.Query()
+	q.Del("page")
+	q.Set("cursor", cursor)
+
+	u := *r.URL
+	u.RawQuery = q.Encode()
+	return "<" + u.RequestURI() + `>; rel="` + rel + `"`
+}
@@ -1,20 +1,184 @@
+// Package config defines the configuration of the service. It is read from
+// a YAML file, USRM_* environment variables and flags, starting from the
+// values of Default, and checked with Validate.
 package config
 
+import "time"
+
+// Config is the configuration of the service. Fields tagged secret are
+// redacted when the config is printed, secret:"url" only redacts the
+// password of a URL.
 type Config struct {
-	Server   Server   `mapstructure:"server"`
-	Database Database `mapstructure:"database"`
-	Logging  Logging  `mapstructure:"logging"`
+	Server    Server    `mapstructure:"server"`
+	Admin     Admin     `mapstructure:"admin"`
+	Database  Database  `mapstructure:"database"`
+	Logging   Logging   `mapstructure:"logging"`
+	Auth      Auth      `mapstructure:"auth"`
+	Mail      Mail      `mapstructure:"mail"`
+	Retention Retention `mapstructure:"retention"`
+
+	Idempotency Idempotency `mapstructure:"idempotency"`
+	Pagination  Pagination  `mapstructure:"pagination"`
+	Batch       Batch       `mapstructure:"batch"`
+	Tracing     Tracing     `mapstructure:"tracing"`
 }
 
 type Logging struct {
 	Level string `mapstructure:"level"`
+
+	// Format of log records, json or text.
+	Format string `mapstructure:"format"`
 }
 
 type Server struct {
-	Port            string `mapstructure:"port"`
-	ShutdownTimeout string `mapstructure:"shutdownTimeout"`
+	Port int `mapstructure:"port"`
+
+	// ShutdownTimeout limits how long the server waits for running requests
+	// on shutdown.
+	ShutdownTimeout time.Duration `mapstructure:"shutdownTimeout"`
+
+	// RequireIfMatch rejects PATCH and DELETE requests on versioned
+	// resources that do not send an If-Match header.
+	RequireIfMatch bool `mapstructure:"requireIfMatch"`
+
+	// DrainDelay is how long readiness fails before the server stops
+	// accepting requests on shutdown, so load balancers stop routing to it.
+	DrainDelay time.Duration `mapstructure:"drainDelay"`
+
+	// ReadHeaderTimeout, ReadTimeout, WriteTimeout and IdleTimeout limit the
+	// connections of the server, see http.Server. RequestTimeout cancels the
+	// context of requests running longer, WriteTimeout should exceed it so
+	// the timeout response can still be written. Neither applies to exports.
+	ReadHeaderTimeout time.Duration `mapstructure:"readHeaderTimeout"`
+	ReadTimeout       time.Duration `mapstructure:"readTimeout"`
+	WriteTimeout      time.Duration `mapstructure:"writeTimeout"`
+	IdleTimeout       time.Duration `mapstructure:"idleTimeout"`
+	RequestTimeout    time.Duration `mapstructure:"requestTimeout"`
+
+	// MaxHeaderBytes limits the size of request headers, MaxBodyBytes the
+	// size of request bodies.
+	MaxHeaderBytes int   `mapstructure:"maxHeaderBytes"`
+	MaxBodyBytes   int64 `mapstructure:"maxBodyBytes"`
+
+	CORS      CORS      `mapstructure:"cors"`
+	RateLimit RateLimit `mapstructure:"rateLimit"`
+	TLS       TLS       `mapstructure:"tls"`
 }
 
+// CORS configures the origins browsers may call the API from.
+type CORS struct {
+	AllowedOrigins   []string `mapstructure:"allowedOrigins"`
+	AllowCredentials bool     `mapstructure:"allowCredentials"`
+	MaxAge           int      `mapstructure:"maxAge"`
+}
+
+// RateLimit limits the requests per client IP, and per API key, to Requests
+// within Window. Requests 0 disables the limit.
+type RateLimit struct {
+	Requests int           `mapstructure:"requests"`
+	Window   time.Duration `mapstructure:"window"`
+}
+
+// TLS enables HTTPS when CertFile and KeyFile are set. ClientAuth is none,
+// optional or require, client certificates are verified against the CAs in
+// ClientCAFile. The files are read again when they change, checked every
+// ReloadInterval, so certificates can be renewed without a restart.
+type TLS struct {
+	CertFile       string        `mapstructure:"certFile"`
+	KeyFile        string        `mapstructure:"keyFile"`
+	ClientCAFile   string        `mapstructure:"clientCAFile"`
+	ClientAuth     string        `mapstructure:"clientAuth"`
+	MinVersion     string        `mapstructure:"minVersion"`
+	ReloadInterval time.Duration `mapstructure:"reloadInterval"`
+}
+
+// Admin configures the admin server, which serves the metrics apart from
+// the API. Port 0 disables it.
+type Admin struct {
+	Port int `mapstructure:"port"`
+}
+
+// Database configures the connection to Postgres. Password replaces the
+// password of Dsn when set, so the DSN can be kept in the config file and
+// the password in a secret.
 type Database struct {
-	Dsn string `mapstructure:"dsn"`
+	Dsn      string `mapstructure:"dsn" secret:"url"`
+	Password string `mapstructure:"password" secret:"true"`
+}
+
+type Auth struct {
+	Issuer          string        `mapstructure:"issuer"`
+	AccessTokenTTL  time.Duration `mapstructure:"accessTokenTTL"`
+	RefreshTokenTTL time.Duration `mapstructure:"refreshTokenTTL"`
+	ActiveKeyId     string        `mapstructure:"activeKeyId"`
+	SigningKeys     []SigningKey  `mapstructure:"signingKeys"`
+
+	RequireEmailVerification bool          `mapstructure:"requireEmailVerification"`
+	EmailVerificationTTL     time.Duration `mapstructure:"emailVerificationTTL"`
+	PasswordResetTTL         time.Duration `mapstructure:"passwordResetTTL"`
+}
+
+// SigningKey is a JWT signing key. HS256 keys use Secret, RS256 and EdDSA keys
+// read a PEM encoded private key from PrivateKeyFile.
+type SigningKey struct {
+	Id             string `mapstructure:"id"`
+	Algorithm      string `mapstructure:"algorithm"`
+	Secret         string `mapstructure:"secret" secret:"true"`
+	PrivateKeyFile string `mapstructure:"privateKeyFile"`
+}
+
+// Mail configures how account emails are sent. Driver is smtp, file or log.
+// The token is appended to VerifyEmailURL and ResetPasswordURL as a query parameter.
+type Mail struct {
+	Driver           string `mapstructure:"driver"`
+	From             string `mapstructure:"from"`
+	Dir              string `mapstructure:"dir"`
+	VerifyEmailURL   string `mapstructure:"verifyEmailUrl"`
+	ResetPasswordURL string `mapstructure:"resetPasswordUrl"`
+	SMTP             SMTP   `mapstructure:"smtp"`
+}
+
+// Retention configures how long soft deleted rows are kept before the purge
+// command removes them.
+type Retention struct {
+	SoftDeleted time.Duration `mapstructure:"softDeleted"`
+}
+
+// Idempotency configures how long responses to requests sent with an
+// Idempotency-Key are kept for replay. ClaimTimeout is how long a key stays
+// claimed by a request still running, a retry after it runs the request
+// again. It must not be shorter than Server.RequestTimeout.
+type Idempotency struct {
+	TTL          time.Duration `mapstructure:"ttl"`
+	ClaimTimeout time.Duration `mapstructure:"claimTimeout"`
+}
+
+// Pagination configures the cursors of paginated lists. CursorSecret signs
+// the cursors, so clients cannot forge them. It should be shared by all
+// instances, otherwise cursors are only valid on the instance that issued them.
+type Pagination struct {
+	CursorSecret string `mapstructure:"cursorSecret" secret:"true"`
+}
+
+// Batch limits the size of requests to the batch endpoints.
+type Batch struct {
+	MaxOperations int `mapstructure:"maxOperations"`
+}
+
+// Tracing configures how spans are exported. Exporter is otlp, stdout or
+// none. Endpoint is the URL of the OTLP/HTTP collector, SampleRatio the
+// share of new traces that are recorded, traces started by a caller follow
+// its sampling decision.
+type Tracing struct {
+	Exporter    string  `mapstructure:"exporter"`
+	Endpoint    string  `mapstructure:"endpoint"`
+	ServiceName string  `mapstructure:"serviceName"`
+	SampleRatio float64 `mapstructure:"sampleRatio"`
+}
+
+type SMTP struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password" secret:"true"`
 }
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+)
+
+// redacted replaces the values of secrets in printed configs.
+const redacted = "[REDACTED]"
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// field is a configuration key with the struct field its value is read into.
+type field struct {
+	key    string
+	value  reflect.Value
+	secret string
+}
+
+// walk calls fn for every key of the struct v, nested structs are walked
+// with their key as prefix. Slices, including slices of structs, are keys of
+// their own.
+func walk(v reflect.Value, prefix string, fn func(f field)) {
+	t := v.Type()
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		key := sf.Tag.Get("mapstructure")
+		if key == "" || key == "-" {
+			continue
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct {
+			walk(fv, key, fn)
+			continue
+		}
+		fn(field{key: key, value: fv, secret: sf.Tag.Get("secret")})
+	}
+}
+
+// Keys returns every key of the config with its default value, to register
+// the keys with viper, which only reads environment variables of known keys.
+func Keys() map[string]any {
+	keys := make(map[string]any)
+	walk(reflect.ValueOf(Default()), "", func(f field) {
+		keys[f.key] = f.value.Interface()
+	})
+	return keys
+}
+
+// Redacted returns cfg as nested maps keyed like the config file, with
+// durations formatted like 15m0s and the values of secrets redacted.
+func Redacted(cfg Config) map[string]any {
+	return redactStruct(reflect.ValueOf(cfg))
+}
+
+func redactStruct(v reflect.Value) map[string]any {
+	m := make(map[string]any)
+	t := v.Type()
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		key := sf.Tag.Get("mapstructure")
+		if key == "" || key == "-" {
+			continue
+		}
+		m[key] = redactValue(v.Field(i), sf.Tag.Get("secret"))
+	}
+	return m
+}
+
+func redactValue(v reflect.Value, secret string) any {
+	switch {
+	case secret == "url":
+		return redactURL(v.String())
+	case secret != "":
+		if v.IsZero() {
+			return v.Interface()
+		}
+		return redacted
+	case v.Type() == durationType:
+		return time.Duration(v.Int()).String()
+	case v.Kind() == reflect.Struct:
+		return redactStruct(v)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Struct:
+		items := make([]any, v.Len())
+		for i := range v.Len() {
+			items[i] = redactStruct(v.Index(i))
+		}
+		return items
+	default:
+		return v.Interface()
+	}
+}
+
+// DecodeHook converts config values to the types of Config. Besides the
+// conversions viper does by default, durations given as plain numbers are
+// read as seconds, like the shutdownTimeout of earlier releases.
+func DecodeHook() mapstructure.DecodeHookFunc {
+	return mapstructure.ComposeDecodeHookFunc(
+		secondsHook,
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	)
+}
+
+func secondsHook(from reflect.Type, to reflect.Type, data any) (any, error) {
+	if to != durationType || from == durationType {
+		return data, nil
+	}
+
+	v := reflect.ValueOf(data)
+	switch {
+	case v.CanInt():
+		return time.Duration(v.Int()) * time.Second, nil
+	case v.CanUint():
+		return time.Duration(v.Uint()) * time.Second, nil
+	case v.CanFloat():
+		return time.Duration(v.Float() * float64(time.Second)), nil
+	case v.Kind() == reflect.String:
+		if seconds, err := strconv.ParseFloat(strings.TrimSpace(v.String()), 64); err == nil {
+			return time.Duration(seconds * float64(time.Second)), nil
+		}
+	}
+	return data, nil
+}
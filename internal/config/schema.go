@@ -0,0 +1,82 @@
+package config
+
+import (
+	"reflect"
+	"time"
+)
+
+// durationPattern matches the durations time.ParseDuration accepts and plain
+// numbers, which are read as seconds.
+const durationPattern = `^(\d+(\.\d+)?|(\d+(\.\d+)?(ns|us|µs|ms|s|m|h))+)$`
+
+// Schema returns the JSON Schema of the config file, with the type, default
+// and allowed values of every key.
+func Schema() map[string]any {
+	schema := objectSchema(reflect.ValueOf(Default()), "")
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "user-management configuration"
+	return schema
+}
+
+func objectSchema(v reflect.Value, prefix string) map[string]any {
+	properties := make(map[string]any)
+	t := v.Type()
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		key := sf.Tag.Get("mapstructure")
+		if key == "" || key == "-" {
+			continue
+		}
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		property := valueSchema(v.Field(i), path)
+		if sf.Tag.Get("secret") != "" {
+			property["writeOnly"] = true
+		}
+		properties[key] = property
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+func valueSchema(v reflect.Value, path string) map[string]any {
+	var schema map[string]any
+	switch {
+	case v.Type() == durationType:
+		schema = map[string]any{"type": []string{"string", "integer"}, "pattern": durationPattern}
+		if !v.IsZero() {
+			schema["default"] = time.Duration(v.Int()).String()
+		}
+		return schema
+	case v.Kind() == reflect.Struct:
+		return objectSchema(v, path)
+	case v.Kind() == reflect.Slice:
+		schema = map[string]any{"type": "array", "items": valueSchema(reflect.New(v.Type().Elem()).Elem(), path)}
+		if v.Len() > 0 {
+			schema["default"] = v.Interface()
+		}
+		return schema
+	case v.CanInt() || v.CanUint():
+		schema = map[string]any{"type": "integer"}
+	case v.CanFloat():
+		schema = map[string]any{"type": "number"}
+	case v.Kind() == reflect.Bool:
+		schema = map[string]any{"type": "boolean"}
+	default:
+		schema = map[string]any{"type": "string"}
+	}
+
+	if values, ok := enums[path]; ok {
+		schema["enum"] = values
+	}
+	if !v.IsZero() {
+		schema["default"] = v.Interface()
+	}
+	return schema
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// ReadSecretFiles sets the string keys whose value is kept in a file, named
+// by the environment variable of the key with a _FILE suffix. With prefix
+// USRM, USRM_DATABASE_PASSWORD_FILE=/run/secrets/db-password sets
+// database.password to the content of the file, without its trailing
+// newline. Setting both the variable of a key and its _FILE variant is an
+// error.
+func ReadSecretFiles(cfg *Config, prefix string, lookupEnv func(string) (string, bool)) error {
+	var errs []error
+	walk(reflect.ValueOf(cfg).Elem(), "", func(f field) {
+		if f.value.Kind() != reflect.String {
+			return
+		}
+
+		name := EnvName(prefix, f.key)
+		file, ok := lookupEnv(name + "_FILE")
+		if !ok {
+			return
+		}
+		if _, ok := lookupEnv(name); ok {
+			errs = append(errs, fmt.Errorf("%s: only one of %s and %s_FILE may be set", f.key, name, name))
+			return
+		}
+
+		b, err := os.ReadFile(file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.key, err))
+			return
+		}
+		f.value.SetString(strings.TrimRight(string(b), "\r\n"))
+	})
+	return errors.Join(errs...)
+}
+
+// EnvName returns the environment variable overriding key, for example
+// USRM_SERVER_PORT for server.port.
+func EnvName(prefix string, key string) string {
+	name := strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
+
+// ConnString returns the DSN with the password replaced by Password, when
+// it is set. The DSN is either a URL or a list of key=value settings.
+func (d Database) ConnString() string {
+	if d.Password == "" {
+		return d.Dsn
+	}
+
+	if u, err := url.Parse(d.Dsn); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		u.User = url.UserPassword(u.User.Username(), d.Password)
+		return u.String()
+	}
+
+	password := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(d.Password)
+	return strings.TrimSpace(d.Dsn) + " password='" + password + "'"
+}
+
+// redactURL replaces the password of a URL by xxxxx. Values that are not
+// URLs, like key=value DSNs, are redacted entirely.
+func redactURL(raw string) string {
+	if raw == "" {
+		return raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" {
+		return redacted
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// enums lists the values keys with a fixed set of values accept, compared
+// case-insensitively.
+var enums = map[string][]string{
+	"server.tls.clientAuth": {"none", "optional", "require"},
+	"server.tls.minVersion": {"1.2", "1.3"},
+	"logging.level":         {"DEBUG", "INFO", "WARN", "WARNING", "ERROR"},
+	"logging.format":        {"json", "text"},
+	"mail.driver":           {"log", "file", "smtp"},
+	"tracing.exporter":      {"none", "stdout", "otlp"},
+}
+
+// Validate checks the config and returns an error naming every invalid key.
+func (c *Config) Validate() error {
+	var errs []error
+	invalid := func(key string, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+	// Negative durations are reported by the check of every key.
+	positive := func(key string, d time.Duration) {
+		if d == 0 {
+			invalid(key, "must be positive")
+		}
+	}
+
+	walk(reflect.ValueOf(c).Elem(), "", func(f field) {
+		switch {
+		case f.value.CanInt() && f.value.Int() < 0:
+			invalid(f.key, "must not be negative")
+		case f.value.Kind() == reflect.String && enums[f.key] != nil && f.value.String() != "":
+			if !slices.ContainsFunc(enums[f.key], func(v string) bool { return strings.EqualFold(v, f.value.String()) }) {
+				invalid(f.key, "must be one of %s, got %q", strings.Join(enums[f.key], ", "), f.value.String())
+			}
+		}
+	})
+
+	if c.Server.Port == 0 || c.Server.Port > 65535 {
+		invalid("server.port", "must be between 1 and 65535, got %d", c.Server.Port)
+	}
+	if c.Admin.Port > 65535 {
+		invalid("admin.port", "must be between 0 and 65535, got %d", c.Admin.Port)
+	}
+	if c.Admin.Port != 0 && c.Admin.Port == c.Server.Port {
+		invalid("admin.port", "must differ from server.port %d", c.Server.Port)
+	}
+	positive("server.shutdownTimeout", c.Server.ShutdownTimeout)
+	if c.Server.RateLimit.Requests > 0 {
+		positive("server.rateLimit.window", c.Server.RateLimit.Window)
+	}
+	if c.Server.CORS.AllowCredentials && slices.Contains(c.Server.CORS.AllowedOrigins, "*") {
+		invalid("server.cors.allowCredentials", "cannot be combined with the allowed origin *")
+	}
+
+	tls := c.Server.TLS
+	if (tls.CertFile == "") != (tls.KeyFile == "") {
+		invalid("server.tls", "certFile and keyFile must be set together")
+	}
+	if !strings.EqualFold(tls.ClientAuth, "none") && tls.ClientAuth != "" && tls.ClientCAFile == "" {
+		invalid("server.tls.clientCAFile", "is required when clientAuth is %s", tls.ClientAuth)
+	}
+
+	if c.Database.Dsn == "" {
+		invalid("database.dsn", "is required")
+	}
+
+	positive("auth.accessTokenTTL", c.Auth.AccessTokenTTL)
+	positive("auth.refreshTokenTTL", c.Auth.RefreshTokenTTL)
+	var keyIDs []string
+	for i, key := range c.Auth.SigningKeys {
+		if key.Id == "" {
+			invalid(fmt.Sprintf("auth.signingKeys[%d].id", i), "is required")
+		} else if slices.Contains(keyIDs, key.Id) {
+			invalid(fmt.Sprintf("auth.signingKeys[%d].id", i), "%q is used by another key", key.Id)
+		}
+		keyIDs = append(keyIDs, key.Id)
+	}
+	if c.Auth.ActiveKeyId != "" && !slices.Contains(keyIDs, c.Auth.ActiveKeyId) {
+		invalid("auth.activeKeyId", "no signing key has the id %q", c.Auth.ActiveKeyId)
+	}
+
+	switch strings.ToLower(c.Mail.Driver) {
+	case "smtp":
+		if c.Mail.SMTP.Host == "" {
+			invalid("mail.smtp.host", "is required for the smtp driver")
+		}
+	case "file":
+		if c.Mail.Dir == "" {
+			invalid("mail.dir", "is required for the file driver")
+		}
+	}
+
+	positive("retention.softDeleted", c.Retention.SoftDeleted)
+	positive("idempotency.ttl", c.Idempotency.TTL)
+	positive("idempotency.claimTimeout", c.Idempotency.ClaimTimeout)
+	if c.Server.RequestTimeout > 0 && c.Idempotency.ClaimTimeout < c.Server.RequestTimeout {
+		invalid("idempotency.claimTimeout", "must be at least server.requestTimeout %s", c.Server.RequestTimeout)
+	}
+	if c.Batch.MaxOperations < 1 {
+		invalid("batch.maxOperations", "must be at least 1, got %d", c.Batch.MaxOperations)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		invalid("tracing.sampleRatio", "must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+	}
+
+	return errors.Join(errs...)
+}
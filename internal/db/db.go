@@ -4,33 +4,34 @@ import (
 	"context"
 	"database/sql"
 	"log/slog"
-	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 )
 
+// Connect opens a pool to the database, retrying until it is reachable. The
+// caller closes the pool, the server only after in-flight requests finished.
 func Connect(connStr string) *sql.DB {
 	const (
 		maxRetries = 5
 		retryDelay = 3 * time.Second
 	)
 
+	config, err := pgx.ParseConfig(connStr)
+	if err != nil {
+		slog.Error("Invalid database connection string", "error", err)
+		panic(err)
+	}
+	config.Tracer = QueryTracer{}
+
 	var pool *sql.DB
-	var err error
 
 	slog.Info("Connecting to database")
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
 
-		pool, err = sql.Open("pgx", connStr)
-		if err != nil {
-			slog.Error("Failed to open DB connection", "attempt", attempt, "error", err)
-			time.Sleep(retryDelay)
-			continue
-		}
+		pool = stdlib.OpenDB(*config)
 
 		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 		defer cancel()
@@ -53,20 +54,5 @@ func Connect(connStr string) *sql.DB {
 	pool.SetMaxIdleConns(5)
 	pool.SetMaxOpenConns(10)
 
-	go handleShutdown(pool)
-
 	return pool
 }
-
-func handleShutdown(pool *sql.DB) {
-	signalChan := make(chan os.Signal, 1)
-	signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM)
-
-	<-signalChan
-	slog.Info("Shutting down database connection...")
-	if err := pool.Close(); err != nil {
-		slog.Error("Error closing DB connection", "error", err)
-	} else {
-		slog.Info("Database connection closed.")
-	}
-}
@@ -0,0 +1,368 @@
+// Package migrate applies and reverts the SQL migrations of the schema. The
+// versions applied to a database are recorded in the SCHEMA_VERSIONS table,
+// and concurrent runners, for example several instances started with
+// auto-migrate, are serialized with an advisory lock.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lockKey names the advisory lock held while migrating.
+const lockKey = "user-management:migrate"
+
+const createVersionTable = `CREATE TABLE IF NOT EXISTS SCHEMA_VERSIONS (
+    VERSION INTEGER PRIMARY KEY,
+    NAME TEXT NOT NULL,
+    APPLIED_AT TIMESTAMP DEFAULT NOW() NOT NULL
+)`
+
+var migrationFile = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a version of the schema. Down is empty when the migration
+// cannot be reverted.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Direction tells whether a step applies or reverts its migration.
+type Direction string
+
+const (
+	Up   Direction = "up"
+	Down Direction = "down"
+)
+
+// Step applies or reverts a migration.
+type Step struct {
+	Migration Migration
+	Direction Direction
+}
+
+// Status is a migration with the time it was applied, nil when it is
+// pending. Migrations applied to the database that are unknown to this
+// build have an empty Name.
+type Status struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// Load reads the migrations in fsys, ordered by version. Every version needs
+// an up migration, the down migration is optional.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration %s: name must look like 0001_name.up.sql", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		if version <= 0 {
+			return nil, fmt.Errorf("migration %s: version must be positive", entry.Name())
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %s: version %d is also named %s", entry.Name(), version, m.Name)
+		}
+
+		body, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %04d_%s has no up migration", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int { return a.Version - b.Version })
+	return migrations, nil
+}
+
+// Plan returns the steps migrating a database with the applied versions to
+// target. Pending migrations up to target are applied in ascending order,
+// applied versions above target are reverted in descending order. Target 0
+// reverts every migration.
+func Plan(migrations []Migration, applied []int, target int) ([]Step, error) {
+	if target < 0 {
+		return nil, fmt.Errorf("version %d does not exist", target)
+	}
+	byVersion := make(map[int]Migration, len(migrations))
+	for _, m := range migrations {
+		byVersion[m.Version] = m
+	}
+	if _, ok := byVersion[target]; target != 0 && !ok && !slices.Contains(applied, target) {
+		return nil, fmt.Errorf("version %d does not exist", target)
+	}
+
+	var steps []Step
+	reverted := slices.Clone(applied)
+	slices.Sort(reverted)
+	slices.Reverse(reverted)
+	for _, version := range reverted {
+		if version <= target {
+			continue
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			return nil, fmt.Errorf("version %d is applied but unknown to this build", version)
+		}
+		if m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s cannot be reverted", m.Version, m.Name)
+		}
+		steps = append(steps, Step{Migration: m, Direction: Down})
+	}
+
+	for _, m := range migrations {
+		if m.Version <= target && !slices.Contains(applied, m.Version) {
+			steps = append(steps, Step{Migration: m, Direction: Up})
+		}
+	}
+	return steps, nil
+}
+
+// Migrator migrates a database.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New returns a migrator of db with the migrations in fsys.
+func New(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Up applies all pending migrations. Versions applied by a newer build are
+// left in place.
+func (m *Migrator) Up(ctx context.Context) ([]Step, error) {
+	return m.migrate(ctx, func(applied []int) int {
+		target := slices.Max(append(slices.Clone(applied), 0))
+		if len(m.migrations) > 0 {
+			target = max(target, m.migrations[len(m.migrations)-1].Version)
+		}
+		return target
+	})
+}
+
+// Down reverts the given number of the most recently applied versions.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Step, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("steps must be positive, got %d", steps)
+	}
+	return m.migrate(ctx, func(applied []int) int {
+		versions := slices.Clone(applied)
+		slices.Sort(versions)
+		if steps >= len(versions) {
+			return 0
+		}
+		return versions[len(versions)-steps-1]
+	})
+}
+
+// To applies or reverts migrations until version is the latest applied one.
+func (m *Migrator) To(ctx context.Context, version int) ([]Step, error) {
+	return m.migrate(ctx, func([]int) int { return version })
+}
+
+// Status returns the known migrations and the applied versions unknown to
+// this build, ordered by version.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		appliedAt, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			s := Status{Version: mig.Version, Name: mig.Name}
+			if at, ok := appliedAt[mig.Version]; ok {
+				s.AppliedAt = &at
+				delete(appliedAt, mig.Version)
+			}
+			statuses = append(statuses, s)
+		}
+		for version, at := range appliedAt {
+			statuses = append(statuses, Status{Version: version, AppliedAt: &at})
+		}
+		return nil
+	})
+	slices.SortFunc(statuses, func(a, b Status) int { return a.Version - b.Version })
+	return statuses, err
+}
+
+// Check returns an error when a known migration is not applied. It does not
+// wait for running migrations, so it suits readiness probes.
+func (m *Migrator) Check(ctx context.Context) error {
+	applied, err := appliedVersions(ctx, m.db)
+	if err != nil {
+		return fmt.Errorf("failed to read schema versions: %w", err)
+	}
+
+	pending := 0
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; !ok {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("%d of %d migrations are pending", pending, len(m.migrations))
+	}
+	return nil
+}
+
+// migrate runs the plan to the target version, computed from the applied
+// versions while the lock is held. Every step runs in its own transaction
+// together with the update of the version table, so a failed step leaves
+// the database at the previous version.
+func (m *Migrator) migrate(ctx context.Context, target func(applied []int) int) ([]Step, error) {
+	var done []Step
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		appliedAt, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		applied := make([]int, 0, len(appliedAt))
+		for version := range appliedAt {
+			applied = append(applied, version)
+		}
+
+		steps, err := Plan(m.migrations, applied, target(applied))
+		if err != nil {
+			return err
+		}
+
+		for _, step := range steps {
+			start := time.Now()
+			if err := runStep(ctx, conn, step); err != nil {
+				return fmt.Errorf("migration %04d_%s %s: %w", step.Migration.Version, step.Migration.Name, step.Direction, err)
+			}
+			slog.Info("Migrated database", "version", step.Migration.Version, "name", step.Migration.Name,
+				"direction", step.Direction, "duration", time.Since(start))
+			done = append(done, step)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// locked runs fn on a connection holding the migration lock, after creating
+// the version table.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", lockKey).Scan(&acquired); err != nil {
+		return err
+	}
+	if !acquired {
+		slog.Info("Waiting for another migration to finish")
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock(hashtext($1))", lockKey); err != nil {
+			return err
+		}
+	}
+	defer func() {
+		// Closing the session releases the lock when unlocking fails.
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock(hashtext($1))", lockKey); err != nil {
+			slog.Error("Failed to release the migration lock", "error", err)
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, createVersionTable); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// querier is implemented by *sql.DB and *sql.Conn.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func appliedVersions(ctx context.Context, conn querier) (map[int]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT VERSION, APPLIED_AT FROM SCHEMA_VERSIONS")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at
+	}
+	return applied, rows.Err()
+}
+
+func runStep(ctx context.Context, conn *sql.Conn, step Step) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	m := step.Migration
+	if step.Direction == Up {
+		if _, err := tx.ExecContext(ctx, m.Up); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, "INSERT INTO SCHEMA_VERSIONS (VERSION, NAME) VALUES ($1, $2)", m.Version, m.Name)
+	} else {
+		if _, err := tx.ExecContext(ctx, m.Down); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, "DELETE FROM SCHEMA_VERSIONS WHERE VERSION = $1", m.Version)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS USERS;
\ No newline at end of file
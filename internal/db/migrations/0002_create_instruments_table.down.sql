@@ -0,0 +1 @@
+DROP TABLE IF EXISTS INSTRUMENTS;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE USERS DROP COLUMN IF EXISTS PASSWORD_HASH;
\ No newline at end of file
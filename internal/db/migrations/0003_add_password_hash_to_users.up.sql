@@ -0,0 +1 @@
+ALTER TABLE USERS ADD COLUMN IF NOT EXISTS PASSWORD_HASH TEXT;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS REFRESH_TOKENS;
\ No newline at end of file
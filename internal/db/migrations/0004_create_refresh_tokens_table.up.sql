@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS REFRESH_TOKENS (
+    ID UUID PRIMARY KEY,
+    USER_ID UUID NOT NULL REFERENCES USERS (USER_ID) ON DELETE CASCADE,
+    TOKEN_HASH TEXT NOT NULL UNIQUE,
+    EXPIRES_AT TIMESTAMP NOT NULL,
+    CREATED_AT TIMESTAMP DEFAULT NOW() NOT NULL,
+    REVOKED_AT TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS IDX_REFRESH_TOKENS_USER_ID ON REFRESH_TOKENS (USER_ID);
\ No newline at end of file
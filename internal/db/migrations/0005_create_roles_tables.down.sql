@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS USER_ROLES;
+DROP TABLE IF EXISTS ROLE_PERMISSIONS;
+DROP TABLE IF EXISTS PERMISSIONS;
+DROP TABLE IF EXISTS ROLES;
\ No newline at end of file
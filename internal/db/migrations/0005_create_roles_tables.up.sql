@@ -0,0 +1,55 @@
+CREATE TABLE IF NOT EXISTS ROLES (
+    NAME VARCHAR(50) PRIMARY KEY,
+    DESCRIPTION TEXT DEFAULT '' NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS PERMISSIONS (
+    NAME VARCHAR(100) PRIMARY KEY,
+    DESCRIPTION TEXT DEFAULT '' NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS ROLE_PERMISSIONS (
+    ROLE_NAME VARCHAR(50) NOT NULL REFERENCES ROLES (NAME) ON DELETE CASCADE,
+    PERMISSION_NAME VARCHAR(100) NOT NULL REFERENCES PERMISSIONS (NAME) ON DELETE CASCADE,
+    PRIMARY KEY (ROLE_NAME, PERMISSION_NAME)
+);
+
+CREATE TABLE IF NOT EXISTS USER_ROLES (
+    USER_ID UUID NOT NULL REFERENCES USERS (USER_ID) ON DELETE CASCADE,
+    ROLE_NAME VARCHAR(50) NOT NULL REFERENCES ROLES (NAME) ON DELETE CASCADE,
+    GRANTED_AT TIMESTAMP DEFAULT NOW() NOT NULL,
+    PRIMARY KEY (USER_ID, ROLE_NAME)
+);
+
+INSERT INTO ROLES (NAME, DESCRIPTION) VALUES
+    ('admin', 'Full access, including user deletion and role management'),
+    ('operator', 'Manages instruments and reads users'),
+    ('viewer', 'Read only access')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO PERMISSIONS (NAME, DESCRIPTION) VALUES
+    ('users:read', 'Read users'),
+    ('users:write', 'Create and update users'),
+    ('users:delete', 'Delete users'),
+    ('instruments:read', 'Read instruments'),
+    ('instruments:write', 'Create and update instruments'),
+    ('instruments:delete', 'Delete instruments'),
+    ('roles:read', 'Read roles and role assignments'),
+    ('roles:write', 'Assign and remove roles')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO ROLE_PERMISSIONS (ROLE_NAME, PERMISSION_NAME) VALUES
+    ('admin', 'users:read'),
+    ('admin', 'users:write'),
+    ('admin', 'users:delete'),
+    ('admin', 'instruments:read'),
+    ('admin', 'instruments:write'),
+    ('admin', 'instruments:delete'),
+    ('admin', 'roles:read'),
+    ('admin', 'roles:write'),
+    ('operator', 'users:read'),
+    ('operator', 'instruments:read'),
+    ('operator', 'instruments:write'),
+    ('viewer', 'users:read'),
+    ('viewer', 'instruments:read')
+ON CONFLICT DO NOTHING;
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS API_KEYS (
+    ID UUID PRIMARY KEY,
+    USER_ID UUID NOT NULL REFERENCES USERS (USER_ID) ON DELETE CASCADE,
+    NAME VARCHAR(100) NOT NULL,
+    PREFIX VARCHAR(16) NOT NULL UNIQUE,
+    KEY_HASH TEXT NOT NULL,
+    SCOPES TEXT NOT NULL,
+    EXPIRES_AT TIMESTAMP,
+    LAST_USED_AT TIMESTAMP,
+    CREATED_AT TIMESTAMP DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS IDX_API_KEYS_USER_ID ON API_KEYS (USER_ID);
\ No newline at end of file
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS MFA_RECOVERY_CODES;
+
+ALTER TABLE ROLES DROP COLUMN IF EXISTS REQUIRE_MFA;
+
+ALTER TABLE USERS DROP COLUMN IF EXISTS MFA_LAST_STEP;
+ALTER TABLE USERS DROP COLUMN IF EXISTS MFA_ENABLED;
+ALTER TABLE USERS DROP COLUMN IF EXISTS MFA_SECRET;
\ No newline at end of file
@@ -0,0 +1,15 @@
+ALTER TABLE USERS ADD COLUMN IF NOT EXISTS MFA_SECRET TEXT;
+ALTER TABLE USERS ADD COLUMN IF NOT EXISTS MFA_ENABLED BOOLEAN DEFAULT FALSE NOT NULL;
+ALTER TABLE USERS ADD COLUMN IF NOT EXISTS MFA_LAST_STEP BIGINT;
+
+ALTER TABLE ROLES ADD COLUMN IF NOT EXISTS REQUIRE_MFA BOOLEAN DEFAULT FALSE NOT NULL;
+
+CREATE TABLE IF NOT EXISTS MFA_RECOVERY_CODES (
+    ID UUID PRIMARY KEY,
+    USER_ID UUID NOT NULL REFERENCES USERS (USER_ID) ON DELETE CASCADE,
+    CODE_HASH TEXT NOT NULL,
+    USED_AT TIMESTAMP,
+    CREATED_AT TIMESTAMP DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS IDX_MFA_RECOVERY_CODES_USER_ID ON MFA_RECOVERY_CODES (USER_ID);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS ACCOUNT_TOKENS;
\ No newline at end of file
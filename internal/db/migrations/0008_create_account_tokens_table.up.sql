@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS ACCOUNT_TOKENS (
+    ID UUID PRIMARY KEY,
+    USER_ID UUID NOT NULL REFERENCES USERS (USER_ID) ON DELETE CASCADE,
+    PURPOSE VARCHAR(32) NOT NULL,
+    TOKEN_HASH TEXT NOT NULL UNIQUE,
+    EXPIRES_AT TIMESTAMP NOT NULL,
+    USED_AT TIMESTAMP,
+    CREATED_AT TIMESTAMP DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS IDX_ACCOUNT_TOKENS_USER_ID ON ACCOUNT_TOKENS (USER_ID);
\ No newline at end of file
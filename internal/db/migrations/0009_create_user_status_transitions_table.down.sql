@@ -0,0 +1 @@
+DROP TABLE IF EXISTS USER_STATUS_TRANSITIONS;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS USER_STATUS_TRANSITIONS (
+    ID UUID PRIMARY KEY,
+    USER_ID UUID NOT NULL REFERENCES USERS (USER_ID) ON DELETE CASCADE,
+    FROM_STATUS TEXT NOT NULL,
+    TO_STATUS TEXT NOT NULL,
+    REASON TEXT DEFAULT '' NOT NULL,
+    ACTOR_ID UUID,
+    CREATED_AT TIMESTAMP DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS IDX_USER_STATUS_TRANSITIONS_USER_ID ON USER_STATUS_TRANSITIONS (USER_ID);
\ No newline at end of file
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS IDX_INSTRUMENTS_DELETED_AT;
+DROP INDEX IF EXISTS IDX_USERS_DELETED_AT;
+
+ALTER TABLE INSTRUMENTS DROP COLUMN IF EXISTS DELETED_BY;
+ALTER TABLE INSTRUMENTS DROP COLUMN IF EXISTS DELETED_AT;
+
+ALTER TABLE USERS DROP COLUMN IF EXISTS DELETED_BY;
+ALTER TABLE USERS DROP COLUMN IF EXISTS DELETED_AT;
\ No newline at end of file
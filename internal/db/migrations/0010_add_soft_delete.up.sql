@@ -0,0 +1,8 @@
+ALTER TABLE USERS ADD COLUMN IF NOT EXISTS DELETED_AT TIMESTAMP;
+ALTER TABLE USERS ADD COLUMN IF NOT EXISTS DELETED_BY UUID;
+
+ALTER TABLE INSTRUMENTS ADD COLUMN IF NOT EXISTS DELETED_AT TIMESTAMP;
+ALTER TABLE INSTRUMENTS ADD COLUMN IF NOT EXISTS DELETED_BY UUID;
+
+CREATE INDEX IF NOT EXISTS IDX_USERS_DELETED_AT ON USERS (DELETED_AT) WHERE DELETED_AT IS NOT NULL;
+CREATE INDEX IF NOT EXISTS IDX_INSTRUMENTS_DELETED_AT ON INSTRUMENTS (DELETED_AT) WHERE DELETED_AT IS NOT NULL;
\ No newline at end of file
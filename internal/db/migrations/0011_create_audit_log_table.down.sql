@@ -0,0 +1,4 @@
+DELETE FROM ROLE_PERMISSIONS WHERE PERMISSION_NAME = 'audit:read';
+DELETE FROM PERMISSIONS WHERE NAME = 'audit:read';
+
+DROP TABLE IF EXISTS AUDIT_LOG;
\ No newline at end of file
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS AUDIT_LOG (
+    ID UUID PRIMARY KEY,
+    SEQ BIGSERIAL NOT NULL UNIQUE,
+    ACTOR_ID UUID,
+    REQUEST_ID TEXT DEFAULT '' NOT NULL,
+    ACTION VARCHAR(20) NOT NULL,
+    ENTITY_TYPE VARCHAR(50) NOT NULL,
+    ENTITY_ID UUID NOT NULL,
+    CHANGES TEXT NOT NULL,
+    PREV_HASH TEXT NOT NULL,
+    HASH TEXT NOT NULL,
+    CREATED_AT TIMESTAMP DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS IDX_AUDIT_LOG_ENTITY ON AUDIT_LOG (ENTITY_TYPE, ENTITY_ID);
+CREATE INDEX IF NOT EXISTS IDX_AUDIT_LOG_ACTOR_ID ON AUDIT_LOG (ACTOR_ID);
+CREATE INDEX IF NOT EXISTS IDX_AUDIT_LOG_CREATED_AT ON AUDIT_LOG (CREATED_AT);
+
+INSERT INTO PERMISSIONS (NAME, DESCRIPTION) VALUES
+    ('audit:read', 'Read the audit log')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO ROLE_PERMISSIONS (ROLE_NAME, PERMISSION_NAME) VALUES
+    ('admin', 'audit:read')
+ON CONFLICT DO NOTHING;
\ No newline at end of file
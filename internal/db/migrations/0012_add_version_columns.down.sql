@@ -0,0 +1,2 @@
+ALTER TABLE INSTRUMENTS DROP COLUMN IF EXISTS VERSION;
+ALTER TABLE USERS DROP COLUMN IF EXISTS VERSION;
\ No newline at end of file
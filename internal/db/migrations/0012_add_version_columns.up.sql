@@ -0,0 +1,2 @@
+ALTER TABLE USERS ADD COLUMN IF NOT EXISTS VERSION BIGINT DEFAULT 1 NOT NULL;
+ALTER TABLE INSTRUMENTS ADD COLUMN IF NOT EXISTS VERSION BIGINT DEFAULT 1 NOT NULL;
\ No newline at end of file
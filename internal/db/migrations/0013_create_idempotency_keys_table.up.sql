@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS IDEMPOTENCY_KEYS (
+    SCOPE TEXT NOT NULL,
+    KEY TEXT NOT NULL,
+    FINGERPRINT TEXT NOT NULL,
+    STATUS_CODE INTEGER NOT NULL,
+    HEADERS TEXT NOT NULL,
+    BODY BYTEA NOT NULL,
+    CREATED_AT TIMESTAMP DEFAULT NOW() NOT NULL,
+    EXPIRES_AT TIMESTAMP NOT NULL,
+    PRIMARY KEY (SCOPE, KEY)
+);
+
+CREATE INDEX IF NOT EXISTS IDX_IDEMPOTENCY_KEYS_EXPIRES_AT ON IDEMPOTENCY_KEYS (EXPIRES_AT);
\ No newline at end of file
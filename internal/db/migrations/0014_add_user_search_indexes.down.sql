@@ -0,0 +1,10 @@
+-- pg_trgm is kept, other objects of the database may depend on it.
+DROP INDEX IF EXISTS IDX_USERS_EMAIL_TRGM;
+DROP INDEX IF EXISTS IDX_USERS_LAST_NAME_TRGM;
+DROP INDEX IF EXISTS IDX_USERS_FIRST_NAME_TRGM;
+DROP INDEX IF EXISTS IDX_USERS_NAME_ORDER;
+DROP INDEX IF EXISTS IDX_USERS_LAST_NAME_PREFIX;
+DROP INDEX IF EXISTS IDX_USERS_FIRST_NAME_PREFIX;
+DROP INDEX IF EXISTS IDX_USERS_EMAIL_DOMAIN;
+DROP INDEX IF EXISTS IDX_USERS_AGE;
+DROP INDEX IF EXISTS IDX_USERS_STATUS;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS IDX_USERS_STATUS ON USERS (STATUS);
+CREATE INDEX IF NOT EXISTS IDX_USERS_AGE ON USERS (AGE);
+CREATE INDEX IF NOT EXISTS IDX_USERS_EMAIL_DOMAIN ON USERS (LOWER(SPLIT_PART(EMAIL, '@', 2)));
+CREATE INDEX IF NOT EXISTS IDX_USERS_FIRST_NAME_PREFIX ON USERS (LOWER(FIRST_NAME) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS IDX_USERS_LAST_NAME_PREFIX ON USERS (LOWER(LAST_NAME) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS IDX_USERS_NAME_ORDER ON USERS (LAST_NAME, FIRST_NAME, USER_ID);
+CREATE INDEX IF NOT EXISTS IDX_USERS_FIRST_NAME_TRGM ON USERS USING GIN (FIRST_NAME gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS IDX_USERS_LAST_NAME_TRGM ON USERS USING GIN (LAST_NAME gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS IDX_USERS_EMAIL_TRGM ON USERS USING GIN (EMAIL gin_trgm_ops);
\ No newline at end of file
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS IDX_INSTRUMENTS_UPDATED_AT;
+DROP INDEX IF EXISTS IDX_INSTRUMENTS_LAST_PRICE;
+DROP INDEX IF EXISTS IDX_INSTRUMENTS_SYMBOL_PREFIX;
+DROP INDEX IF EXISTS IDX_INSTRUMENTS_TYPE;
+DROP INDEX IF EXISTS IDX_INSTRUMENTS_EXCHANGE_TYPE;
\ No newline at end of file
@@ -0,0 +1,5 @@
+CREATE INDEX IF NOT EXISTS IDX_INSTRUMENTS_EXCHANGE_TYPE ON INSTRUMENTS (EXCHANGE, INSTRUMENT_TYPE);
+CREATE INDEX IF NOT EXISTS IDX_INSTRUMENTS_TYPE ON INSTRUMENTS (INSTRUMENT_TYPE);
+CREATE INDEX IF NOT EXISTS IDX_INSTRUMENTS_SYMBOL_PREFIX ON INSTRUMENTS (SYMBOL text_pattern_ops);
+CREATE INDEX IF NOT EXISTS IDX_INSTRUMENTS_LAST_PRICE ON INSTRUMENTS (LAST_PRICE);
+CREATE INDEX IF NOT EXISTS IDX_INSTRUMENTS_UPDATED_AT ON INSTRUMENTS (UPDATED_AT);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS MFA_FAILED_ATTEMPTS;
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS MFA_FAILED_ATTEMPTS (
+    ID UUID PRIMARY KEY,
+    USER_ID UUID NOT NULL REFERENCES USERS (USER_ID) ON DELETE CASCADE,
+    CHALLENGE_ID UUID,
+    FAILED_AT TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS IDX_MFA_FAILED_ATTEMPTS_USER_ID ON MFA_FAILED_ATTEMPTS (USER_ID, FAILED_AT);
+CREATE INDEX IF NOT EXISTS IDX_MFA_FAILED_ATTEMPTS_CHALLENGE_ID ON MFA_FAILED_ATTEMPTS (CHALLENGE_ID);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE IDEMPOTENCY_KEYS DROP COLUMN IF EXISTS CLAIM_ID;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE IDEMPOTENCY_KEYS ADD COLUMN IF NOT EXISTS CLAIM_ID UUID DEFAULT gen_random_uuid() NOT NULL;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS MFA_CHALLENGES;
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS MFA_CHALLENGES (
+    ID UUID PRIMARY KEY,
+    USER_ID UUID NOT NULL REFERENCES USERS (USER_ID) ON DELETE CASCADE,
+    ATTEMPTS INTEGER NOT NULL,
+    CONSUMED_AT TIMESTAMP,
+    EXPIRES_AT TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS IDX_MFA_CHALLENGES_USER_ID ON MFA_CHALLENGES (USER_ID, EXPIRES_AT);
\ No newline at end of file
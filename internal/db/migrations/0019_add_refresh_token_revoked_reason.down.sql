@@ -0,0 +1 @@
+ALTER TABLE REFRESH_TOKENS DROP COLUMN IF EXISTS REVOKED_REASON;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE REFRESH_TOKENS ADD COLUMN IF NOT EXISTS REVOKED_REASON VARCHAR(20);
\ No newline at end of file
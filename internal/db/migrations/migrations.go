@@ -0,0 +1,11 @@
+// Package migrations holds the SQL migrations of the database schema. Each
+// version has an NNNN_name.up.sql file and an NNNN_name.down.sql file that
+// reverts it.
+package migrations
+
+import "embed"
+
+// FS contains the migration files.
+//
+//go:embed *.sql
+var FS embed.FS
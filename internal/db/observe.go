@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"user-management/internal/db/sqlc"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// UnnamedQuery names statements without a sqlc name comment.
+const UnnamedQuery = "unnamed"
+
+// QueryHook observes the statements run through a DBTX returned by Observe.
+// It is called before a statement runs and returns the context the statement
+// runs with and the function called with its outcome.
+type QueryHook func(ctx context.Context, name string) (context.Context, func(err error))
+
+var queryHooks []QueryHook
+
+// AddQueryHook registers a hook for the statements run through Observe.
+// Hooks must be added at startup, before connections are observed.
+func AddQueryHook(hook QueryHook) {
+	queryHooks = append(queryHooks, hook)
+}
+
+// Observe returns conn running the registered hooks around every statement,
+// or conn itself when no hook is registered.
+func Observe(conn sqlc.DBTX) sqlc.DBTX {
+	if len(queryHooks) == 0 {
+		return conn
+	}
+	return &observed{conn: conn, hooks: slices.Clone(queryHooks)}
+}
+
+// QueryName returns the name sqlc gives a query in the comment it starts
+// with, for example GetUserById.
+func QueryName(query string) string {
+	rest, ok := strings.CutPrefix(query, "-- name: ")
+	if !ok {
+		return UnnamedQuery
+	}
+	name, _, _ := strings.Cut(rest, " ")
+	return name
+}
+
+type observed struct {
+	conn  sqlc.DBTX
+	hooks []QueryHook
+}
+
+// start runs the hooks before a statement and returns the function ending
+// them in reverse order.
+func (o *observed) start(ctx context.Context, query string) (context.Context, func(err error)) {
+	name := QueryName(query)
+	ends := make([]func(error), len(o.hooks))
+	for i, hook := range o.hooks {
+		ctx, ends[i] = hook(ctx, name)
+	}
+	return ctx, func(err error) {
+		for i := len(ends) - 1; i >= 0; i-- {
+			ends[i](err)
+		}
+	}
+}
+
+func (o *observed) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, end := o.start(ctx, query)
+	res, err := o.conn.ExecContext(ctx, query, args...)
+	end(err)
+	return res, err
+}
+
+func (o *observed) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	ctx, end := o.start(ctx, query)
+	stmt, err := o.conn.PrepareContext(ctx, query)
+	end(err)
+	return stmt, err
+}
+
+// QueryContext ends the hooks when the rows are closed, which QueryTracer
+// reports for connections opened by Connect. On other connections, and when
+// the statement fails, they end when QueryContext returns.
+func (o *observed) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, end := o.start(ctx, query)
+	q := &openQuery{end: end}
+	rows, err := o.conn.QueryContext(context.WithValue(ctx, openQueryKey{}, q), query, args...)
+	if err != nil || !q.traced.Load() {
+		q.finish(err)
+	}
+	return rows, err
+}
+
+// QueryRowContext reports the error of running the statement. Errors of
+// scanning the row, like sql.ErrNoRows, are not known yet.
+func (o *observed) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, end := o.start(ctx, query)
+	row := o.conn.QueryRowContext(ctx, query, args...)
+	end(row.Err())
+	return row
+}
+
+type openQueryKey struct{}
+
+// openQuery is a statement run through QueryContext whose rows may still be
+// open.
+type openQuery struct {
+	traced atomic.Bool
+	once   sync.Once
+	end    func(err error)
+}
+
+func (q *openQuery) finish(err error) {
+	q.once.Do(func() { q.end(err) })
+}
+
+// QueryTracer is the pgx tracer of the connections opened by Connect. pgx
+// reports the end of a query when its rows are closed, which ends the hooks
+// of statements run through QueryContext.
+type QueryTracer struct{}
+
+func (QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	if q, ok := ctx.Value(openQueryKey{}).(*openQuery); ok {
+		q.traced.Store(true)
+	}
+	return ctx
+}
+
+func (QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	if q, ok := ctx.Value(openQueryKey{}).(*openQuery); ok {
+		q.finish(data.Err)
+	}
+}
@@ -0,0 +1,13 @@
+-- name: CreateAccountToken :exec
+INSERT INTO ACCOUNT_TOKENS (ID, USER_ID, PURPOSE, TOKEN_HASH, EXPIRES_AT, CREATED_AT)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: FindAccountTokenByHash :one
+SELECT * FROM ACCOUNT_TOKENS WHERE TOKEN_HASH = $1 AND PURPOSE = $2 LIMIT 1;
+
+-- name: UseAccountToken :execrows
+UPDATE ACCOUNT_TOKENS SET USED_AT = $1 WHERE ID = $2 AND USED_AT IS NULL;
+
+-- name: InvalidateAccountTokens :exec
+UPDATE ACCOUNT_TOKENS SET USED_AT = $1
+WHERE USER_ID = $2 AND PURPOSE = $3 AND USED_AT IS NULL;
\ No newline at end of file
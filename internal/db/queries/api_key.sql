@@ -0,0 +1,23 @@
+-- name: CreateApiKey :one
+INSERT INTO API_KEYS (ID, USER_ID, NAME, PREFIX, KEY_HASH, SCOPES, EXPIRES_AT, CREATED_AT)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: FindApiKeyByPrefix :one
+SELECT * FROM API_KEYS WHERE PREFIX = $1 LIMIT 1;
+
+-- name: FindApiKeyById :one
+SELECT * FROM API_KEYS WHERE ID = $1 AND USER_ID = $2 LIMIT 1;
+
+-- name: ListUserApiKeys :many
+SELECT * FROM API_KEYS WHERE USER_ID = $1 ORDER BY CREATED_AT DESC;
+
+-- name: UpdateApiKey :one
+UPDATE API_KEYS SET NAME = $1, SCOPES = $2 WHERE ID = $3 AND USER_ID = $4
+RETURNING *;
+
+-- name: DeleteApiKey :execrows
+DELETE FROM API_KEYS WHERE ID = $1 AND USER_ID = $2;
+
+-- name: TouchApiKey :exec
+UPDATE API_KEYS SET LAST_USED_AT = $1 WHERE ID = $2;
\ No newline at end of file
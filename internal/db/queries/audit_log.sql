@@ -0,0 +1,23 @@
+-- name: CreateAuditLog :exec
+INSERT INTO AUDIT_LOG (ID, ACTOR_ID, REQUEST_ID, ACTION, ENTITY_TYPE, ENTITY_ID, CHANGES, PREV_HASH, HASH, CREATED_AT)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: LockAuditLog :exec
+SELECT pg_advisory_xact_lock(hashtext('AUDIT_LOG'));
+
+-- name: FindLastAuditLogHash :one
+SELECT HASH FROM AUDIT_LOG ORDER BY SEQ DESC LIMIT 1;
+
+-- name: ListAuditLog :many
+SELECT * FROM AUDIT_LOG
+WHERE (sqlc.narg('entity_type')::text IS NULL OR ENTITY_TYPE = sqlc.narg('entity_type'))
+  AND (sqlc.narg('entity_id')::uuid IS NULL OR ENTITY_ID = sqlc.narg('entity_id'))
+  AND (sqlc.narg('actor_id')::uuid IS NULL OR ACTOR_ID = sqlc.narg('actor_id'))
+  AND (sqlc.narg('action')::text IS NULL OR ACTION = sqlc.narg('action'))
+  AND (sqlc.narg('from_time')::timestamp IS NULL OR CREATED_AT >= sqlc.narg('from_time'))
+  AND (sqlc.narg('to_time')::timestamp IS NULL OR CREATED_AT < sqlc.narg('to_time'))
+ORDER BY SEQ DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListAuditLogAfter :many
+SELECT * FROM AUDIT_LOG WHERE SEQ > $1 ORDER BY SEQ LIMIT $2;
\ No newline at end of file
@@ -0,0 +1,26 @@
+-- name: ClaimIdempotencyKey :execrows
+INSERT INTO IDEMPOTENCY_KEYS (SCOPE, KEY, FINGERPRINT, STATUS_CODE, HEADERS, BODY, CREATED_AT, EXPIRES_AT, CLAIM_ID)
+VALUES (sqlc.arg('scope'), sqlc.arg('key'), sqlc.arg('fingerprint'), 0, '', '', sqlc.arg('created_at'), sqlc.arg('expires_at'), sqlc.arg('claim_id'))
+ON CONFLICT (SCOPE, KEY) DO UPDATE
+SET FINGERPRINT = EXCLUDED.FINGERPRINT,
+    STATUS_CODE = 0,
+    HEADERS = '',
+    BODY = '',
+    CREATED_AT = EXCLUDED.CREATED_AT,
+    EXPIRES_AT = EXCLUDED.EXPIRES_AT,
+    CLAIM_ID = EXCLUDED.CLAIM_ID
+WHERE IDEMPOTENCY_KEYS.EXPIRES_AT <= EXCLUDED.CREATED_AT;
+
+-- name: FindIdempotencyKey :one
+SELECT * FROM IDEMPOTENCY_KEYS WHERE SCOPE = $1 AND KEY = $2 LIMIT 1;
+
+-- name: CompleteIdempotencyKey :execrows
+UPDATE IDEMPOTENCY_KEYS
+SET STATUS_CODE = sqlc.arg('status_code'), HEADERS = sqlc.arg('headers'), BODY = sqlc.arg('body'), EXPIRES_AT = sqlc.arg('expires_at')
+WHERE SCOPE = sqlc.arg('scope') AND KEY = sqlc.arg('key') AND CLAIM_ID = sqlc.arg('claim_id') AND STATUS_CODE = 0;
+
+-- name: ReleaseIdempotencyKey :execrows
+DELETE FROM IDEMPOTENCY_KEYS WHERE SCOPE = $1 AND KEY = $2 AND CLAIM_ID = $3 AND STATUS_CODE = 0;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM IDEMPOTENCY_KEYS WHERE EXPIRES_AT <= $1;
\ No newline at end of file
@@ -4,23 +4,39 @@ VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING *;
 
 -- name: FindInstrumentById :one
-SELECT * FROM INSTRUMENTS WHERE ID = $1 LIMIT 1;
+SELECT * FROM INSTRUMENTS
+WHERE ID = sqlc.arg('id') AND (sqlc.arg('include_deleted')::bool OR DELETED_AT IS NULL)
+LIMIT 1;
 
--- name: ListAllInstrumentPaged :many
-SELECT * FROM INSTRUMENTS LIMIT $1 OFFSET $2;
+-- name: FindInstrumentBySymbol :one
+SELECT * FROM INSTRUMENTS
+WHERE SYMBOL = sqlc.arg('symbol') AND (sqlc.arg('include_deleted')::bool OR DELETED_AT IS NULL)
+LIMIT 1;
 
--- name: DeleteInstrumentById :exec
-DELETE FROM INSTRUMENTS WHERE ID = $1;
+-- name: SoftDeleteInstrument :execrows
+UPDATE INSTRUMENTS
+SET DELETED_AT = sqlc.arg('deleted_at')::timestamp, DELETED_BY = sqlc.narg('deleted_by'), VERSION = VERSION + 1
+WHERE ID = sqlc.arg('id') AND VERSION = sqlc.arg('version') AND DELETED_AT IS NULL;
+
+-- name: RestoreInstrument :execrows
+UPDATE INSTRUMENTS
+SET DELETED_AT = NULL, DELETED_BY = NULL, VERSION = VERSION + 1
+WHERE ID = sqlc.arg('id') AND DELETED_AT IS NOT NULL;
+
+-- name: PurgeDeletedInstruments :many
+DELETE FROM INSTRUMENTS WHERE DELETED_AT IS NOT NULL AND DELETED_AT < sqlc.arg('deleted_before')::timestamp
+RETURNING ID;
 
 -- name: UpdateInstrument :one
 UPDATE INSTRUMENTS
 SET
     SYMBOL = COALESCE(sqlc.narg('symbol'), SYMBOL),
     NAME  = COALESCE(sqlc.narg('name'), NAME),
-    INSTRUMENT_TYPE      = COALESCE(sqlc.narg('instrument_type'), EXCHANGE),
-    EXCHANGE      = COALESCE(sqlc.narg('exchange'), PHONE),
+    INSTRUMENT_TYPE      = COALESCE(sqlc.narg('instrument_type'), INSTRUMENT_TYPE),
+    EXCHANGE      = COALESCE(sqlc.narg('exchange'), EXCHANGE),
     LAST_PRICE        = COALESCE(sqlc.narg('last_price'), LAST_PRICE),
     CREATED_AT     = COALESCE(sqlc.narg('created_at'), CREATED_AT),
-    UPDATED_AT     = COALESCE(sqlc.narg('updated_at'), UPDATED_AT)
-WHERE ID = sqlc.arg('id')
+    UPDATED_AT     = COALESCE(sqlc.narg('updated_at'), UPDATED_AT),
+    VERSION        = VERSION + 1
+WHERE ID = sqlc.arg('id') AND VERSION = sqlc.arg('version')
 RETURNING *;
\ No newline at end of file
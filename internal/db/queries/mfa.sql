@@ -0,0 +1,61 @@
+-- name: SetUserMfaSecret :exec
+UPDATE USERS
+SET MFA_SECRET = $1, MFA_ENABLED = FALSE, MFA_LAST_STEP = NULL
+WHERE USER_ID = $2;
+
+-- name: EnableUserMfa :execrows
+UPDATE USERS SET MFA_ENABLED = TRUE, VERSION = VERSION + 1 WHERE USER_ID = $1 AND MFA_SECRET IS NOT NULL;
+
+-- name: DisableUserMfa :exec
+UPDATE USERS
+SET MFA_SECRET = NULL, MFA_ENABLED = FALSE, MFA_LAST_STEP = NULL, VERSION = VERSION + 1
+WHERE USER_ID = $1;
+
+-- name: UpdateUserMfaLastStep :execrows
+UPDATE USERS
+SET MFA_LAST_STEP = sqlc.arg('mfa_last_step')
+WHERE USER_ID = sqlc.arg('user_id')
+  AND (MFA_LAST_STEP IS NULL OR MFA_LAST_STEP < sqlc.arg('mfa_last_step'));
+
+-- name: CreateMfaRecoveryCode :exec
+INSERT INTO MFA_RECOVERY_CODES (ID, USER_ID, CODE_HASH, CREATED_AT)
+VALUES ($1, $2, $3, $4);
+
+-- name: DeleteMfaRecoveryCodes :exec
+DELETE FROM MFA_RECOVERY_CODES WHERE USER_ID = $1;
+
+-- name: UseMfaRecoveryCode :execrows
+UPDATE MFA_RECOVERY_CODES
+SET USED_AT = $1
+WHERE USER_ID = $2 AND CODE_HASH = $3 AND USED_AT IS NULL;
+
+-- name: CountUnusedMfaRecoveryCodes :one
+SELECT COUNT(*) FROM MFA_RECOVERY_CODES WHERE USER_ID = $1 AND USED_AT IS NULL;
+
+-- name: CreateMfaFailedAttempt :exec
+INSERT INTO MFA_FAILED_ATTEMPTS (ID, USER_ID, CHALLENGE_ID, FAILED_AT)
+VALUES ($1, $2, $3, $4);
+
+-- name: DeleteMfaFailedAttemptsBefore :exec
+DELETE FROM MFA_FAILED_ATTEMPTS WHERE USER_ID = $1 AND FAILED_AT <= $2;
+
+-- name: CountMfaFailedAttemptsSince :one
+SELECT COUNT(*) FROM MFA_FAILED_ATTEMPTS WHERE USER_ID = $1 AND FAILED_AT > $2;
+
+-- name: StartMfaChallengeAttempt :execrows
+INSERT INTO MFA_CHALLENGES (ID, USER_ID, ATTEMPTS, EXPIRES_AT)
+VALUES (sqlc.arg('id'), sqlc.arg('user_id'), 1, sqlc.arg('expires_at'))
+ON CONFLICT (ID) DO UPDATE
+SET ATTEMPTS = MFA_CHALLENGES.ATTEMPTS + 1
+WHERE MFA_CHALLENGES.USER_ID = EXCLUDED.USER_ID
+  AND MFA_CHALLENGES.CONSUMED_AT IS NULL
+  AND MFA_CHALLENGES.ATTEMPTS < sqlc.arg('max_attempts');
+
+-- name: ConsumeMfaChallenge :execrows
+UPDATE MFA_CHALLENGES SET CONSUMED_AT = $1 WHERE ID = $2 AND CONSUMED_AT IS NULL;
+
+-- name: FindMfaChallenge :one
+SELECT * FROM MFA_CHALLENGES WHERE ID = $1 LIMIT 1;
+
+-- name: DeleteExpiredMfaChallenges :exec
+DELETE FROM MFA_CHALLENGES WHERE USER_ID = $1 AND EXPIRES_AT <= $2;
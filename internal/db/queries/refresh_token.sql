@@ -0,0 +1,17 @@
+-- name: CreateRefreshToken :one
+INSERT INTO REFRESH_TOKENS (ID, USER_ID, TOKEN_HASH, EXPIRES_AT, CREATED_AT)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: FindRefreshTokenByHash :one
+SELECT * FROM REFRESH_TOKENS WHERE TOKEN_HASH = $1 LIMIT 1;
+
+-- name: RevokeRefreshToken :execrows
+UPDATE REFRESH_TOKENS
+SET REVOKED_AT = sqlc.arg('revoked_at'), REVOKED_REASON = sqlc.arg('revoked_reason')
+WHERE ID = sqlc.arg('id') AND REVOKED_AT IS NULL;
+
+-- name: RevokeUserRefreshTokens :exec
+UPDATE REFRESH_TOKENS
+SET REVOKED_AT = sqlc.arg('revoked_at'), REVOKED_REASON = sqlc.arg('revoked_reason')
+WHERE USER_ID = sqlc.arg('user_id') AND REVOKED_AT IS NULL;
\ No newline at end of file
@@ -0,0 +1,31 @@
+-- name: ListRoles :many
+SELECT * FROM ROLES ORDER BY NAME;
+
+-- name: FindRoleByName :one
+SELECT * FROM ROLES WHERE NAME = $1 LIMIT 1;
+
+-- name: ListRolePermissions :many
+SELECT * FROM ROLE_PERMISSIONS ORDER BY ROLE_NAME, PERMISSION_NAME;
+
+-- name: ListUserRoles :many
+SELECT R.* FROM ROLES R
+JOIN USER_ROLES UR ON UR.ROLE_NAME = R.NAME
+WHERE UR.USER_ID = $1
+ORDER BY R.NAME;
+
+-- name: ListUserPermissions :many
+SELECT DISTINCT RP.PERMISSION_NAME FROM USER_ROLES UR
+JOIN ROLE_PERMISSIONS RP ON RP.ROLE_NAME = UR.ROLE_NAME
+WHERE UR.USER_ID = $1
+ORDER BY RP.PERMISSION_NAME;
+
+-- name: AssignUserRole :exec
+INSERT INTO USER_ROLES (USER_ID, ROLE_NAME)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveUserRole :execrows
+DELETE FROM USER_ROLES WHERE USER_ID = $1 AND ROLE_NAME = $2;
+
+-- name: UpdateRoleRequireMfa :execrows
+UPDATE ROLES SET REQUIRE_MFA = $1 WHERE NAME = $2;
\ No newline at end of file
@@ -1,16 +1,29 @@
 -- name: CreateUser :one
-INSERT INTO USERS (USER_ID, FIRST_NAME, LAST_NAME, EMAIL, PHONE, AGE, STATUS)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO USERS (USER_ID, FIRST_NAME, LAST_NAME, EMAIL, PHONE, AGE, STATUS, PASSWORD_HASH)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING *;
 
 -- name: FindUserById :one
-SELECT * FROM USERS WHERE USER_ID = $1 LIMIT 1;
+SELECT * FROM USERS
+WHERE USER_ID = sqlc.arg('user_id') AND (sqlc.arg('include_deleted')::bool OR DELETED_AT IS NULL)
+LIMIT 1;
 
--- name: ListAllUsersPaged :many
-SELECT * FROM USERS LIMIT $1 OFFSET $2;
+-- name: FindUserByEmail :one
+SELECT * FROM USERS WHERE EMAIL = $1 AND DELETED_AT IS NULL LIMIT 1;
 
--- name: DeleteUserByID :exec
-DELETE FROM USERS WHERE USER_ID = $1;
+-- name: SoftDeleteUser :execrows
+UPDATE USERS
+SET DELETED_AT = sqlc.arg('deleted_at')::timestamp, DELETED_BY = sqlc.narg('deleted_by'), STATUS = sqlc.arg('status'), VERSION = VERSION + 1
+WHERE USER_ID = sqlc.arg('user_id') AND VERSION = sqlc.arg('version') AND DELETED_AT IS NULL;
+
+-- name: RestoreUser :execrows
+UPDATE USERS
+SET DELETED_AT = NULL, DELETED_BY = NULL, STATUS = sqlc.arg('status'), VERSION = VERSION + 1
+WHERE USER_ID = sqlc.arg('user_id') AND DELETED_AT IS NOT NULL;
+
+-- name: PurgeDeletedUsers :many
+DELETE FROM USERS WHERE DELETED_AT IS NOT NULL AND DELETED_AT < sqlc.arg('deleted_before')::timestamp
+RETURNING USER_ID;
 
 -- name: UpdateUser :one
 UPDATE users
@@ -20,6 +33,20 @@ SET
     EMAIL      = COALESCE(sqlc.narg('email'), EMAIL),
     PHONE      = COALESCE(sqlc.narg('phone'), PHONE),
     AGE        = COALESCE(sqlc.narg('age'), AGE),
-    STATUS     = COALESCE(sqlc.narg('status'), STATUS)
-WHERE user_id = sqlc.arg('user_id')
-RETURNING *;
\ No newline at end of file
+    STATUS     = COALESCE(sqlc.narg('status'), STATUS),
+    VERSION    = VERSION + 1
+WHERE user_id = sqlc.arg('user_id') AND VERSION = sqlc.arg('version')
+RETURNING *;
+
+-- name: UpdateUserPassword :exec
+UPDATE USERS
+SET PASSWORD_HASH = sqlc.arg('password_hash')
+WHERE USER_ID = sqlc.arg('user_id');
+
+-- name: UpdateUserStatus :execrows
+UPDATE USERS
+SET STATUS = sqlc.arg('status'), VERSION = VERSION + 1
+WHERE USER_ID = sqlc.arg('user_id') AND STATUS = sqlc.arg('from_status');
+
+-- name: CountUsersByStatus :many
+SELECT STATUS, COUNT(*) AS COUNT FROM USERS WHERE DELETED_AT IS NULL GROUP BY STATUS;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- name: CreateUserStatusTransition :exec
+INSERT INTO USER_STATUS_TRANSITIONS (ID, USER_ID, FROM_STATUS, TO_STATUS, REASON, ACTOR_ID, CREATED_AT)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: ListUserStatusTransitions :many
+SELECT * FROM USER_STATUS_TRANSITIONS WHERE USER_ID = $1 ORDER BY CREATED_AT DESC;
\ No newline at end of file
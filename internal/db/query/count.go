@@ -0,0 +1,48 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// Querier runs a query returning a single row, as *sql.DB and *sql.Tx do.
+type Querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Total counts the rows matching the conditions of b with the given mode. It
+// must be called before the keyset condition and the page are added. The
+// returned count is nil for CountNone.
+func Total(ctx context.Context, db Querier, b *Builder, mode CountMode) (*int64, error) {
+	var total int64
+
+	switch mode {
+	case CountExact:
+		stmt, args := b.CountSQL()
+		if err := db.QueryRowContext(ctx, stmt, args...).Scan(&total); err != nil {
+			return nil, err
+		}
+	case CountEstimated:
+		stmt, args := b.SQL()
+		var plan []byte
+		if err := db.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) "+stmt, args...).Scan(&plan); err != nil {
+			return nil, err
+		}
+
+		var explained []struct {
+			Plan struct {
+				Rows float64 `json:"Plan Rows"`
+			} `json:"Plan"`
+		}
+		if err := json.Unmarshal(plan, &explained); err != nil || len(explained) == 0 {
+			return nil, fmt.Errorf("unexpected query plan: %s", plan)
+		}
+		total = int64(explained[0].Plan.Rows)
+	default:
+		return nil, nil
+	}
+
+	return &total, nil
+}
@@ -0,0 +1,81 @@
+package query
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"user-management/internal/common/apperror"
+)
+
+var ErrInvalidCursor = apperror.New(apperror.Validation, "invalid_cursor", "invalid cursor")
+
+// Cursor points next to a row of a keyset paginated list. Values are the
+// values of the row for each field of Sort, formatted as text. A backward
+// cursor selects the rows before the row instead of after it.
+type Cursor struct {
+	Sort     string   `json:"s"`
+	Values   []string `json:"v"`
+	Backward bool     `json:"b,omitempty"`
+}
+
+// CursorCodec turns cursors into opaque tokens signed with HMAC-SHA256, so
+// clients cannot craft or alter them.
+type CursorCodec struct {
+	secret []byte
+}
+
+// NewCursorCodec creates a codec signing with secret. Without a secret a
+// random one is generated, and cursors stop working when the server restarts.
+func NewCursorCodec(secret string) *CursorCodec {
+	if secret == "" {
+		slog.Warn("No pagination cursor secret configured, cursors are only valid until the server restarts")
+		key := make([]byte, 32)
+		rand.Read(key)
+		return &CursorCodec{secret: key}
+	}
+	return &CursorCodec{secret: []byte(secret)}
+}
+
+func (c *CursorCodec) Encode(cur Cursor) string {
+	payload, _ := json.Marshal(cur)
+	return encode(payload) + "." + encode(c.sign(payload))
+}
+
+// Decode verifies and reads a token created by Encode. It returns
+// ErrInvalidCursor for tokens that are malformed or were not signed with the
+// secret of the codec.
+func (c *CursorCodec) Decode(token string) (Cursor, error) {
+	encodedPayload, encodedSignature, found := strings.Cut(token, ".")
+	if !found {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, c.sign(payload)) {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	var cur Cursor
+	if err := json.Unmarshal(payload, &cur); err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return cur, nil
+}
+
+func (c *CursorCodec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,52 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InsertBuilder assembles an INSERT statement adding many rows at once.
+type InsertBuilder struct {
+	table     string
+	columns   int
+	names     string
+	rows      []string
+	returning string
+	args      []any
+}
+
+// Insert starts a statement inserting into the comma separated columns of
+// table.
+func Insert(table string, columns string) *InsertBuilder {
+	return &InsertBuilder{table: table, names: columns, columns: len(strings.Split(columns, ","))}
+}
+
+// Values adds a row with a value for each column.
+func (b *InsertBuilder) Values(values ...any) *InsertBuilder {
+	if len(values) != b.columns {
+		panic(fmt.Sprintf("query: %d values for %d columns of %s", len(values), b.columns, b.table))
+	}
+
+	placeholders := make([]string, len(values))
+	for i, v := range values {
+		b.args = append(b.args, v)
+		placeholders[i] = fmt.Sprintf("$%d", len(b.args))
+	}
+	b.rows = append(b.rows, "("+strings.Join(placeholders, ", ")+")")
+	return b
+}
+
+// Returning makes the statement return the given columns of the inserted rows.
+func (b *InsertBuilder) Returning(columns string) *InsertBuilder {
+	b.returning = columns
+	return b
+}
+
+// SQL returns the statement and its arguments.
+func (b *InsertBuilder) SQL() (string, []any) {
+	stmt := "INSERT INTO " + b.table + " (" + b.names + ") VALUES " + strings.Join(b.rows, ", ")
+	if b.returning != "" {
+		stmt += " RETURNING " + b.returning
+	}
+	return stmt, b.args
+}
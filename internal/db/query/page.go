@@ -0,0 +1,105 @@
+package query
+
+import (
+	"fmt"
+	"slices"
+	"user-management/internal/common/apperror"
+)
+
+var ErrInvalidCount = apperror.New(apperror.Validation, "invalid_count", "invalid count")
+
+// CountMode selects whether a page reports the total number of rows.
+type CountMode string
+
+const (
+	// CountNone does not report a total.
+	CountNone CountMode = ""
+	// CountExact counts the matching rows.
+	CountExact CountMode = "exact"
+	// CountEstimated uses the row estimate of the query planner, which is
+	// cheap on large tables but can be far off.
+	CountEstimated CountMode = "estimated"
+)
+
+// ParseCountMode reads the count query parameter, which is empty, exact or
+// estimated.
+func ParseCountMode(s string) (CountMode, error) {
+	switch mode := CountMode(s); mode {
+	case CountNone, CountExact, CountEstimated:
+		return mode, nil
+	default:
+		return CountNone, ErrInvalidCount.WithMessage(fmt.Sprintf("invalid count %q, expected exact or estimated", s))
+	}
+}
+
+// PageRequest selects a page of Limit rows, by keyset when Cursor is set and
+// by Offset otherwise.
+type PageRequest struct {
+	Limit  int
+	Offset int
+	Cursor string
+	Count  CountMode
+}
+
+// PageInfo holds the cursors of the neighbouring pages, which are empty when
+// there is no such page, and the total requested with the Count mode.
+type PageInfo struct {
+	Next      string
+	Prev      string
+	Total     *int64
+	Estimated bool
+}
+
+type Page[T any] struct {
+	Items []T
+	PageInfo
+}
+
+// Paginate fetches the page selected by req and creates the cursors of its
+// neighbours. keys must end with a unique tiebreaker. fetch gets the decoded
+// cursor, nil for offset pages, and returns up to limit rows ordered by keys,
+// or by the reversed keys for a backward cursor. values returns the values
+// of a row for each of the keys.
+func Paginate[T any](codec *CursorCodec, keys Sort, req PageRequest, fetch func(cur *Cursor, limit int, offset int) ([]T, error), values func(row T) []string) (Page[T], error) {
+	var cur *Cursor
+	offset := req.Offset
+	if req.Cursor != "" {
+		decoded, err := codec.Decode(req.Cursor)
+		if err != nil {
+			return Page[T]{}, err
+		}
+		if decoded.Sort != keys.String() || len(decoded.Values) != len(keys) {
+			return Page[T]{}, ErrInvalidCursor.WithMessage("the cursor was created for another sort")
+		}
+		cur = &decoded
+		offset = 0
+	}
+
+	rows, err := fetch(cur, req.Limit+1, offset)
+	if err != nil {
+		return Page[T]{}, err
+	}
+
+	more := len(rows) > req.Limit
+	if more {
+		rows = rows[:req.Limit]
+	}
+
+	hasNext, hasPrev := more, cur != nil || offset > 0
+	if cur != nil && cur.Backward {
+		slices.Reverse(rows)
+		hasNext, hasPrev = true, more
+	}
+
+	page := Page[T]{Items: rows}
+	if len(rows) == 0 {
+		return page, nil
+	}
+	if hasNext {
+		page.Next = codec.Encode(Cursor{Sort: keys.String(), Values: values(rows[len(rows)-1])})
+	}
+	if hasPrev {
+		page.Prev = codec.Encode(Cursor{Sort: keys.String(), Values: values(rows[0]), Backward: true})
+	}
+	return page, nil
+}
@@ -0,0 +1,145 @@
+// Package query builds the SELECT statements of list endpoints whose filters
+// and order are chosen at runtime. Values are always passed as positional
+// arguments and only whitelisted column names are written into the SQL.
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"user-management/internal/common/apperror"
+)
+
+// ErrInvalidFilter is returned for filter query parameters that cannot be
+// parsed, with a message naming the parameter.
+var ErrInvalidFilter = apperror.New(apperror.Validation, "invalid_filter", "invalid filter")
+
+// Builder assembles a SELECT statement.
+type Builder struct {
+	columns string
+	table   string
+	where   []string
+	order   []string
+	limit   string
+	offset  string
+	args    []any
+}
+
+// Select starts a statement selecting columns from table.
+func Select(columns string, table string) *Builder {
+	return &Builder{columns: columns, table: table}
+}
+
+// Where adds a condition, all conditions must match. Each ? in cond is
+// replaced by a placeholder for the next of args.
+func (b *Builder) Where(cond string, args ...any) *Builder {
+	var sb strings.Builder
+	for _, part := range strings.SplitAfter(cond, "?") {
+		if !strings.HasSuffix(part, "?") {
+			sb.WriteString(part)
+			continue
+		}
+		if len(args) == 0 {
+			panic(fmt.Sprintf("query: missing argument in %q", cond))
+		}
+		sb.WriteString(strings.TrimSuffix(part, "?"))
+		sb.WriteString(b.arg(args[0]))
+		args = args[1:]
+	}
+	if len(args) > 0 {
+		panic(fmt.Sprintf("query: too many arguments for %q", cond))
+	}
+
+	b.where = append(b.where, "("+sb.String()+")")
+	return b
+}
+
+// Seek adds the keyset condition selecting the rows after the row with the
+// given values for keys, in the order of keys.
+func (b *Builder) Seek(keys Sort, values []string) *Builder {
+	var or []string
+	for i, f := range keys {
+		var and []string
+		for j := range i {
+			and = append(and, keys[j].Column+" = "+b.arg(values[j])+"::text::"+keys[j].Type)
+		}
+		op := " > "
+		if f.Desc {
+			op = " < "
+		}
+		and = append(and, f.Column+op+b.arg(values[i])+"::text::"+f.Type)
+		or = append(or, "("+strings.Join(and, " AND ")+")")
+	}
+
+	b.where = append(b.where, "("+strings.Join(or, " OR ")+")")
+	return b
+}
+
+// OrderBy sorts by the fields of s.
+func (b *Builder) OrderBy(s Sort) *Builder {
+	for _, f := range s {
+		b.order = append(b.order, f.Column+direction(f.Desc))
+	}
+	return b
+}
+
+// Page limits the result to limit rows starting at offset.
+func (b *Builder) Page(limit int, offset int) *Builder {
+	b.limit = b.arg(limit)
+	b.offset = b.arg(offset)
+	return b
+}
+
+// CountSQL returns a statement counting the rows matching the conditions.
+func (b *Builder) CountSQL() (string, []any) {
+	return b.from("SELECT COUNT(*)"), b.args
+}
+
+// SQL returns the statement and its arguments.
+func (b *Builder) SQL() (string, []any) {
+	var sb strings.Builder
+	sb.WriteString(b.from("SELECT " + b.columns))
+	if len(b.order) > 0 {
+		sb.WriteString(" ORDER BY " + strings.Join(b.order, ", "))
+	}
+	if b.limit != "" {
+		sb.WriteString(" LIMIT " + b.limit + " OFFSET " + b.offset)
+	}
+	return sb.String(), b.args
+}
+
+func (b *Builder) from(selectClause string) string {
+	stmt := selectClause + " FROM " + b.table
+	if len(b.where) > 0 {
+		stmt += " WHERE " + strings.Join(b.where, " AND ")
+	}
+	return stmt
+}
+
+func (b *Builder) arg(v any) string {
+	b.args = append(b.args, v)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+func direction(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+	return " ASC"
+}
+
+// Contains returns a LIKE pattern matching values that contain s.
+func Contains(s string) string {
+	return "%" + escapeLike(s) + "%"
+}
+
+// Prefix returns a LIKE pattern matching values that start with s.
+func Prefix(s string) string {
+	return escapeLike(s) + "%"
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
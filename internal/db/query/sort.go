@@ -0,0 +1,87 @@
+package query
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"user-management/internal/common/apperror"
+)
+
+var ErrInvalidSort = apperror.New(apperror.Validation, "invalid_sort", "invalid sort")
+
+// SortField orders by Column, which is the column of the API field Name. Type
+// is the SQL type of the column, used to compare it with cursor values.
+type SortField struct {
+	Name   string
+	Column string
+	Type   string
+	Desc   bool
+}
+
+// Sort is an ordered list of sort fields.
+type Sort []SortField
+
+// ParseSort reads a comma separated list of field names, each optionally
+// prefixed with - for descending order, for example "lastName,-age". Only the
+// fields in fields are allowed. An empty value returns def.
+func ParseSort(value string, fields map[string]SortField, def Sort) (Sort, error) {
+	if value == "" {
+		return def, nil
+	}
+
+	var s Sort
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+
+		field, ok := fields[name]
+		if !ok {
+			return nil, ErrInvalidSort.WithMessage(fmt.Sprintf("invalid sort field %q, expected one of %s", name, strings.Join(fieldNames(fields), ", ")))
+		}
+		if slices.ContainsFunc(s, func(f SortField) bool { return f.Name == name }) {
+			return nil, ErrInvalidSort.WithMessage(fmt.Sprintf("duplicate sort field %q", name))
+		}
+		field.Name = name
+		field.Desc = desc
+		s = append(s, field)
+	}
+	return s, nil
+}
+
+// With returns the sort followed by the unique tiebreaker field, which makes
+// the order total and stable between pages.
+func (s Sort) With(tiebreaker SortField) Sort {
+	return append(slices.Clip(s), tiebreaker)
+}
+
+// Reverse returns the sort with every direction flipped.
+func (s Sort) Reverse() Sort {
+	r := slices.Clone(s)
+	for i := range r {
+		r[i].Desc = !r[i].Desc
+	}
+	return r
+}
+
+// String formats the sort the way ParseSort reads it.
+func (s Sort) String() string {
+	names := make([]string, len(s))
+	for i, f := range s {
+		if f.Desc {
+			names[i] = "-" + f.Name
+		} else {
+			names[i] = f.Name
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+func fieldNames(fields map[string]SortField) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
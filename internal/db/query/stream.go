@@ -0,0 +1,47 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Stream runs the statement as a server side cursor in tx and calls each for
+// every row. Rows are fetched batchSize at a time, so memory use does not
+// grow with the number of rows. each must only scan the current row.
+func Stream(ctx context.Context, tx *sql.Tx, stmt string, args []any, batchSize int, each func(rows *sql.Rows) error) error {
+	if _, err := tx.ExecContext(ctx, "DECLARE stream_cursor NO SCROLL CURSOR FOR "+stmt, args...); err != nil {
+		return err
+	}
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM stream_cursor", batchSize)
+	for {
+		fetched, err := fetchRows(ctx, tx, fetch, each)
+		if err != nil {
+			return err
+		}
+		if fetched < batchSize {
+			break
+		}
+	}
+
+	_, err := tx.ExecContext(ctx, "CLOSE stream_cursor")
+	return err
+}
+
+func fetchRows(ctx context.Context, tx *sql.Tx, fetch string, each func(rows *sql.Rows) error) (int, error) {
+	rows, err := tx.QueryContext(ctx, fetch)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	fetched := 0
+	for rows.Next() {
+		fetched++
+		if err := each(rows); err != nil {
+			return fetched, err
+		}
+	}
+	return fetched, rows.Err()
+}
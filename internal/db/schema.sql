@@ -5,10 +5,17 @@ CREATE TABLE IF NOT EXISTS USERS (
   EMAIL TEXT UNIQUE NOT NULL,  
   PHONE TEXT NOT NULL,  
   AGE SMALLINT NOT NULL,
-  STATUS TEXT NOT NULL
+  STATUS TEXT NOT NULL,
+  PASSWORD_HASH TEXT,
+  MFA_SECRET TEXT,
+  MFA_ENABLED BOOLEAN DEFAULT FALSE NOT NULL,
+  MFA_LAST_STEP BIGINT,
+  DELETED_AT TIMESTAMP,
+  DELETED_BY UUID,
+  VERSION BIGINT DEFAULT 1 NOT NULL
 );
 
-CREATE TABLE INSTRUMENTS (
+CREATE TABLE IF NOT EXISTS INSTRUMENTS (
     ID UUID PRIMARY KEY,
     SYMBOL VARCHAR(20) NOT NULL UNIQUE,
     NAME VARCHAR(100) NOT NULL,
@@ -16,5 +23,166 @@ CREATE TABLE INSTRUMENTS (
     EXCHANGE VARCHAR(20) NOT NULL,
     LAST_PRICE NUMERIC(18, 6) DEFAULT 0 NOT NULL,
     CREATED_AT TIMESTAMP DEFAULT NOW() NOT NULL,
-    UPDATED_AT TIMESTAMP DEFAULT NOW() NOT NULL
-);
\ No newline at end of file
+    UPDATED_AT TIMESTAMP DEFAULT NOW() NOT NULL,
+    DELETED_AT TIMESTAMP,
+    DELETED_BY UUID,
+    VERSION BIGINT DEFAULT 1 NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS REFRESH_TOKENS (
+    ID UUID PRIMARY KEY,
+    USER_ID UUID NOT NULL REFERENCES USERS (USER_ID) ON DELETE CASCADE,
+    TOKEN_HASH TEXT NOT NULL UNIQUE,
+    EXPIRES_AT TIMESTAMP NOT NULL,
+    CREATED_AT TIMESTAMP DEFAULT NOW() NOT NULL,
+    REVOKED_AT TIMESTAMP,
+    REVOKED_REASON VARCHAR(20)
+);
+
+CREATE INDEX IF NOT EXISTS IDX_REFRESH_TOKENS_USER_ID ON REFRESH_TOKENS (USER_ID);
+
+CREATE TABLE IF NOT EXISTS ROLES (
+    NAME VARCHAR(50) PRIMARY KEY,
+    DESCRIPTION TEXT DEFAULT '' NOT NULL,
+    REQUIRE_MFA BOOLEAN DEFAULT FALSE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS PERMISSIONS (
+    NAME VARCHAR(100) PRIMARY KEY,
+    DESCRIPTION TEXT DEFAULT '' NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS ROLE_PERMISSIONS (
+    ROLE_NAME VARCHAR(50) NOT NULL REFERENCES ROLES (NAME) ON DELETE CASCADE,
+    PERMISSION_NAME VARCHAR(100) NOT NULL REFERENCES PERMISSIONS (NAME) ON DELETE CASCADE,
+    PRIMARY KEY (ROLE_NAME, PERMISSION_NAME)
+);
+
+CREATE TABLE IF NOT EXISTS USER_ROLES (
+    USER_ID UUID NOT NULL REFERENCES USERS (USER_ID) ON DELETE CASCADE,
+    ROLE_NAME VARCHAR(50) NOT NULL REFERENCES ROLES (NAME) ON DELETE CASCADE,
+    GRANTED_AT TIMESTAMP DEFAULT NOW() NOT NULL,
+    PRIMARY KEY (USER_ID, ROLE_NAME)
+);
+
+CREATE TABLE IF NOT EXISTS API_KEYS (
+    ID UUID PRIMARY KEY,
+    USER_ID UUID NOT NULL REFERENCES USERS (USER_ID) ON DELETE CASCADE,
+    NAME VARCHAR(100) NOT NULL,
+    PREFIX VARCHAR(16) NOT NULL UNIQUE,
+    KEY_HASH TEXT NOT NULL,
+    SCOPES TEXT NOT NULL,
+    EXPIRES_AT TIMESTAMP,
+    LAST_USED_AT TIMESTAMP,
+    CREATED_AT TIMESTAMP DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS IDX_API_KEYS_USER_ID ON API_KEYS (USER_ID);
+
+CREATE TABLE IF NOT EXISTS MFA_RECOVERY_CODES (
+    ID UUID PRIMARY KEY,
+    USER_ID UUID NOT NULL REFERENCES USERS (USER_ID) ON DELETE CASCADE,
+    CODE_HASH TEXT NOT NULL,
+    USED_AT TIMESTAMP,
+    CREATED_AT TIMESTAMP DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS IDX_MFA_RECOVERY_CODES_USER_ID ON MFA_RECOVERY_CODES (USER_ID);
+
+CREATE TABLE IF NOT EXISTS MFA_FAILED_ATTEMPTS (
+    ID UUID PRIMARY KEY,
+    USER_ID UUID NOT NULL REFERENCES USERS (USER_ID) ON DELETE CASCADE,
+    CHALLENGE_ID UUID,
+    FAILED_AT TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS IDX_MFA_FAILED_ATTEMPTS_USER_ID ON MFA_FAILED_ATTEMPTS (USER_ID, FAILED_AT);
+CREATE INDEX IF NOT EXISTS IDX_MFA_FAILED_ATTEMPTS_CHALLENGE_ID ON MFA_FAILED_ATTEMPTS (CHALLENGE_ID);
+
+CREATE TABLE IF NOT EXISTS MFA_CHALLENGES (
+    ID UUID PRIMARY KEY,
+    USER_ID UUID NOT NULL REFERENCES USERS (USER_ID) ON DELETE CASCADE,
+    ATTEMPTS INTEGER NOT NULL,
+    CONSUMED_AT TIMESTAMP,
+    EXPIRES_AT TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS IDX_MFA_CHALLENGES_USER_ID ON MFA_CHALLENGES (USER_ID, EXPIRES_AT);
+
+CREATE TABLE IF NOT EXISTS ACCOUNT_TOKENS (
+    ID UUID PRIMARY KEY,
+    USER_ID UUID NOT NULL REFERENCES USERS (USER_ID) ON DELETE CASCADE,
+    PURPOSE VARCHAR(32) NOT NULL,
+    TOKEN_HASH TEXT NOT NULL UNIQUE,
+    EXPIRES_AT TIMESTAMP NOT NULL,
+    USED_AT TIMESTAMP,
+    CREATED_AT TIMESTAMP DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS IDX_ACCOUNT_TOKENS_USER_ID ON ACCOUNT_TOKENS (USER_ID);
+
+CREATE TABLE IF NOT EXISTS USER_STATUS_TRANSITIONS (
+    ID UUID PRIMARY KEY,
+    USER_ID UUID NOT NULL REFERENCES USERS (USER_ID) ON DELETE CASCADE,
+    FROM_STATUS TEXT NOT NULL,
+    TO_STATUS TEXT NOT NULL,
+    REASON TEXT DEFAULT '' NOT NULL,
+    ACTOR_ID UUID,
+    CREATED_AT TIMESTAMP DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS IDX_USER_STATUS_TRANSITIONS_USER_ID ON USER_STATUS_TRANSITIONS (USER_ID);
+
+CREATE INDEX IF NOT EXISTS IDX_USERS_DELETED_AT ON USERS (DELETED_AT) WHERE DELETED_AT IS NOT NULL;
+CREATE INDEX IF NOT EXISTS IDX_INSTRUMENTS_DELETED_AT ON INSTRUMENTS (DELETED_AT) WHERE DELETED_AT IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS AUDIT_LOG (
+    ID UUID PRIMARY KEY,
+    SEQ BIGSERIAL NOT NULL UNIQUE,
+    ACTOR_ID UUID,
+    REQUEST_ID TEXT DEFAULT '' NOT NULL,
+    ACTION VARCHAR(20) NOT NULL,
+    ENTITY_TYPE VARCHAR(50) NOT NULL,
+    ENTITY_ID UUID NOT NULL,
+    CHANGES TEXT NOT NULL,
+    PREV_HASH TEXT NOT NULL,
+    HASH TEXT NOT NULL,
+    CREATED_AT TIMESTAMP DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS IDX_AUDIT_LOG_ENTITY ON AUDIT_LOG (ENTITY_TYPE, ENTITY_ID);
+CREATE INDEX IF NOT EXISTS IDX_AUDIT_LOG_ACTOR_ID ON AUDIT_LOG (ACTOR_ID);
+CREATE INDEX IF NOT EXISTS IDX_AUDIT_LOG_CREATED_AT ON AUDIT_LOG (CREATED_AT);
+
+CREATE TABLE IF NOT EXISTS IDEMPOTENCY_KEYS (
+    SCOPE TEXT NOT NULL,
+    KEY TEXT NOT NULL,
+    FINGERPRINT TEXT NOT NULL,
+    STATUS_CODE INTEGER NOT NULL,
+    HEADERS TEXT NOT NULL,
+    BODY BYTEA NOT NULL,
+    CREATED_AT TIMESTAMP DEFAULT NOW() NOT NULL,
+    EXPIRES_AT TIMESTAMP NOT NULL,
+    CLAIM_ID UUID DEFAULT gen_random_uuid() NOT NULL,
+    PRIMARY KEY (SCOPE, KEY)
+);
+
+CREATE INDEX IF NOT EXISTS IDX_IDEMPOTENCY_KEYS_EXPIRES_AT ON IDEMPOTENCY_KEYS (EXPIRES_AT);
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS IDX_USERS_STATUS ON USERS (STATUS);
+CREATE INDEX IF NOT EXISTS IDX_USERS_AGE ON USERS (AGE);
+CREATE INDEX IF NOT EXISTS IDX_USERS_EMAIL_DOMAIN ON USERS (LOWER(SPLIT_PART(EMAIL, '@', 2)));
+CREATE INDEX IF NOT EXISTS IDX_USERS_FIRST_NAME_PREFIX ON USERS (LOWER(FIRST_NAME) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS IDX_USERS_LAST_NAME_PREFIX ON USERS (LOWER(LAST_NAME) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS IDX_USERS_NAME_ORDER ON USERS (LAST_NAME, FIRST_NAME, USER_ID);
+CREATE INDEX IF NOT EXISTS IDX_USERS_FIRST_NAME_TRGM ON USERS USING GIN (FIRST_NAME gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS IDX_USERS_LAST_NAME_TRGM ON USERS USING GIN (LAST_NAME gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS IDX_USERS_EMAIL_TRGM ON USERS USING GIN (EMAIL gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS IDX_INSTRUMENTS_EXCHANGE_TYPE ON INSTRUMENTS (EXCHANGE, INSTRUMENT_TYPE);
+CREATE INDEX IF NOT EXISTS IDX_INSTRUMENTS_TYPE ON INSTRUMENTS (INSTRUMENT_TYPE);
+CREATE INDEX IF NOT EXISTS IDX_INSTRUMENTS_SYMBOL_PREFIX ON INSTRUMENTS (SYMBOL text_pattern_ops);
+CREATE INDEX IF NOT EXISTS IDX_INSTRUMENTS_LAST_PRICE ON INSTRUMENTS (LAST_PRICE);
+CREATE INDEX IF NOT EXISTS IDX_INSTRUMENTS_UPDATED_AT ON INSTRUMENTS (UPDATED_AT);
\ No newline at end of file
@@ -5,11 +5,60 @@
 package sqlc
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type AccountToken struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Purpose   string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
+	CreatedAt time.Time
+}
+
+type ApiKey struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Name       string
+	Prefix     string
+	KeyHash    string
+	Scopes     string
+	ExpiresAt  sql.NullTime
+	LastUsedAt sql.NullTime
+	CreatedAt  time.Time
+}
+
+type AuditLog struct {
+	ID         uuid.UUID
+	Seq        int64
+	ActorID    uuid.NullUUID
+	RequestID  string
+	Action     string
+	EntityType string
+	EntityID   uuid.UUID
+	Changes    string
+	PrevHash   string
+	Hash       string
+	CreatedAt  time.Time
+}
+
+type IdempotencyKey struct {
+	Scope       string
+	Key         string
+	Fingerprint string
+	StatusCode  int32
+	Headers     string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	ClaimID     uuid.UUID
+}
+
 type Instrument struct {
 	ID             uuid.UUID
 	Symbol         string
@@ -19,14 +68,89 @@ type Instrument struct {
 	LastPrice      string
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
+	DeletedAt      sql.NullTime
+	DeletedBy      uuid.NullUUID
+	Version        int64
+}
+
+type MfaChallenge struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Attempts   int32
+	ConsumedAt sql.NullTime
+	ExpiresAt  time.Time
+}
+
+type MfaFailedAttempt struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	ChallengeID uuid.NullUUID
+	FailedAt    time.Time
+}
+
+type MfaRecoveryCode struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	CodeHash  string
+	UsedAt    sql.NullTime
+	CreatedAt time.Time
+}
+
+type Permission struct {
+	Name        string
+	Description string
+}
+
+type RefreshToken struct {
+	ID            uuid.UUID
+	UserID        uuid.UUID
+	TokenHash     string
+	ExpiresAt     time.Time
+	CreatedAt     time.Time
+	RevokedAt     sql.NullTime
+	RevokedReason sql.NullString
+}
+
+type Role struct {
+	Name        string
+	Description string
+	RequireMfa  bool
+}
+
+type RolePermission struct {
+	RoleName       string
+	PermissionName string
 }
 
 type User struct {
+	UserID       uuid.UUID
+	FirstName    string
+	LastName     string
+	Email        string
+	Phone        string
+	Age          int16
+	Status       string
+	PasswordHash sql.NullString
+	MfaSecret    sql.NullString
+	MfaEnabled   bool
+	MfaLastStep  sql.NullInt64
+	DeletedAt    sql.NullTime
+	DeletedBy    uuid.NullUUID
+	Version      int64
+}
+
+type UserRole struct {
 	UserID    uuid.UUID
-	FirstName string
-	LastName  string
-	Email     string
-	Phone     string
-	Age       int16
-	Status    string
+	RoleName  string
+	GrantedAt time.Time
+}
+
+type UserStatusTransition struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	FromStatus string
+	ToStatus   string
+	Reason     string
+	ActorID    uuid.NullUUID
+	CreatedAt  time.Time
 }
@@ -8,24 +8,58 @@ package sqlc
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+const countUsersByStatus = `-- name: CountUsersByStatus :many
+SELECT STATUS, COUNT(*) AS COUNT FROM USERS WHERE DELETED_AT IS NULL GROUP BY STATUS
+`
+
+type CountUsersByStatusRow struct {
+	Status string
+	Count  int64
+}
+
+func (q *Queries) CountUsersByStatus(ctx context.Context) ([]CountUsersByStatusRow, error) {
+	rows, err := q.db.QueryContext(ctx, countUsersByStatus)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountUsersByStatusRow
+	for rows.Next() {
+		var i CountUsersByStatusRow
+		if err := rows.Scan(&i.Status, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createUser = `-- name: CreateUser :one
-INSERT INTO USERS (USER_ID, FIRST_NAME, LAST_NAME, EMAIL, PHONE, AGE, STATUS)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING user_id, first_name, last_name, email, phone, age, status
+INSERT INTO USERS (USER_ID, FIRST_NAME, LAST_NAME, EMAIL, PHONE, AGE, STATUS, PASSWORD_HASH)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING user_id, first_name, last_name, email, phone, age, status, password_hash, mfa_secret, mfa_enabled, mfa_last_step, deleted_at, deleted_by, version
 `
 
 type CreateUserParams struct {
-	UserID    uuid.UUID
-	FirstName string
-	LastName  string
-	Email     string
-	Phone     string
-	Age       int16
-	Status    string
+	UserID       uuid.UUID
+	FirstName    string
+	LastName     string
+	Email        string
+	Phone        string
+	Age          int16
+	Status       string
+	PasswordHash sql.NullString
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -37,6 +71,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.Phone,
 		arg.Age,
 		arg.Status,
+		arg.PasswordHash,
 	)
 	var i User
 	err := row.Scan(
@@ -47,25 +82,56 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Phone,
 		&i.Age,
 		&i.Status,
+		&i.PasswordHash,
+		&i.MfaSecret,
+		&i.MfaEnabled,
+		&i.MfaLastStep,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Version,
 	)
 	return i, err
 }
 
-const deleteUserByID = `-- name: DeleteUserByID :exec
-DELETE FROM USERS WHERE USER_ID = $1
+const findUserByEmail = `-- name: FindUserByEmail :one
+SELECT user_id, first_name, last_name, email, phone, age, status, password_hash, mfa_secret, mfa_enabled, mfa_last_step, deleted_at, deleted_by, version FROM USERS WHERE EMAIL = $1 AND DELETED_AT IS NULL LIMIT 1
 `
 
-func (q *Queries) DeleteUserByID(ctx context.Context, userID uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, deleteUserByID, userID)
-	return err
+func (q *Queries) FindUserByEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRowContext(ctx, findUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.UserID,
+		&i.FirstName,
+		&i.LastName,
+		&i.Email,
+		&i.Phone,
+		&i.Age,
+		&i.Status,
+		&i.PasswordHash,
+		&i.MfaSecret,
+		&i.MfaEnabled,
+		&i.MfaLastStep,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Version,
+	)
+	return i, err
 }
 
 const findUserById = `-- name: FindUserById :one
-SELECT user_id, first_name, last_name, email, phone, age, status FROM USERS WHERE USER_ID = $1 LIMIT 1
+SELECT user_id, first_name, last_name, email, phone, age, status, password_hash, mfa_secret, mfa_enabled, mfa_last_step, deleted_at, deleted_by, version FROM USERS
+WHERE USER_ID = $1 AND ($2::bool OR DELETED_AT IS NULL)
+LIMIT 1
 `
 
-func (q *Queries) FindUserById(ctx context.Context, userID uuid.UUID) (User, error) {
-	row := q.db.QueryRowContext(ctx, findUserById, userID)
+type FindUserByIdParams struct {
+	UserID         uuid.UUID
+	IncludeDeleted bool
+}
+
+func (q *Queries) FindUserById(ctx context.Context, arg FindUserByIdParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, findUserById, arg.UserID, arg.IncludeDeleted)
 	var i User
 	err := row.Scan(
 		&i.UserID,
@@ -75,40 +141,35 @@ func (q *Queries) FindUserById(ctx context.Context, userID uuid.UUID) (User, err
 		&i.Phone,
 		&i.Age,
 		&i.Status,
+		&i.PasswordHash,
+		&i.MfaSecret,
+		&i.MfaEnabled,
+		&i.MfaLastStep,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Version,
 	)
 	return i, err
 }
 
-const listAllUsersPaged = `-- name: ListAllUsersPaged :many
-SELECT user_id, first_name, last_name, email, phone, age, status FROM USERS LIMIT $1 OFFSET $2
+const purgeDeletedUsers = `-- name: PurgeDeletedUsers :many
+DELETE FROM USERS WHERE DELETED_AT IS NOT NULL AND DELETED_AT < $1::timestamp
+RETURNING USER_ID
 `
 
-type ListAllUsersPagedParams struct {
-	Limit  int32
-	Offset int32
-}
-
-func (q *Queries) ListAllUsersPaged(ctx context.Context, arg ListAllUsersPagedParams) ([]User, error) {
-	rows, err := q.db.QueryContext(ctx, listAllUsersPaged, arg.Limit, arg.Offset)
+func (q *Queries) PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, purgeDeletedUsers, deletedBefore)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []User
+	var items []uuid.UUID
 	for rows.Next() {
-		var i User
-		if err := rows.Scan(
-			&i.UserID,
-			&i.FirstName,
-			&i.LastName,
-			&i.Email,
-			&i.Phone,
-			&i.Age,
-			&i.Status,
-		); err != nil {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
 			return nil, err
 		}
-		items = append(items, i)
+		items = append(items, user_id)
 	}
 	if err := rows.Close(); err != nil {
 		return nil, err
@@ -119,6 +180,53 @@ func (q *Queries) ListAllUsersPaged(ctx context.Context, arg ListAllUsersPagedPa
 	return items, nil
 }
 
+const restoreUser = `-- name: RestoreUser :execrows
+UPDATE USERS
+SET DELETED_AT = NULL, DELETED_BY = NULL, STATUS = $1, VERSION = VERSION + 1
+WHERE USER_ID = $2 AND DELETED_AT IS NOT NULL
+`
+
+type RestoreUserParams struct {
+	Status string
+	UserID uuid.UUID
+}
+
+func (q *Queries) RestoreUser(ctx context.Context, arg RestoreUserParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, restoreUser, arg.Status, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const softDeleteUser = `-- name: SoftDeleteUser :execrows
+UPDATE USERS
+SET DELETED_AT = $1::timestamp, DELETED_BY = $2, STATUS = $3, VERSION = VERSION + 1
+WHERE USER_ID = $4 AND VERSION = $5 AND DELETED_AT IS NULL
+`
+
+type SoftDeleteUserParams struct {
+	DeletedAt time.Time
+	DeletedBy uuid.NullUUID
+	Status    string
+	UserID    uuid.UUID
+	Version   int64
+}
+
+func (q *Queries) SoftDeleteUser(ctx context.Context, arg SoftDeleteUserParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, softDeleteUser,
+		arg.DeletedAt,
+		arg.DeletedBy,
+		arg.Status,
+		arg.UserID,
+		arg.Version,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
 SET
@@ -127,9 +235,10 @@ SET
     EMAIL      = COALESCE($3, EMAIL),
     PHONE      = COALESCE($4, PHONE),
     AGE        = COALESCE($5, AGE),
-    STATUS     = COALESCE($6, STATUS)
-WHERE user_id = $7
-RETURNING user_id, first_name, last_name, email, phone, age, status
+    STATUS     = COALESCE($6, STATUS),
+    VERSION    = VERSION + 1
+WHERE user_id = $7 AND VERSION = $8
+RETURNING user_id, first_name, last_name, email, phone, age, status, password_hash, mfa_secret, mfa_enabled, mfa_last_step, deleted_at, deleted_by, version
 `
 
 type UpdateUserParams struct {
@@ -140,6 +249,7 @@ type UpdateUserParams struct {
 	Age       sql.NullInt16
 	Status    sql.NullString
 	UserID    uuid.UUID
+	Version   int64
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
@@ -151,6 +261,7 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		arg.Age,
 		arg.Status,
 		arg.UserID,
+		arg.Version,
 	)
 	var i User
 	err := row.Scan(
@@ -161,6 +272,49 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.Phone,
 		&i.Age,
 		&i.Status,
+		&i.PasswordHash,
+		&i.MfaSecret,
+		&i.MfaEnabled,
+		&i.MfaLastStep,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Version,
 	)
 	return i, err
 }
+
+const updateUserPassword = `-- name: UpdateUserPassword :exec
+UPDATE USERS
+SET PASSWORD_HASH = $1
+WHERE USER_ID = $2
+`
+
+type UpdateUserPasswordParams struct {
+	PasswordHash sql.NullString
+	UserID       uuid.UUID
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserPassword, arg.PasswordHash, arg.UserID)
+	return err
+}
+
+const updateUserStatus = `-- name: UpdateUserStatus :execrows
+UPDATE USERS
+SET STATUS = $1, VERSION = VERSION + 1
+WHERE USER_ID = $2 AND STATUS = $3
+`
+
+type UpdateUserStatusParams struct {
+	Status     string
+	UserID     uuid.UUID
+	FromStatus string
+}
+
+func (q *Queries) UpdateUserStatus(ctx context.Context, arg UpdateUserStatusParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateUserStatus, arg.Status, arg.UserID, arg.FromStatus)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,36 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"user-management/internal/db/sqlc"
+)
+
+// InTx runs fn in a new transaction. The transaction is committed when fn
+// returns nil and rolled back otherwise.
+func InTx(ctx context.Context, conn *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// WithTx runs fn with queries bound to a new transaction, see InTx.
+func WithTx(ctx context.Context, conn *sql.DB, fn func(q *sqlc.Queries) error) error {
+	return InTx(ctx, conn, func(tx *sql.Tx) error {
+		return fn(TxQueries(tx))
+	})
+}
+
+// TxQueries returns queries bound to tx. Unlike sqlc.Queries.WithTx, the
+// statements are observed like those outside of transactions.
+func TxQueries(tx *sql.Tx) *sqlc.Queries {
+	return sqlc.New(Observe(tx))
+}
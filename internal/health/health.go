@@ -0,0 +1,153 @@
+// Package health serves the liveness and readiness probes. Liveness only
+// reports that the process serves requests, readiness runs the registered
+// checks of the dependencies the service needs to handle traffic.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrDraining fails readiness once the server started shutting down.
+var ErrDraining = errors.New("server is shutting down")
+
+// Check reports whether a dependency is usable, it must return before ctx
+// is done.
+type Check func(ctx context.Context) error
+
+// Status is the outcome of a probe, or of a single check.
+type Status string
+
+const (
+	StatusOK          Status = "ok"
+	StatusUnavailable Status = "unavailable"
+)
+
+// CheckResult is the outcome of a single readiness check.
+type CheckResult struct {
+	Status Status `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report is the body of probe responses.
+type Report struct {
+	Status Status                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker runs the readiness checks. Checks run concurrently, each limited
+// to the timeout.
+type Checker struct {
+	timeout  time.Duration
+	draining atomic.Bool
+
+	mu     sync.RWMutex
+	checks []namedCheck
+}
+
+// NewChecker returns a checker without checks, which is ready until Drain
+// is called.
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Register adds a readiness check. A check registered again under the same
+// name replaces the previous one.
+func (c *Checker) Register(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, nc := range c.checks {
+		if nc.name == name {
+			c.checks[i].check = check
+			return
+		}
+	}
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// Drain fails readiness from now on, so load balancers stop routing new
+// requests to the instance before it shuts down.
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Ready runs the checks and reports the result of each.
+func (c *Checker) Ready(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := append([]namedCheck(nil), c.checks...)
+	c.mu.RUnlock()
+
+	errs := make([]error, len(checks))
+	var wg sync.WaitGroup
+	for i, nc := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+			errs[i] = nc.check(ctx)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checks)+1)}
+	if c.draining.Load() {
+		errs = append(errs, ErrDraining)
+		checks = append(checks, namedCheck{name: "shutdown"})
+	}
+	for i, nc := range checks {
+		result := CheckResult{Status: StatusOK}
+		if errs[i] != nil {
+			result = CheckResult{Status: StatusUnavailable, Error: errs[i].Error()}
+			report.Status = StatusUnavailable
+		}
+		report.Checks[nc.name] = result
+	}
+	return report
+}
+
+// Live godoc
+// @Summary Liveness probe
+// @Description Succeeds while the process serves requests, without checking dependencies
+// @Tags health
+// @Produce  json
+// @Success 200 {object} health.Report
+// @Router /healthz [get]
+func (c *Checker) Live(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, Report{Status: StatusOK})
+}
+
+// Readiness godoc
+// @Summary Readiness probe
+// @Description Succeeds when the database is reachable, the schema is migrated and the server is not shutting down
+// @Tags health
+// @Produce  json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func (c *Checker) Readiness(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, c.Ready(r.Context()))
+}
+
+func writeReport(w http.ResponseWriter, report Report) {
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
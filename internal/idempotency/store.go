@@ -0,0 +1,132 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+	"user-management/internal/db/sqlc"
+	"user-management/internal/middleware"
+
+	"github.com/google/uuid"
+)
+
+// Store keeps idempotent responses in the IDEMPOTENCY_KEYS table. Claiming a
+// key inserts its row, so concurrent requests with the same key are serialized
+// on the row lock of the primary key and only one of them runs. Each claim
+// gets an id, so only the request holding it can complete or release the key.
+type Store struct {
+	queries      *sqlc.Queries
+	ttl          time.Duration
+	claimTimeout time.Duration
+}
+
+// NewStore creates a store keeping responses for ttl. A key stays claimed for
+// claimTimeout by a request that never completes, for example because the
+// server stopped while handling it.
+func NewStore(q *sqlc.Queries, ttl time.Duration, claimTimeout time.Duration) *Store {
+	return &Store{queries: q, ttl: ttl, claimTimeout: claimTimeout}
+}
+
+func (s *Store) Claim(ctx context.Context, scope string, key string, fingerprint string) (string, *middleware.IdempotentResponse, error) {
+	now := time.Now().UTC()
+	claim := uuid.New()
+
+	// A claim can race with the expiry of the row it conflicted with, in
+	// which case it is tried once more.
+	for range 2 {
+		claimed, err := s.queries.ClaimIdempotencyKey(ctx, sqlc.ClaimIdempotencyKeyParams{
+			Scope:       scope,
+			Key:         key,
+			Fingerprint: fingerprint,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(s.claimTimeout),
+			ClaimID:     claim,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		if claimed > 0 {
+			return claim.String(), nil, nil
+		}
+
+		stored, err := s.queries.FindIdempotencyKey(ctx, sqlc.FindIdempotencyKeyParams{Scope: scope, Key: key})
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		resp, err := fromSQLC(stored)
+		return "", resp, err
+	}
+
+	return "", nil, errors.New("idempotency key could not be claimed")
+}
+
+func (s *Store) Complete(ctx context.Context, scope string, key string, claim string, resp middleware.IdempotentResponse) error {
+	claimID, err := uuid.Parse(claim)
+	if err != nil {
+		return err
+	}
+	headers, err := json.Marshal(resp.Header)
+	if err != nil {
+		return err
+	}
+
+	completed, err := s.queries.CompleteIdempotencyKey(ctx, sqlc.CompleteIdempotencyKeyParams{
+		StatusCode: int32(resp.StatusCode),
+		Headers:    string(headers),
+		Body:       resp.Body,
+		ExpiresAt:  time.Now().UTC().Add(s.ttl),
+		Scope:      scope,
+		Key:        key,
+		ClaimID:    claimID,
+	})
+	return claimHeld(completed, err)
+}
+
+func (s *Store) Release(ctx context.Context, scope string, key string, claim string) error {
+	claimID, err := uuid.Parse(claim)
+	if err != nil {
+		return err
+	}
+
+	released, err := s.queries.ReleaseIdempotencyKey(ctx, sqlc.ReleaseIdempotencyKeyParams{Scope: scope, Key: key, ClaimID: claimID})
+	return claimHeld(released, err)
+}
+
+// claimHeld returns ErrIdempotencyClaimLost when a statement limited to a
+// claim changed no row.
+func claimHeld(rows int64, err error) error {
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return middleware.ErrIdempotencyClaimLost
+	}
+	return nil
+}
+
+// PurgeExpired deletes the keys that expired before the given time.
+func (s *Store) PurgeExpired(ctx context.Context, before time.Time) (int64, error) {
+	return s.queries.DeleteExpiredIdempotencyKeys(ctx, before)
+}
+
+func fromSQLC(k sqlc.IdempotencyKey) (*middleware.IdempotentResponse, error) {
+	resp := &middleware.IdempotentResponse{
+		Fingerprint: k.Fingerprint,
+		StatusCode:  int(k.StatusCode),
+		Header:      http.Header{},
+		Body:        k.Body,
+	}
+
+	if k.Headers != "" {
+		if err := json.Unmarshal([]byte(k.Headers), &resp.Header); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
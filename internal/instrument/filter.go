@@ -0,0 +1,127 @@
+package instrument
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"user-management/internal/db/query"
+	"user-management/internal/db/sqlc"
+)
+
+// sortFields are the fields instruments can be sorted by.
+var sortFields = map[string]query.SortField{
+	"symbol":     {Column: "SYMBOL", Type: "text"},
+	"name":       {Column: "NAME", Type: "text"},
+	"type":       {Column: "INSTRUMENT_TYPE", Type: "text"},
+	"exchange":   {Column: "EXCHANGE", Type: "text"},
+	"last_price": {Column: "LAST_PRICE", Type: "numeric"},
+	"created_At": {Column: "CREATED_AT", Type: "timestamp"},
+	"updated_At": {Column: "UPDATED_AT", Type: "timestamp"},
+}
+
+var defaultSort = query.Sort{{Name: "symbol", Column: "SYMBOL", Type: "text"}}
+
+// tiebreaker makes the order of instruments total, so pages are stable.
+var tiebreaker = query.SortField{Name: "id", Column: "ID", Type: "uuid"}
+
+// Filter narrows and orders the instrument list. Empty fields match every
+// instrument, the price range is inclusive.
+type Filter struct {
+	Exchanges    []string
+	Types        []string
+	SymbolPrefix string
+	MinPrice     *float64
+	MaxPrice     *float64
+	UpdatedSince *time.Time
+	Sort         query.Sort
+
+	IncludeDeleted bool
+}
+
+// ParseFilter reads the exchange, type, symbol_prefix, min_price, max_price,
+// updated_since and sort query parameters. exchange and type can be repeated
+// or comma separated, updated_since is RFC 3339.
+func ParseFilter(q url.Values) (Filter, error) {
+	f := Filter{
+		Exchanges:    parseList(q, "exchange"),
+		Types:        parseList(q, "type"),
+		SymbolPrefix: q.Get("symbol_prefix"),
+	}
+
+	var err error
+	if f.MinPrice, err = parsePrice(q, "min_price"); err != nil {
+		return Filter{}, err
+	}
+	if f.MaxPrice, err = parsePrice(q, "max_price"); err != nil {
+		return Filter{}, err
+	}
+	if f.MinPrice != nil && f.MaxPrice != nil && *f.MinPrice > *f.MaxPrice {
+		return Filter{}, query.ErrInvalidFilter.WithMessage("min_price must not be greater than max_price")
+	}
+	if v := q.Get("updated_since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return Filter{}, query.ErrInvalidFilter.WithMessage("invalid updated_since, expected RFC 3339")
+		}
+		t = t.UTC()
+		f.UpdatedSince = &t
+	}
+	if f.Sort, err = query.ParseSort(q.Get("sort"), sortFields, defaultSort); err != nil {
+		return Filter{}, err
+	}
+
+	return f, nil
+}
+
+func parseList(q url.Values, name string) []string {
+	var values []string
+	for _, value := range q[name] {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+func parsePrice(q url.Values, name string) (*float64, error) {
+	v := q.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	price, err := strconv.ParseFloat(v, 64)
+	if err != nil || price < 0 {
+		return nil, query.ErrInvalidFilter.WithMessage(fmt.Sprintf("invalid %s, expected a positive number", name))
+	}
+	return &price, nil
+}
+
+// sortValues returns the values of the instrument for each of the keys,
+// formatted for a cursor.
+func sortValues(i sqlc.Instrument, keys query.Sort) []string {
+	values := make([]string, len(keys))
+	for n, f := range keys {
+		switch f.Name {
+		case "symbol":
+			values[n] = i.Symbol
+		case "name":
+			values[n] = i.Name
+		case "type":
+			values[n] = i.InstrumentType
+		case "exchange":
+			values[n] = i.Exchange
+		case "last_price":
+			values[n] = i.LastPrice
+		case "created_At":
+			values[n] = i.CreatedAt.Format(time.RFC3339Nano)
+		case "updated_At":
+			values[n] = i.UpdatedAt.Format(time.RFC3339Nano)
+		case tiebreaker.Name:
+			values[n] = i.ID.String()
+		}
+	}
+	return values
+}
@@ -1,25 +1,36 @@
 package instrument
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"log/slog"
 	"net/http"
+	"user-management/internal/batch"
 	httputils "user-management/internal/common/httputils"
+	"user-management/internal/db/query"
 	"user-management/internal/middleware"
+	"user-management/internal/rbac"
+	"user-management/internal/transfer"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 )
 
 type Handler struct {
 	service  *Service
 	validate *validator.Validate
+
+	maxBatchOperations int
 }
 
-func NewHandler(service *Service, validate *validator.Validate) *Handler {
+// NewHandler creates the instrument handler. Batches can have at most
+// maxBatchOperations operations.
+func NewHandler(service *Service, validate *validator.Validate, maxBatchOperations int) *Handler {
 	return &Handler{
 		service:  service,
 		validate: validate,
+
+		maxBatchOperations: maxBatchOperations,
 	}
 }
 
@@ -29,9 +40,15 @@ func NewHandler(service *Service, validate *validator.Validate) *Handler {
 // @Tags instruments
 // @Accept  json
 // @Produce  json
+// @Param Idempotency-Key header string false "Key making retries of the request safe, at most 255 characters"
 // @Success 200 {object} Instrument
-// @Failure      400  {object}  httputils.ErrorResponse
-// @Failure      500  {object}  httputils.ErrorResponse
+// @Header 200 {string} Idempotent-Replayed "Set when the response is replayed for a retried Idempotency-Key"
+// @Failure      400  {object}  httputils.Problem
+// @Failure      409  {object}  httputils.Problem
+// @Failure      422  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /instruments [post]
 func (h *Handler) CreateInstrument(w http.ResponseWriter, r *http.Request) {
 
@@ -40,23 +57,21 @@ func (h *Handler) CreateInstrument(w http.ResponseWriter, r *http.Request) {
 	var req Instrument
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		slog.Warn("Invalid request", "error", r)
-		httputils.WriteError(w, http.StatusBadRequest, "Invalid request", r)
+		slog.WarnContext(r.Context(), "Invalid request", "error", err)
+		httputils.WriteProblem(w, r, httputils.ErrInvalidRequest.Wrap(err))
 		return
 	}
 
-	if err := h.validate.Struct(req); err != nil {
-		details := httputils.ConvertValidationErrors(err)
-		slog.Warn("Instrument update failed", "error", "Validation failed")
-		httputils.WriteDetailedError(w, http.StatusBadRequest, "Validation failed", details, r)
+	if err := httputils.Validate(&req, h.validate); err != nil {
+		slog.WarnContext(r.Context(), "Instrument creation failed", "error", err)
+		httputils.WriteProblem(w, r, err)
 		return
 	}
 
 	instrument, err := h.service.CreateInstrument(r.Context(), &req)
 
 	if err != nil {
-		slog.Error("Failed to create instrument", "error", r)
-		httputils.WriteError(w, http.StatusInternalServerError, "Failed to create instrument", r)
+		httputils.WriteProblem(w, r, err)
 		return
 	}
 
@@ -65,6 +80,29 @@ func (h *Handler) CreateInstrument(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(instrument)
 }
 
+// BatchInstruments godoc
+// @Summary Create, update and delete instruments in a batch
+// @Description Apply up to batch.maxOperations operations, deletes require the delete permission. The data of create operations is an instrument, of update operations an instrument update request. Creates are applied first, with a single insert, then the other operations in their order.
+// @Description In transactional mode, the default, all operations are applied or none: invalid operations and the first failing operation reject the batch with a problem listing the fields of the operation, for example operations[2].Symbol.
+// @Description In best_effort mode each operation is applied on its own and the result of every operation reports its status and problem.
+// @Tags instruments
+// @Accept  json
+// @Produce  json
+// @Param Idempotency-Key header string false "Key making retries of the request safe, at most 255 characters"
+// @Param request body batch.Request true "Operations of the batch"
+// @Success 200 {object} batch.Response
+// @Failure      400  {object}  httputils.Problem
+// @Failure      404  {object}  httputils.Problem
+// @Failure      409  {object}  httputils.Problem
+// @Failure      412  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /instruments:batch [post]
+func (h *Handler) BatchInstruments(w http.ResponseWriter, r *http.Request) {
+	batch.Serve(w, r, h.validate, h.maxBatchOperations, rbac.PermInstrumentsDelete, h.service.Batch)
+}
+
 // GetInstrumentById godoc
 // @Summary Get instrument by id
 // @Description Get instrument details by id
@@ -73,22 +111,34 @@ func (h *Handler) CreateInstrument(w http.ResponseWriter, r *http.Request) {
 // @Produce  json
 // @Success 200 {object} Instrument
 // @Param id path string true "Instrument ID"
-// @Failure      400  {object}  httputils.ErrorResponse
-// @Failure      404  {object}  httputils.ErrorResponse
-// @Failure      500  {object}  httputils.ErrorResponse
+// @Param include_deleted query bool false "Also find soft deleted instruments, requires instruments:delete"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Header 200 {string} ETag "Version of the instrument"
+// @Success 304
+// @Failure      400  {object}  httputils.Problem
+// @Failure      403  {object}  httputils.Problem
+// @Failure      404  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /instruments/{id} [get]
 func (h *Handler) GetInstrumentById(w http.ResponseWriter, r *http.Request) {
 
 	instrumentId, uuiderr := httputils.ParseUUIDFromURL(r, "id")
 	if uuiderr != nil {
-		http.Error(w, "Invalid instrument ID format", http.StatusBadRequest)
+		httputils.WriteError(w, http.StatusBadRequest, "Invalid instrument ID format", r)
 		return
 	}
 
-	instruments, err := h.service.GetInstrumentById(r.Context(), instrumentId.String())
+	includeDeleted, _ := r.Context().Value(middleware.IncludeDeletedKey).(bool)
+
+	instruments, err := h.service.GetInstrumentById(r.Context(), instrumentId.String(), includeDeleted)
 	if err != nil {
-		slog.Warn(fmt.Sprintf("Instrument not found with id: %s", instrumentId))
-		httputils.WriteError(w, http.StatusNotFound, "Instrument not found", r)
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	if httputils.NotModified(w, r, httputils.ETag(instruments.Version)) {
 		return
 	}
 
@@ -97,6 +147,45 @@ func (h *Handler) GetInstrumentById(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(instruments)
 }
 
+// GetInstrumentBySymbol godoc
+// @Summary Get instrument by symbol
+// @Description Get instrument details by its unique symbol
+// @Tags instruments
+// @Accept  json
+// @Produce  json
+// @Param symbol path string true "Instrument symbol"
+// @Param include_deleted query bool false "Also find soft deleted instruments, requires instruments:delete"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Success 200 {object} Instrument
+// @Header 200 {string} ETag "Version of the instrument"
+// @Success 304
+// @Failure      403  {object}  httputils.Problem
+// @Failure      404  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /instruments/by-symbol/{symbol} [get]
+func (h *Handler) GetInstrumentBySymbol(w http.ResponseWriter, r *http.Request) {
+
+	symbol := chi.URLParam(r, "symbol")
+	includeDeleted, _ := r.Context().Value(middleware.IncludeDeletedKey).(bool)
+
+	instrument, err := h.service.GetInstrumentBySymbol(r.Context(), symbol, includeDeleted)
+
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	if httputils.NotModified(w, r, httputils.ETag(instrument.Version)) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(instrument)
+}
+
 // GetInstruments godoc
 // @Summary Get all instruments
 // @Description Get all instruments
@@ -105,26 +194,85 @@ func (h *Handler) GetInstrumentById(w http.ResponseWriter, r *http.Request) {
 // @Produce  json
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(10)
+// @Param include_deleted query bool false "Also list soft deleted instruments, requires instruments:delete"
+// @Param exchange query []string false "Only instruments on one of the exchanges" collectionFormat(csv)
+// @Param type query []string false "Only instruments of one of the types" collectionFormat(csv)
+// @Param symbol_prefix query string false "Only instruments whose symbol starts with the prefix"
+// @Param min_price query number false "Minimum last price, inclusive"
+// @Param max_price query number false "Maximum last price, inclusive"
+// @Param updated_since query string false "Only instruments updated at or after the RFC 3339 time"
+// @Param sort query string false "Comma separated fields out of symbol, name, type, exchange, last_price, created_At and updated_At, prefixed with - for descending order" default(symbol)
+// @Param cursor query string false "Cursor of the page to fetch, taken from the Link header of the previous response"
+// @Param count query string false "Report the total number of matching instruments" Enums(exact, estimated)
 // @Success 200 {array} Instrument
-// @Failure      404  {object}  httputils.ErrorResponse
-// @Failure      500  {object}  httputils.ErrorResponse
+// @Header 200 {string} Link "Links to the next and previous page"
+// @Header 200 {integer} X-Total-Count "Number of matching instruments, with count=exact"
+// @Header 200 {integer} X-Total-Count-Estimate "Estimated number of matching instruments, with count=estimated"
+// @Failure      400  {object}  httputils.Problem
+// @Failure      403  {object}  httputils.Problem
+// @Failure      404  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /instruments [get]
 func (h *Handler) GetInstruments(w http.ResponseWriter, r *http.Request) {
+	pageReq := r.Context().Value(middleware.PageRequestKey).(query.PageRequest)
 
-	page := r.Context().Value(middleware.PageKey).(int)
-	limit := r.Context().Value(middleware.LimitKey).(int)
-	offset := (page - 1) * limit
+	filter, err := ParseFilter(r.URL.Query())
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+	filter.IncludeDeleted, _ = r.Context().Value(middleware.IncludeDeletedKey).(bool)
 
-	instruments, err := h.service.ListInstrumentsPaged(r.Context(), limit, offset)
+	result, err := h.service.ListInstrumentsPaged(r.Context(), filter, pageReq)
 	if err != nil {
-		slog.Warn("Failed to fetch instruments")
-		httputils.WriteError(w, http.StatusNotFound, "Failed to fetch instruments", r)
+		httputils.WriteProblem(w, r, err)
 		return
 	}
 
+	httputils.WritePageHeaders(w, r, result.PageInfo)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(instruments)
+	json.NewEncoder(w).Encode(result.Items)
+}
+
+// ExportInstruments godoc
+// @Summary Export instruments
+// @Description Stream every instrument matching the filter, in the format selected by the Accept header. The columns are id, symbol, name, type, exchange, last_price, version and deletedAt
+// @Tags instruments
+// @Produce  json
+// @Produce  text/csv
+// @Produce  application/x-ndjson
+// @Param columns query string false "Comma separated columns to export, all columns when unset"
+// @Param include_deleted query bool false "Also export soft deleted instruments, requires instruments:delete"
+// @Param exchange query []string false "Only instruments on one of the exchanges" collectionFormat(csv)
+// @Param type query []string false "Only instruments of one of the types" collectionFormat(csv)
+// @Param symbol_prefix query string false "Only instruments whose symbol starts with the prefix"
+// @Param min_price query number false "Minimum last price, inclusive"
+// @Param max_price query number false "Maximum last price, inclusive"
+// @Param updated_since query string false "Only instruments updated at or after the RFC 3339 time"
+// @Param sort query string false "Comma separated fields out of symbol, name, type, exchange, last_price, created_At and updated_At, prefixed with - for descending order" default(symbol)
+// @Success 200 {array} Instrument
+// @Header 200 {string} Content-Disposition "Attachment named instruments.json, instruments.csv or instruments.ndjson"
+// @Failure      400  {object}  httputils.Problem
+// @Failure      403  {object}  httputils.Problem
+// @Failure      406  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /instruments/export [get]
+func (h *Handler) ExportInstruments(w http.ResponseWriter, r *http.Request) {
+	filter, err := ParseFilter(r.URL.Query())
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+	filter.IncludeDeleted, _ = r.Context().Value(middleware.IncludeDeletedKey).(bool)
+
+	transfer.ServeExport(w, r, "instruments", ExportColumns, func(ctx context.Context, fn func(Instrument) error) error {
+		return h.service.ExportInstruments(ctx, filter, fn)
+	})
 }
 
 // UpdateInstrumentById godoc
@@ -134,35 +282,43 @@ func (h *Handler) GetInstruments(w http.ResponseWriter, r *http.Request) {
 // @Accept  json
 // @Produce  json
 // @Param id path string true "Instrument ID"
+// @Param If-Match header string false "ETag of the instrument, required when server.requireIfMatch is set"
 // @Success 200 {object} Instrument
-// @Failure      400  {object}  httputils.ErrorResponse
-// @Failure      404  {object}  httputils.ErrorResponse
-// @Failure      500  {object}  httputils.ErrorResponse
+// @Header 200 {string} ETag "Version of the updated instrument"
+// @Failure      400  {object}  httputils.Problem
+// @Failure      404  {object}  httputils.Problem
+// @Failure      409  {object}  httputils.Problem
+// @Failure      412  {object}  httputils.Problem
+// @Failure      428  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /instruments/{id} [patch]
 func (h *Handler) UpdateInstrumentById(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
 	instrumentId, uuiderr := httputils.ParseUUIDFromURL(r, "id")
 	if uuiderr != nil {
-		http.Error(w, "Invalid instrument ID format", http.StatusNotFound)
+		httputils.WriteError(w, http.StatusBadRequest, "Invalid instrument ID format", r)
 		return
 	}
 
 	var req InstrumentUpdateRequest
 	if err := httputils.DecodeAndValidateRequest(r, &req, h.validate); err != nil {
-		slog.Warn("Instrument update failed", "error", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		slog.WarnContext(r.Context(), "Instrument update failed", "error", err)
+		httputils.WriteProblem(w, r, err)
 		return
 	}
 
-	updatedInstrument, err := h.service.UpdateInstrument(r.Context(), instrumentId.String(), &req)
+	version := r.Context().Value(middleware.IfMatchKey).(int64)
+	updatedInstrument, err := h.service.UpdateInstrument(r.Context(), instrumentId.String(), &req, version)
 
 	if err != nil {
-		slog.Warn("Instrument update failed", "error", err)
-		httputils.WriteError(w, http.StatusInternalServerError, "Instrument update failed", r)
+		httputils.WriteProblem(w, r, err)
 		return
 	}
 
+	w.Header().Set("ETag", httputils.ETag(updatedInstrument.Version))
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(updatedInstrument)
@@ -170,25 +326,70 @@ func (h *Handler) UpdateInstrumentById(w http.ResponseWriter, r *http.Request) {
 
 // DeleteInstrument godoc
 // @Summary Delete instrument by id
-// @Description Delete an existing instrument by id
+// @Description Soft delete an existing instrument by id. Deleted instruments can be restored until they are purged
 // @Tags instruments
 // @Accept  json
 // @Produce  json
 // @Param id path string true "Instrument ID"
+// @Param If-Match header string false "ETag of the instrument, required when server.requireIfMatch is set"
 // @Success 204
+// @Failure      404  {object}  httputils.Problem
+// @Failure      412  {object}  httputils.Problem
+// @Failure      428  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /instruments/{id} [delete]
 func (h *Handler) DeleteInstrumentById(w http.ResponseWriter, r *http.Request) {
 
 	instrumentId, uuiderr := httputils.ParseUUIDFromURL(r, "id")
 	if uuiderr != nil {
-		http.Error(w, "Invalid instrument ID format", http.StatusBadRequest)
+		httputils.WriteError(w, http.StatusBadRequest, "Invalid instrument ID format", r)
 		return
 	}
 
-	err := h.service.DeleteInstrumentById(r.Context(), instrumentId.String())
+	version := r.Context().Value(middleware.IfMatchKey).(int64)
+	err := h.service.DeleteInstrumentById(r.Context(), instrumentId.String(), version)
+
 	if err != nil {
-		httputils.WriteError(w, http.StatusNotFound, "Failed to fetch instruments", r)
+		httputils.WriteProblem(w, r, err)
 		return
 	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// RestoreInstrument godoc
+// @Summary Restore instrument by id
+// @Description Restore a soft deleted instrument
+// @Tags instruments
+// @Produce  json
+// @Param id path string true "Instrument ID"
+// @Success 200 {object} Instrument
+// @Header 200 {string} ETag "Version of the restored instrument"
+// @Failure      400  {object}  httputils.Problem
+// @Failure      404  {object}  httputils.Problem
+// @Failure      409  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /instruments/{id}/restore [post]
+func (h *Handler) RestoreInstrument(w http.ResponseWriter, r *http.Request) {
+
+	instrumentId, uuiderr := httputils.ParseUUIDFromURL(r, "id")
+	if uuiderr != nil {
+		httputils.WriteError(w, http.StatusBadRequest, "Invalid instrument ID format", r)
+		return
+	}
+
+	restored, err := h.service.RestoreInstrument(r.Context(), instrumentId)
+
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", httputils.ETag(restored.Version))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(restored)
+}
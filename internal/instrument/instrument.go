@@ -14,11 +14,17 @@ type Instrument struct {
 	Id              uuid.UUID `json:"id"`
 	Symbol          string    `json:"symbol" validate:"required,min=2,max=50"`
 	Name            string    `json:"name" validate:"required,min=2,max=50"`
-	Instrument_Type string    `json:"type" validate:"required,email"`
-	Exchange        string    `json:"exchange" validate:"omitempty,e164"`
+	Instrument_Type string    `json:"type" validate:"required,min=2,max=50"`
+	Exchange        string    `json:"exchange" validate:"omitempty,min=2,max=50"`
 	Last_Price      float64   `json:"last_price" validate:"omitempty,gt=0"`
 	Created_At      time.Time `json:"created_At" validate:"omitempty,userStatus"`
 	Updated_At      time.Time `json:"updated_At" validate:"omitempty,userStatus"`
+
+	// Version is incremented on every change and sent as the ETag.
+	Version int64 `json:"version"`
+
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+	DeletedBy *uuid.UUID `json:"deletedBy,omitempty"`
 }
 
 func NewInstrument(symbol string, name string, instrumentType string, exchange string, lastPrice float64) *Instrument {
@@ -51,6 +57,7 @@ func UpdatableInstrument(id uuid.UUID, instrument *sqlc.Instrument) (*Instrument
 		Last_Price:      lastPrice,
 		Created_At:      instrument.CreatedAt,
 		Updated_At:      time.Now(),
+		Version:         instrument.Version,
 	}, nil
 }
 
@@ -62,7 +69,7 @@ func FromSQLC(i sqlc.Instrument) Instrument {
 		slog.Error("Error parsing string to float64", "error", err)
 	}
 
-	return Instrument{
+	mapped := Instrument{
 		Id:              i.ID,
 		Symbol:          i.Symbol,
 		Name:            i.Name,
@@ -71,7 +78,17 @@ func FromSQLC(i sqlc.Instrument) Instrument {
 		Last_Price:      float64(lastPrice),
 		Created_At:      i.CreatedAt,
 		Updated_At:      i.UpdatedAt,
+		Version:         i.Version,
+	}
+
+	if i.DeletedAt.Valid {
+		mapped.DeletedAt = &i.DeletedAt.Time
 	}
+	if i.DeletedBy.Valid {
+		mapped.DeletedBy = &i.DeletedBy.UUID
+	}
+
+	return mapped
 }
 
 func FromSQLCList(instruments []sqlc.Instrument) []Instrument {
@@ -0,0 +1,31 @@
+package instrument
+
+import (
+	"user-management/internal/transfer"
+)
+
+// ImportFromRecord reads the symbol, name, type, exchange and last_price
+// columns of a record. Other columns, like those of an export, are ignored.
+func ImportFromRecord(rec transfer.Record) (Instrument, error) {
+	f := transfer.NewFields(rec)
+	i := Instrument{
+		Symbol:          f.String("symbol"),
+		Name:            f.String("name"),
+		Instrument_Type: f.String("type"),
+		Exchange:        f.String("exchange"),
+		Last_Price:      f.Float64("last_price"),
+	}
+	return i, f.Err()
+}
+
+// ExportColumns are the columns of exported instruments.
+var ExportColumns = []transfer.Column[Instrument]{
+	{Name: "id", Value: func(i Instrument) any { return i.Id.String() }},
+	{Name: "symbol", Value: func(i Instrument) any { return i.Symbol }},
+	{Name: "name", Value: func(i Instrument) any { return i.Name }},
+	{Name: "type", Value: func(i Instrument) any { return i.Instrument_Type }},
+	{Name: "exchange", Value: func(i Instrument) any { return i.Exchange }},
+	{Name: "last_price", Value: func(i Instrument) any { return i.Last_Price }},
+	{Name: "version", Value: func(i Instrument) any { return i.Version }},
+	{Name: "deletedAt", Value: func(i Instrument) any { return i.DeletedAt }},
+}
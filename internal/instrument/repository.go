@@ -2,19 +2,52 @@ package instrument
 
 import (
 	"context"
+	"database/sql"
 	"log/slog"
+	"time"
+	"user-management/internal/audit"
 	"user-management/internal/common/converters"
+	"user-management/internal/db"
+	"user-management/internal/db/query"
 	"user-management/internal/db/sqlc"
 
 	"github.com/google/uuid"
 )
 
 type Repository struct {
+	db      *sql.DB
+	tx      *sql.Tx
 	queries *sqlc.Queries
 }
 
-func NewRepository(q *sqlc.Queries) *Repository {
-	return &Repository{queries: q}
+func NewRepository(conn *sql.DB, q *sqlc.Queries) *Repository {
+	return &Repository{db: conn, queries: q}
+}
+
+// WithTx runs fn with a repository bound to a new transaction, committing it
+// when fn returns nil. A repository already bound to a transaction runs fn in
+// its transaction, which is committed by the outermost WithTx.
+func (r *Repository) WithTx(ctx context.Context, fn func(tx *Repository) error) error {
+	if r.tx != nil {
+		return fn(r)
+	}
+	return db.InTx(ctx, r.db, func(tx *sql.Tx) error {
+		return fn(&Repository{db: r.db, tx: tx, queries: db.TxQueries(tx)})
+	})
+}
+
+// conn returns the transaction of the repository, if any, or the database.
+func (r *Repository) conn() sqlc.DBTX {
+	if r.tx != nil {
+		return db.Observe(r.tx)
+	}
+	return db.Observe(r.db)
+}
+
+// Audit records the event with the queries of the repository, so it commits
+// together with a change made through the same WithTx repository.
+func (r *Repository) Audit(ctx context.Context, e audit.Event) error {
+	return audit.Write(ctx, r.queries, e)
 }
 
 func (r *Repository) Create(ctx context.Context, instrument *Instrument) (sqlc.Instrument, error) {
@@ -25,6 +58,7 @@ func (r *Repository) Create(ctx context.Context, instrument *Instrument) (sqlc.I
 		InstrumentType: instrument.Instrument_Type,
 		Exchange:       instrument.Exchange,
 		LastPrice:      converters.Float64ToString(instrument.Last_Price),
+		CreatedAt:      instrument.Created_At,
 		UpdatedAt:      instrument.Updated_At,
 		ID:             instrument.Id,
 	}
@@ -32,25 +66,165 @@ func (r *Repository) Create(ctx context.Context, instrument *Instrument) (sqlc.I
 	return r.queries.CreateInstrument(ctx, params)
 }
 
-func (r *Repository) GetAllPaged(ctx context.Context, limit int, offset int) ([]sqlc.Instrument, error) {
+// CreateMany inserts the instruments with a single statement and returns them
+// in the order of instruments.
+func (r *Repository) CreateMany(ctx context.Context, instruments []*Instrument) ([]sqlc.Instrument, error) {
+
+	b := query.Insert("INSTRUMENTS", "ID, SYMBOL, NAME, INSTRUMENT_TYPE, EXCHANGE, LAST_PRICE, CREATED_AT, UPDATED_AT").Returning(instrumentColumns)
+	for _, i := range instruments {
+		b.Values(i.Id, i.Symbol, i.Name, i.Instrument_Type, i.Exchange, converters.Float64ToString(i.Last_Price), i.Created_At, i.Updated_At)
+	}
+
+	stmt, args := b.SQL()
+	saved, err := r.queryInstruments(ctx, stmt, args)
+	if err != nil {
+		return nil, err
+	}
+
+	byId := make(map[uuid.UUID]sqlc.Instrument, len(saved))
+	for _, i := range saved {
+		byId[i.ID] = i
+	}
+	ordered := make([]sqlc.Instrument, len(instruments))
+	for n, i := range instruments {
+		ordered[n] = byId[i.Id]
+	}
+	return ordered, nil
+}
+
+// instrumentColumns are the columns of INSTRUMENTS in the order of sqlc.Instrument.
+const instrumentColumns = "ID, SYMBOL, NAME, INSTRUMENT_TYPE, EXCHANGE, LAST_PRICE, CREATED_AT, UPDATED_AT, DELETED_AT, DELETED_BY, VERSION"
+
+// GetAllPaged returns the instruments matching the filter in the order of
+// keys. With a cursor only the instruments after it are returned, and the
+// order is reversed for a backward cursor.
+func (r *Repository) GetAllPaged(ctx context.Context, f Filter, keys query.Sort, cur *query.Cursor, limit int, offset int) ([]sqlc.Instrument, error) {
+
+	b := filterQuery(f)
+	if cur != nil && cur.Backward {
+		keys = keys.Reverse()
+	}
+	if cur != nil {
+		b.Seek(keys, cur.Values)
+	}
+	b.OrderBy(keys).Page(limit, offset)
+
+	stmt, args := b.SQL()
+	return r.queryInstruments(ctx, stmt, args)
+}
+
+func (r *Repository) queryInstruments(ctx context.Context, stmt string, args []any) ([]sqlc.Instrument, error) {
+	rows, err := r.conn().QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	params := sqlc.ListAllInstrumentPagedParams{
-		Limit:  int32(limit),
-		Offset: int32(offset),
+	var instruments []sqlc.Instrument
+	for rows.Next() {
+		i, err := scanInstrument(rows)
+		if err != nil {
+			return nil, err
+		}
+		instruments = append(instruments, i)
 	}
+	return instruments, rows.Err()
+}
 
-	return r.queries.ListAllInstrumentPaged(ctx, params)
+// streamBatchSize is the number of rows Stream fetches at a time.
+const streamBatchSize = 1000
+
+// Stream calls fn with every instrument matching the filter in the order of
+// keys. The instruments are read from a server side cursor, so any number of
+// instruments can be streamed. An error returned by fn stops the stream.
+func (r *Repository) Stream(ctx context.Context, f Filter, keys query.Sort, fn func(sqlc.Instrument) error) error {
+	stmt, args := filterQuery(f).OrderBy(keys).SQL()
+
+	return r.WithTx(ctx, func(tx *Repository) error {
+		return query.Stream(ctx, tx.tx, stmt, args, streamBatchSize, func(rows *sql.Rows) error {
+			i, err := scanInstrument(rows)
+			if err != nil {
+				return err
+			}
+			return fn(i)
+		})
+	})
+}
+
+func scanInstrument(rows *sql.Rows) (sqlc.Instrument, error) {
+	var i sqlc.Instrument
+	err := rows.Scan(
+		&i.ID,
+		&i.Symbol,
+		&i.Name,
+		&i.InstrumentType,
+		&i.Exchange,
+		&i.LastPrice,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Version,
+	)
+	return i, err
+}
+
+// Count returns the number of instruments matching the filter with the given
+// mode.
+func (r *Repository) Count(ctx context.Context, f Filter, mode query.CountMode) (*int64, error) {
+	return query.Total(ctx, r.conn(), filterQuery(f), mode)
+}
+
+func filterQuery(f Filter) *query.Builder {
+	b := query.Select(instrumentColumns, "INSTRUMENTS")
+	if !f.IncludeDeleted {
+		b.Where("DELETED_AT IS NULL")
+	}
+	if len(f.Exchanges) > 0 {
+		b.Where("EXCHANGE = ANY(?)", f.Exchanges)
+	}
+	if len(f.Types) > 0 {
+		b.Where("INSTRUMENT_TYPE = ANY(?)", f.Types)
+	}
+	if f.SymbolPrefix != "" {
+		b.Where("SYMBOL LIKE ?", query.Prefix(f.SymbolPrefix))
+	}
+	if f.MinPrice != nil {
+		b.Where("LAST_PRICE >= ?", converters.Float64ToString(*f.MinPrice))
+	}
+	if f.MaxPrice != nil {
+		b.Where("LAST_PRICE <= ?", converters.Float64ToString(*f.MaxPrice))
+	}
+	if f.UpdatedSince != nil {
+		b.Where("UPDATED_AT >= ?", *f.UpdatedSince)
+	}
+	return b
 }
 
-func (r *Repository) GetInstrumentById(ctx context.Context, instrumentId string) (sqlc.Instrument, error) {
+func (r *Repository) GetInstrumentById(ctx context.Context, instrumentId string, includeDeleted bool) (sqlc.Instrument, error) {
 
 	parsedUUID, err := uuid.Parse(instrumentId)
 	if err != nil {
-		slog.Error("Invalid UUID from DB", "error", err)
+		slog.ErrorContext(ctx, "Invalid UUID from DB", "error", err)
 		return sqlc.Instrument{}, err
 	}
 
-	return r.queries.FindInstrumentById(ctx, parsedUUID)
+	params := sqlc.FindInstrumentByIdParams{
+		ID:             parsedUUID,
+		IncludeDeleted: includeDeleted,
+	}
+
+	return r.queries.FindInstrumentById(ctx, params)
+}
+
+func (r *Repository) GetInstrumentBySymbol(ctx context.Context, symbol string, includeDeleted bool) (sqlc.Instrument, error) {
+
+	params := sqlc.FindInstrumentBySymbolParams{
+		Symbol:         symbol,
+		IncludeDeleted: includeDeleted,
+	}
+
+	return r.queries.FindInstrumentBySymbol(ctx, params)
 }
 
 func (r *Repository) Update(ctx context.Context, instrument *Instrument) (sqlc.Instrument, error) {
@@ -63,18 +237,35 @@ func (r *Repository) Update(ctx context.Context, instrument *Instrument) (sqlc.I
 		LastPrice:      converters.NullableFloat64(instrument.Last_Price),
 		UpdatedAt:      converters.NullableTime(instrument.Updated_At),
 		ID:             instrument.Id,
+		Version:        instrument.Version,
 	}
 
 	return r.queries.UpdateInstrument(ctx, parms)
 }
 
-func (r *Repository) Delete(ctx context.Context, instrumentId string) error {
+// SoftDelete marks the instrument as deleted and reports whether it was
+// found, not deleted yet and unchanged since version was read.
+func (r *Repository) SoftDelete(ctx context.Context, instrumentId uuid.UUID, version int64, actor uuid.NullUUID) (bool, error) {
 
-	parsedUUID, err := uuid.Parse(instrumentId)
-	if err != nil {
-		slog.Error("Invalid UUID from DB", "error", err)
-		return err
+	params := sqlc.SoftDeleteInstrumentParams{
+		DeletedAt: time.Now().UTC(),
+		DeletedBy: actor,
+		ID:        instrumentId,
+		Version:   version,
 	}
 
-	return r.queries.DeleteInstrumentById(ctx, parsedUUID)
+	rows, err := r.queries.SoftDeleteInstrument(ctx, params)
+	return rows > 0, err
+}
+
+// Restore clears the deletion and reports whether the instrument was deleted.
+func (r *Repository) Restore(ctx context.Context, instrumentId uuid.UUID) (bool, error) {
+	rows, err := r.queries.RestoreInstrument(ctx, instrumentId)
+	return rows > 0, err
+}
+
+// Purge hard deletes instruments soft deleted before the given time and
+// returns their ids.
+func (r *Repository) Purge(ctx context.Context, deletedBefore time.Time) ([]uuid.UUID, error) {
+	return r.queries.PurgeDeletedInstruments(ctx, deletedBefore)
 }
@@ -2,51 +2,225 @@ package instrument
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"time"
+	"user-management/internal/audit"
+	"user-management/internal/batch"
+	"user-management/internal/common/apperror"
 	"user-management/internal/common/converters"
+	"user-management/internal/db/query"
+	"user-management/internal/db/sqlc"
+	"user-management/internal/middleware"
+	"user-management/internal/tracing"
+	"user-management/internal/transfer"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// AuditEntity is the entity type of instruments in the audit log.
+const AuditEntity = "instrument"
+
+// uniqueViolation is the PostgreSQL error code of unique constraint violations.
+const uniqueViolation = "23505"
+
+var (
+	ErrInstrumentNotFound   = apperror.New(apperror.NotFound, "instrument_not_found", "instrument not found")
+	ErrSymbolTaken          = apperror.New(apperror.Conflict, "symbol_taken", "symbol is already in use")
+	ErrInstrumentNotDeleted = apperror.New(apperror.Conflict, "instrument_not_deleted", "instrument is not deleted")
+	ErrVersionMismatch      = apperror.New(apperror.PreconditionFailed, "version_mismatch", "instrument version does not match")
 )
 
 type Service struct {
-	repo *Repository
+	repo    *Repository
+	cursors *query.CursorCodec
 }
 
-func NewService(repo *Repository) *Service {
-	return &Service{repo: repo}
+// NewService creates the service. Pagination cursors are signed with cursors.
+func NewService(repo *Repository, cursors *query.CursorCodec) *Service {
+	return &Service{repo: repo, cursors: cursors}
 }
 
-func (s *Service) CreateInstrument(ctx context.Context, i *Instrument) (Instrument, error) {
+func (s *Service) CreateInstrument(ctx context.Context, i *Instrument) (_ Instrument, err error) {
+	ctx, span := tracing.Start(ctx, "instrument.Service.CreateInstrument")
+	defer tracing.End(span, &err)
+
 	newInstrument := NewInstrument(i.Symbol, i.Name, i.Instrument_Type, i.Exchange, i.Last_Price)
-	savedInstrument, err := s.repo.Create(ctx, newInstrument)
+
+	var savedInstrument sqlc.Instrument
+	err = s.repo.WithTx(ctx, func(tx *Repository) error {
+		var err error
+		savedInstrument, err = tx.Create(ctx, newInstrument)
+		if err != nil {
+			return symbolTaken(err)
+		}
+		return tx.Audit(ctx, auditEvent(audit.ActionCreate, savedInstrument.ID, nil, FromSQLC(savedInstrument)))
+	})
 	if err != nil {
 		return Instrument{}, err
 	}
 	return FromSQLC(savedInstrument), nil
 }
 
-func (s *Service) ListInstrumentsPaged(ctx context.Context, limit int, offset int) ([]Instrument, error) {
-	instruments, err := s.repo.GetAllPaged(ctx, limit, offset)
+// BatchItem is an operation of an instruments batch.
+type BatchItem = batch.Item[Instrument, InstrumentUpdateRequest]
+
+// Batch applies the operations of an instruments batch, see batch.Run. In
+// transactional mode all operations commit together and the first failure
+// rolls them back and is returned.
+func (s *Service) Batch(ctx context.Context, mode batch.Mode, items []BatchItem) (_ []batch.Outcome, err error) {
+	ctx, span := tracing.Start(ctx, "instrument.Service.Batch")
+	defer tracing.End(span, &err)
+
+	if mode == batch.BestEffort {
+		return batch.Run(ctx, items, false, s.createBatch, s.applyBatchItem)
+	}
+
+	var outcomes []batch.Outcome
+	err = s.repo.WithTx(ctx, func(tx *Repository) error {
+		txService := &Service{repo: tx, cursors: s.cursors}
+
+		var err error
+		outcomes, err = batch.Run(ctx, items, true, txService.createBatch, txService.applyBatchItem)
+		return err
+	})
+	return outcomes, err
+}
+
+// createBatch inserts the instruments of the create items with one statement.
+func (s *Service) createBatch(ctx context.Context, items []BatchItem) ([]batch.Outcome, error) {
+	newInstruments := make([]*Instrument, len(items))
+	for n, item := range items {
+		i := item.Create
+		newInstruments[n] = NewInstrument(i.Symbol, i.Name, i.Instrument_Type, i.Exchange, i.Last_Price)
+	}
+
+	var saved []sqlc.Instrument
+	err := s.repo.WithTx(ctx, func(tx *Repository) error {
+		var err error
+		saved, err = tx.CreateMany(ctx, newInstruments)
+		if err != nil {
+			return symbolTaken(err)
+		}
+		for _, i := range saved {
+			if err := tx.Audit(ctx, auditEvent(audit.ActionCreate, i.ID, nil, FromSQLC(i))); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	return FromSQLCList(instruments), nil
+
+	outcomes := make([]batch.Outcome, len(items))
+	for n, item := range items {
+		outcomes[n] = item.Done(saved[n].ID, FromSQLC(saved[n]))
+	}
+	return outcomes, nil
 }
 
-func (s *Service) GetInstrumentById(ctx context.Context, instrumentId string) (Instrument, error) {
-	u, err := s.repo.GetInstrumentById(ctx, instrumentId)
+func (s *Service) applyBatchItem(ctx context.Context, item BatchItem) batch.Outcome {
+	var i Instrument
+	var err error
+
+	switch item.Op {
+	case batch.Create:
+		i, err = s.CreateInstrument(ctx, item.Create)
+	case batch.Update:
+		i, err = s.UpdateInstrument(ctx, item.ID.String(), item.Update, item.Version)
+	case batch.Delete:
+		if err = s.DeleteInstrumentById(ctx, item.ID.String(), item.Version); err == nil {
+			return item.Done(item.ID, nil)
+		}
+	}
+
 	if err != nil {
-		return Instrument{}, err
+		return item.Failed(err)
+	}
+	return item.Done(i.Id, i)
+}
+
+// ListInstrumentsPaged returns the page of instruments matching the filter
+// selected by req, which is invalid with query.ErrInvalidCursor when its
+// cursor is.
+func (s *Service) ListInstrumentsPaged(ctx context.Context, f Filter, req query.PageRequest) (_ query.Page[Instrument], err error) {
+	ctx, span := tracing.Start(ctx, "instrument.Service.ListInstrumentsPaged")
+	defer tracing.End(span, &err)
+
+	keys := f.Sort.With(tiebreaker)
+
+	fetch := func(cur *query.Cursor, limit int, offset int) ([]sqlc.Instrument, error) {
+		return s.repo.GetAllPaged(ctx, f, keys, cur, limit, offset)
+	}
+	values := func(i sqlc.Instrument) []string {
+		return sortValues(i, keys)
+	}
+
+	page, err := query.Paginate(s.cursors, keys, req, fetch, values)
+	if err != nil {
+		return query.Page[Instrument]{}, err
+	}
+
+	result := query.Page[Instrument]{Items: FromSQLCList(page.Items), PageInfo: page.PageInfo}
+	if result.Total, err = s.repo.Count(ctx, f, req.Count); err != nil {
+		return query.Page[Instrument]{}, err
+	}
+	result.Estimated = result.Total != nil && req.Count == query.CountEstimated
+	return result, nil
+}
+
+// ExportInstruments calls fn with every instrument matching the filter, in the order of the
+// filter. Unlike the pages of ListInstrumentsPaged, the instruments are streamed, an error
+// returned by fn stops the stream.
+func (s *Service) ExportInstruments(ctx context.Context, f Filter, fn func(Instrument) error) (err error) {
+	ctx, span := tracing.Start(ctx, "instrument.Service.ExportInstruments")
+	defer tracing.End(span, &err)
+
+	return s.repo.Stream(ctx, f, f.Sort.With(tiebreaker), func(row sqlc.Instrument) error {
+		return fn(FromSQLC(row))
+	})
+}
+
+func (s *Service) GetInstrumentById(ctx context.Context, instrumentId string, includeDeleted bool) (_ Instrument, err error) {
+	ctx, span := tracing.Start(ctx, "instrument.Service.GetInstrumentById")
+	defer tracing.End(span, &err)
+
+	u, err := s.repo.GetInstrumentById(ctx, instrumentId, includeDeleted)
+	if err != nil {
+		return Instrument{}, notFound(err)
 	}
 	return FromSQLC(u), nil
 }
 
-func (s *Service) UpdateInstrument(ctx context.Context, instrumentId string, i *InstrumentUpdateRequest) (Instrument, error) {
+func (s *Service) GetInstrumentBySymbol(ctx context.Context, symbol string, includeDeleted bool) (_ Instrument, err error) {
+	ctx, span := tracing.Start(ctx, "instrument.Service.GetInstrumentBySymbol")
+	defer tracing.End(span, &err)
 
-	existing, err := s.repo.GetInstrumentById(ctx, instrumentId)
+	i, err := s.repo.GetInstrumentBySymbol(ctx, symbol, includeDeleted)
 	if err != nil {
-		return Instrument{}, err
+		return Instrument{}, notFound(err)
+	}
+	return FromSQLC(i), nil
+}
+
+// UpdateInstrument applies the set fields of the request. A non zero
+// expectedVersion must match the version of the instrument, otherwise
+// ErrVersionMismatch is returned.
+func (s *Service) UpdateInstrument(ctx context.Context, instrumentId string, i *InstrumentUpdateRequest, expectedVersion int64) (_ Instrument, err error) {
+	ctx, span := tracing.Start(ctx, "instrument.Service.UpdateInstrument")
+	defer tracing.End(span, &err)
+
+	existing, err := s.repo.GetInstrumentById(ctx, instrumentId, false)
+	if err != nil {
+		return Instrument{}, notFound(err)
 	}
+	if expectedVersion != 0 && expectedVersion != existing.Version {
+		return Instrument{}, ErrVersionMismatch
+	}
+	before := FromSQLC(existing)
 
 	if i.Symbol != "" {
 		existing.Symbol = i.Symbol
@@ -75,17 +249,191 @@ func (s *Service) UpdateInstrument(ctx context.Context, instrumentId string, i *
 		return Instrument{}, err
 	}
 
-	savedInstrument, err := s.repo.Update(ctx, instrumentToBeUpdate)
+	var savedInstrument sqlc.Instrument
+	err = s.repo.WithTx(ctx, func(tx *Repository) error {
+		var err error
+		savedInstrument, err = tx.Update(ctx, instrumentToBeUpdate)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrVersionMismatch
+		}
+		if err != nil {
+			return symbolTaken(err)
+		}
+		return tx.Audit(ctx, auditEvent(audit.ActionUpdate, id, before, FromSQLC(savedInstrument)))
+	})
 	if err != nil {
 		return Instrument{}, err
 	}
 	return FromSQLC(savedInstrument), nil
 }
 
-func (s *Service) DeleteInstrumentById(ctx context.Context, instrumentId string) error {
-	err := s.repo.Delete(ctx, instrumentId)
+// ImportInstrument creates an instrument with the symbol of i, or updates the
+// existing instrument when it differs from i. An empty exchange and a zero
+// price keep the values of the existing instrument. With dryRun nothing is
+// written and the action that would be taken is returned.
+func (s *Service) ImportInstrument(ctx context.Context, i *Instrument, dryRun bool) (_ transfer.Action, err error) {
+	ctx, span := tracing.Start(ctx, "instrument.Service.ImportInstrument")
+	defer tracing.End(span, &err)
+
+	existing, err := s.GetInstrumentBySymbol(ctx, i.Symbol, false)
+	if errors.Is(err, ErrInstrumentNotFound) {
+		if !dryRun {
+			if _, err := s.CreateInstrument(ctx, i); err != nil {
+				return transfer.Rejected, err
+			}
+		}
+		return transfer.Created, nil
+	}
 	if err != nil {
-		return err
+		return transfer.Rejected, err
+	}
+
+	if i.Name == existing.Name && i.Instrument_Type == existing.Instrument_Type &&
+		(i.Exchange == "" || i.Exchange == existing.Exchange) &&
+		(i.Last_Price == 0 || i.Last_Price == existing.Last_Price) {
+		return transfer.Unchanged, nil
+	}
+	if !dryRun {
+		changes := InstrumentUpdateRequest{
+			Symbol:          i.Symbol,
+			Name:            i.Name,
+			Instrument_Type: i.Instrument_Type,
+			Exchange:        i.Exchange,
+			Last_Price:      i.Last_Price,
+		}
+		if _, err := s.UpdateInstrument(ctx, existing.Id.String(), &changes, existing.Version); err != nil {
+			return transfer.Rejected, err
+		}
+	}
+	return transfer.Updated, nil
+}
+
+// DeleteInstrumentById soft deletes an instrument. It can be restored with
+// RestoreInstrument until the deleted instruments are purged. A non zero
+// expectedVersion must match the version of the instrument.
+func (s *Service) DeleteInstrumentById(ctx context.Context, instrumentId string, expectedVersion int64) (err error) {
+	ctx, span := tracing.Start(ctx, "instrument.Service.DeleteInstrumentById")
+	defer tracing.End(span, &err)
+
+	id, err := uuid.Parse(instrumentId)
+	if err != nil {
+		return fmt.Errorf("invalid instrumentId: %w", err)
+	}
+
+	var actor uuid.NullUUID
+	if principal, ok := middleware.PrincipalFrom(ctx); ok {
+		actor = uuid.NullUUID{UUID: principal.UserID, Valid: true}
+	}
+
+	before, err := s.repo.GetInstrumentById(ctx, instrumentId, false)
+	if err != nil {
+		return notFound(err)
+	}
+	if expectedVersion != 0 && expectedVersion != before.Version {
+		return ErrVersionMismatch
+	}
+
+	return s.repo.WithTx(ctx, func(tx *Repository) error {
+		deleted, err := tx.SoftDelete(ctx, id, before.Version, actor)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return ErrVersionMismatch
+		}
+
+		after, err := tx.GetInstrumentById(ctx, instrumentId, true)
+		if err != nil {
+			return err
+		}
+		return tx.Audit(ctx, auditEvent(audit.ActionDelete, id, FromSQLC(before), FromSQLC(after)))
+	})
+}
+
+func (s *Service) RestoreInstrument(ctx context.Context, instrumentId uuid.UUID) (_ Instrument, err error) {
+	ctx, span := tracing.Start(ctx, "instrument.Service.RestoreInstrument")
+	defer tracing.End(span, &err)
+
+	before, err := s.repo.GetInstrumentById(ctx, instrumentId.String(), true)
+	if err != nil {
+		return Instrument{}, notFound(err)
+	}
+
+	var after sqlc.Instrument
+	err = s.repo.WithTx(ctx, func(tx *Repository) error {
+		restored, err := tx.Restore(ctx, instrumentId)
+		if err != nil {
+			return err
+		}
+		if !restored {
+			return ErrInstrumentNotDeleted
+		}
+
+		after, err = tx.GetInstrumentById(ctx, instrumentId.String(), false)
+		if err != nil {
+			return err
+		}
+		return tx.Audit(ctx, auditEvent(audit.ActionRestore, instrumentId, FromSQLC(before), FromSQLC(after)))
+	})
+	if err != nil {
+		return Instrument{}, err
+	}
+
+	return FromSQLC(after), nil
+}
+
+// PurgeDeleted hard deletes instruments soft deleted before the given time and
+// returns how many were removed.
+func (s *Service) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (_ int64, err error) {
+	ctx, span := tracing.Start(ctx, "instrument.Service.PurgeDeleted")
+	defer tracing.End(span, &err)
+
+	var purged []uuid.UUID
+	err = s.repo.WithTx(ctx, func(tx *Repository) error {
+		var err error
+		purged, err = tx.Purge(ctx, deletedBefore)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range purged {
+			if err := tx.Audit(ctx, auditEvent(audit.ActionPurge, id, nil, nil)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(purged)), nil
+}
+
+// notFound turns a missing instrument row into ErrInstrumentNotFound, which
+// still matches sql.ErrNoRows.
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInstrumentNotFound.Wrap(err)
+	}
+	return err
+}
+
+// symbolTaken turns a violation of the unique symbol constraint into
+// ErrSymbolTaken.
+func symbolTaken(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == "instruments_symbol_key" {
+		return ErrSymbolTaken.Wrap(err)
+	}
+	return err
+}
+
+func auditEvent(action string, instrumentId uuid.UUID, before any, after any) audit.Event {
+	return audit.Event{
+		Action:     action,
+		EntityType: AuditEntity,
+		EntityId:   instrumentId,
+		Before:     before,
+		After:      after,
 	}
-	return nil
 }
@@ -0,0 +1,81 @@
+// Package logging sets up the structured logger of the service. Records are
+// written as JSON or text, carry the request ID, route, user ID and trace ID
+// of the request they were logged for and have personal data and secrets
+// masked.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"user-management/internal/config"
+	"user-management/internal/tracing"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Formats of log records.
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// New returns the handler writing records to w in the configured format and
+// level. Records logged with the context of a request, like slog.InfoContext,
+// carry its request fields.
+func New(cfg config.Logging, w io.Writer) (slog.Handler, error) {
+	opts := &slog.HandlerOptions{Level: ParseLevel(cfg.Level), ReplaceAttr: Redact}
+
+	var h slog.Handler
+	switch strings.ToLower(cfg.Format) {
+	case "", FormatJSON:
+		h = slog.NewJSONHandler(w, opts)
+	case FormatText:
+		h = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("unknown logging format %q, expected json or text", cfg.Format)
+	}
+	return tracing.LogHandler(requestHandler{h}), nil
+}
+
+// ParseLevel returns the level named by level, INFO when it is unknown.
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// requestHandler adds the fields of the request of the context to records.
+type requestHandler struct {
+	slog.Handler
+}
+
+func (h requestHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := middleware.GetReqID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if route := routeFrom(ctx); route != "" {
+		r.AddAttrs(slog.String("route", route))
+	}
+	if f, ok := ctx.Value(fieldsKey).(*fields); ok && f.userID != "" {
+		r.AddAttrs(slog.String("user_id", f.userID))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h requestHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return requestHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h requestHandler) WithGroup(name string) slog.Handler {
+	return requestHandler{h.Handler.WithGroup(name)}
+}
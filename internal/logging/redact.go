@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// redacted replaces the values of secrets.
+const redacted = "[REDACTED]"
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// Redact masks personal data and secrets, it is a slog.HandlerOptions
+// ReplaceAttr function. Attributes are matched by key: secrets like tokens
+// and passwords are replaced, phone numbers keep their last digits. Email
+// addresses are masked in every string and error value, so they do not leak
+// through error messages.
+func Redact(groups []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+	switch {
+	case isSecret(key):
+		return slog.String(a.Key, redacted)
+	case strings.Contains(key, "phone"):
+		return slog.String(a.Key, MaskPhone(a.Value.String()))
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		if s := a.Value.String(); strings.Contains(s, "@") {
+			return slog.String(a.Key, MaskEmails(s))
+		}
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok && strings.Contains(err.Error(), "@") {
+			return slog.String(a.Key, MaskEmails(err.Error()))
+		}
+	}
+	return a
+}
+
+func isSecret(key string) bool {
+	switch key {
+	case "authorization", "cookie", "apikey", "api_key", "code":
+		return true
+	}
+	return strings.Contains(key, "token") || strings.Contains(key, "password") || strings.Contains(key, "secret")
+}
+
+// MaskEmails masks the local part of the email addresses in s except its
+// first character, jane@example.com becomes j***@example.com.
+func MaskEmails(s string) string {
+	return emailPattern.ReplaceAllStringFunc(s, func(email string) string {
+		local, domain, _ := strings.Cut(email, "@")
+		return local[:1] + "***@" + domain
+	})
+}
+
+// MaskPhone keeps the last two digits of a phone number.
+func MaskPhone(phone string) string {
+	if len(phone) <= 2 {
+		return "***"
+	}
+	return "***" + phone[len(phone)-2:]
+}
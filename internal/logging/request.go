@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+type contextKey string
+
+const fieldsKey contextKey = "logFields"
+
+// fields are the request fields that are only known once middleware further
+// down the chain ran, like the authenticated user.
+type fields struct {
+	userID string
+}
+
+// Middleware logs every request once it was handled, with its status, size
+// and duration. It stores the request fields in the context, so it must run
+// before the middleware setting them, like authentication.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		ctx := context.WithValue(r.Context(), fieldsKey, &fields{})
+
+		defer func() {
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			slog.Log(ctx, level, "Request completed",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", status,
+				"bytes", ww.BytesWritten(),
+				"duration", time.Since(start),
+				"remoteAddr", r.RemoteAddr,
+			)
+		}()
+
+		next.ServeHTTP(ww, r.WithContext(ctx))
+	})
+}
+
+// SetUserID records the ID of the user the request is made by in the request
+// fields of ctx, it does nothing outside of requests.
+func SetUserID(ctx context.Context, userID string) {
+	if f, ok := ctx.Value(fieldsKey).(*fields); ok {
+		f.userID = userID
+	}
+}
+
+// routeFrom returns the chi route pattern of the request of ctx so far.
+func routeFrom(ctx context.Context) string {
+	if rctx := chi.RouteContext(ctx); rctx != nil {
+		return rctx.RoutePattern()
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"user-management/internal/config"
+)
+
+const (
+	DriverSMTP = "smtp"
+	DriverFile = "file"
+	DriverLog  = "log"
+)
+
+const defaultFrom = "user-management@localhost"
+
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends plain text emails.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New returns the mailer selected by the driver. The log mailer is used when no
+// driver is configured, so local setups work without an SMTP server.
+func New(cfg config.Mail) (Mailer, error) {
+	from := cfg.From
+	if from == "" {
+		from = defaultFrom
+	}
+
+	switch strings.ToLower(cfg.Driver) {
+	case DriverSMTP:
+		if cfg.SMTP.Host == "" {
+			return nil, fmt.Errorf("mail.smtp.host is required for the smtp driver")
+		}
+		return NewSMTPMailer(cfg.SMTP, from), nil
+	case DriverFile:
+		if cfg.Dir == "" {
+			return nil, fmt.Errorf("mail.dir is required for the file driver")
+		}
+		return NewFileMailer(cfg.Dir, from), nil
+	case DriverLog, "":
+		return NewLogMailer(from), nil
+	default:
+		return nil, fmt.Errorf("unsupported mail driver: %q", cfg.Driver)
+	}
+}
+
+// format renders the message as RFC 5322 text.
+func format(msg Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", msg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
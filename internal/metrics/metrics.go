@@ -0,0 +1,165 @@
+// Package metrics collects the Prometheus metrics of the service: the rate,
+// errors and duration of requests by route, the latency of database queries,
+// the stats of the connection pool and gauges of business data.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// unmatchedRoute labels requests no route matched, so unknown paths do not
+// create new series.
+const unmatchedRoute = "unmatched"
+
+// countTimeout limits the queries of count gauges during a scrape.
+const countTimeout = 5 * time.Second
+
+var queryBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5}
+
+// Metrics holds the collectors of the service in its own registry.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestErrors   *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	queryDuration   *prometheus.HistogramVec
+	queryErrors     *prometheus.CounterVec
+}
+
+// New returns metrics registering the request and query collectors and
+// those of the Go runtime and the process.
+func New() *Metrics {
+	labels := []string{"method", "route", "status"}
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Number of handled HTTP requests.",
+		}, labels),
+		requestErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_request_errors_total",
+			Help: "Number of HTTP requests answered with a server error.",
+		}, labels),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Duration of HTTP requests.",
+			Buckets: prometheus.DefBuckets,
+		}, labels),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "db_query_duration_seconds",
+			Help:    "Duration of database queries by sqlc query name.",
+			Buckets: queryBuckets,
+		}, []string{"query"}),
+		queryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "db_query_errors_total",
+			Help: "Number of failed database queries by sqlc query name.",
+		}, []string{"query"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests, m.requestErrors, m.requestDuration, m.queryDuration, m.queryErrors,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format. A collector that
+// fails is left out, the other metrics are still served.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError})
+}
+
+// Middleware counts and times requests by method, chi route pattern and
+// status. It must be used on the root router, the route is only known once
+// the request was routed.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		defer func() {
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			route := unmatchedRoute
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+
+			labels := prometheus.Labels{"method": r.Method, "route": route, "status": strconv.Itoa(status)}
+			m.requests.With(labels).Inc()
+			m.requestDuration.With(labels).Observe(time.Since(start).Seconds())
+			if status >= http.StatusInternalServerError {
+				m.requestErrors.With(labels).Inc()
+			}
+		}()
+
+		next.ServeHTTP(ww, r)
+	})
+}
+
+// ObserveQuery times a database query, it is a db.QueryHook.
+func (m *Metrics) ObserveQuery(ctx context.Context, name string) (context.Context, func(err error)) {
+	start := time.Now()
+	return ctx, func(err error) {
+		m.queryDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			m.queryErrors.WithLabelValues(name).Inc()
+		}
+	}
+}
+
+// RegisterDB exports the stats of the connection pool of conn, labelled
+// with the database name.
+func (m *Metrics) RegisterDB(name string, conn *sql.DB) error {
+	return m.registry.Register(collectors.NewDBStatsCollector(conn, name))
+}
+
+// RegisterCounts exports a gauge per key of the map count returns, with the
+// key as value of label. count runs on every scrape.
+func (m *Metrics) RegisterCounts(name string, help string, label string, count func(ctx context.Context) (map[string]int64, error)) error {
+	return m.registry.Register(&countCollector{
+		desc:  prometheus.NewDesc(name, help, []string{label}, nil),
+		count: count,
+	})
+}
+
+type countCollector struct {
+	desc  *prometheus.Desc
+	count func(ctx context.Context) (map[string]int64, error)
+}
+
+func (c *countCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *countCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), countTimeout)
+	defer cancel()
+
+	counts, err := c.count(ctx)
+	if err != nil {
+		slog.Error("Failed to collect metric", "metric", c.desc.String(), "error", err)
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+	for value, n := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(n), value)
+	}
+}
@@ -0,0 +1,242 @@
+package mfa
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	httputils "user-management/internal/common/httputils"
+	"user-management/internal/middleware"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	service  *Service
+	validate *validator.Validate
+}
+
+func NewHandler(service *Service, validate *validator.Validate) *Handler {
+	return &Handler{
+		service:  service,
+		validate: validate,
+	}
+}
+
+// GetMfaStatus godoc
+// @Summary Get MFA status
+// @Description Get whether MFA is enabled for the current user and how many recovery codes are left
+// @Tags mfa
+// @Produce  json
+// @Success 200 {object} Status
+// @Failure      401  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Router /auth/mfa [get]
+func (h *Handler) GetMfaStatus(w http.ResponseWriter, r *http.Request) {
+
+	userId, ok := mfaOwner(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := h.service.Status(r.Context(), userId)
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+// EnrollMfa godoc
+// @Summary Start MFA enrollment
+// @Description Generate a TOTP secret and otpauth URI for the current user. MFA is enabled once a code is confirmed with /auth/mfa/activate
+// @Tags mfa
+// @Produce  json
+// @Success 200 {object} Enrollment
+// @Failure      401  {object}  httputils.Problem
+// @Failure      409  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Router /auth/mfa/enroll [post]
+func (h *Handler) EnrollMfa(w http.ResponseWriter, r *http.Request) {
+
+	userId, ok := mfaOwner(w, r)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.service.Enroll(r.Context(), userId)
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, enrollment)
+}
+
+// ActivateMfa godoc
+// @Summary Activate MFA
+// @Description Confirm enrollment with a code from the authenticator app. Returns one-time recovery codes, which are only shown once
+// @Tags mfa
+// @Accept  json
+// @Produce  json
+// @Param request body MfaCodeRequest true "TOTP code"
+// @Success 200 {object} RecoveryCodes
+// @Failure      400  {object}  httputils.Problem
+// @Failure      401  {object}  httputils.Problem
+// @Failure      409  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Router /auth/mfa/activate [post]
+func (h *Handler) ActivateMfa(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	userId, req, ok := h.decodeCode(w, r)
+	if !ok {
+		return
+	}
+
+	codes, err := h.service.Activate(r.Context(), userId, req.Code)
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, RecoveryCodes{Codes: codes})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replace the recovery codes of the current user. Requires a TOTP or recovery code
+// @Tags mfa
+// @Accept  json
+// @Produce  json
+// @Param request body MfaCodeRequest true "TOTP or recovery code"
+// @Success 200 {object} RecoveryCodes
+// @Failure      400  {object}  httputils.Problem
+// @Failure      401  {object}  httputils.Problem
+// @Failure      429  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Router /auth/mfa/recovery-codes [post]
+func (h *Handler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	userId, req, ok := h.decodeCode(w, r)
+	if !ok {
+		return
+	}
+
+	codes, err := h.service.RegenerateRecoveryCodes(r.Context(), userId, req.Code)
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, RecoveryCodes{Codes: codes})
+}
+
+// DisableMfa godoc
+// @Summary Disable MFA
+// @Description Disable MFA for the current user. Requires a TOTP or recovery code
+// @Tags mfa
+// @Accept  json
+// @Produce  json
+// @Param request body MfaCodeRequest true "TOTP or recovery code"
+// @Success 204
+// @Failure      400  {object}  httputils.Problem
+// @Failure      401  {object}  httputils.Problem
+// @Failure      429  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Router /auth/mfa/disable [post]
+func (h *Handler) DisableMfa(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	userId, req, ok := h.decodeCode(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Disable(r.Context(), userId, req.Code); err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResetUserMfa godoc
+// @Summary Reset MFA of an user
+// @Description Disable MFA of an user who lost their authenticator and recovery codes. Requires every permission the user holds
+// @Tags mfa
+// @Produce  json
+// @Param id path string true "User ID"
+// @Success 204
+// @Failure      400  {object}  httputils.Problem
+// @Failure      403  {object}  httputils.Problem
+// @Failure      404  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users/{id}/mfa [delete]
+func (h *Handler) ResetUserMfa(w http.ResponseWriter, r *http.Request) {
+
+	userId, uuiderr := httputils.ParseUUIDFromURL(r, "id")
+	if uuiderr != nil {
+		httputils.WriteError(w, http.StatusBadRequest, "Invalid user ID format", r)
+		return
+	}
+
+	if err := h.service.Reset(r.Context(), userId); err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) decodeCode(w http.ResponseWriter, r *http.Request) (uuid.UUID, MfaCodeRequest, bool) {
+	var req MfaCodeRequest
+
+	userId, ok := mfaOwner(w, r)
+	if !ok {
+		return uuid.Nil, req, false
+	}
+
+	if err := httputils.DecodeAndValidateRequest(r, &req, h.validate); err != nil {
+		slog.WarnContext(r.Context(), "Invalid MFA request", "error", err)
+		httputils.WriteProblem(w, r, err)
+		return uuid.Nil, req, false
+	}
+
+	return userId, req, true
+}
+
+// mfaOwner returns the current user. MFA settings cannot be changed with an
+// API key.
+func mfaOwner(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	principal, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		httputils.WriteError(w, http.StatusUnauthorized, "Missing credentials", r)
+		return uuid.Nil, false
+	}
+
+	if principal.APIKeyID != uuid.Nil {
+		httputils.WriteError(w, http.StatusForbidden, "API keys cannot manage MFA", r)
+		return uuid.Nil, false
+	}
+
+	return principal.UserID, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,19 @@
+package mfa
+
+type Enrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauthUri"`
+}
+
+type RecoveryCodes struct {
+	Codes []string `json:"recoveryCodes"`
+}
+
+type Status struct {
+	Enabled                bool  `json:"enabled"`
+	RecoveryCodesRemaining int64 `json:"recoveryCodesRemaining"`
+}
+
+type MfaCodeRequest struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
@@ -0,0 +1,186 @@
+package mfa
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"user-management/internal/db"
+	"user-management/internal/db/sqlc"
+
+	"github.com/google/uuid"
+)
+
+type Repository struct {
+	db      *sql.DB
+	queries *sqlc.Queries
+}
+
+func NewRepository(conn *sql.DB, q *sqlc.Queries) *Repository {
+	return &Repository{db: conn, queries: q}
+}
+
+func (r *Repository) SetSecret(ctx context.Context, userId uuid.UUID, secret string) error {
+
+	params := sqlc.SetUserMfaSecretParams{
+		MfaSecret: sql.NullString{String: secret, Valid: true},
+		UserID:    userId,
+	}
+
+	return r.queries.SetUserMfaSecret(ctx, params)
+}
+
+// Enable turns on MFA and stores the recovery code hashes in one transaction.
+func (r *Repository) Enable(ctx context.Context, userId uuid.UUID, codeHashes []string) error {
+	return db.WithTx(ctx, r.db, func(q *sqlc.Queries) error {
+		if _, err := q.EnableUserMfa(ctx, userId); err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(ctx, q, userId, codeHashes)
+	})
+}
+
+func (r *Repository) Disable(ctx context.Context, userId uuid.UUID) error {
+	return db.WithTx(ctx, r.db, func(q *sqlc.Queries) error {
+		if err := q.DisableUserMfa(ctx, userId); err != nil {
+			return err
+		}
+		return q.DeleteMfaRecoveryCodes(ctx, userId)
+	})
+}
+
+func (r *Repository) ReplaceRecoveryCodes(ctx context.Context, userId uuid.UUID, codeHashes []string) error {
+	return db.WithTx(ctx, r.db, func(q *sqlc.Queries) error {
+		return replaceRecoveryCodes(ctx, q, userId, codeHashes)
+	})
+}
+
+// UseRecoveryCode marks an unused code as used and reports whether one matched.
+func (r *Repository) UseRecoveryCode(ctx context.Context, userId uuid.UUID, codeHash string) (bool, error) {
+
+	params := sqlc.UseMfaRecoveryCodeParams{
+		UsedAt:   sql.NullTime{Time: time.Now().UTC(), Valid: true},
+		UserID:   userId,
+		CodeHash: codeHash,
+	}
+
+	rows, err := r.queries.UseMfaRecoveryCode(ctx, params)
+	return rows > 0, err
+}
+
+// UpdateLastStep records the time step of an accepted code and reports false
+// when the same or a later step was already used.
+func (r *Repository) UpdateLastStep(ctx context.Context, userId uuid.UUID, step int64) (bool, error) {
+
+	params := sqlc.UpdateUserMfaLastStepParams{
+		MfaLastStep: sql.NullInt64{Int64: step, Valid: true},
+		UserID:      userId,
+	}
+
+	rows, err := r.queries.UpdateUserMfaLastStep(ctx, params)
+	return rows > 0, err
+}
+
+func (r *Repository) CountUnusedRecoveryCodes(ctx context.Context, userId uuid.UUID) (int64, error) {
+	return r.queries.CountUnusedMfaRecoveryCodes(ctx, userId)
+}
+
+// RecordFailure records a rejected code, of the login challenge when
+// challengeId is valid, and deletes the failures of the user that are no
+// longer counted, those at or before since.
+func (r *Repository) RecordFailure(ctx context.Context, userId uuid.UUID, challengeId uuid.NullUUID, since time.Time) error {
+	return db.WithTx(ctx, r.db, func(q *sqlc.Queries) error {
+		expired := sqlc.DeleteMfaFailedAttemptsBeforeParams{
+			UserID:   userId,
+			FailedAt: since,
+		}
+		if err := q.DeleteMfaFailedAttemptsBefore(ctx, expired); err != nil {
+			return err
+		}
+
+		params := sqlc.CreateMfaFailedAttemptParams{
+			ID:          uuid.New(),
+			UserID:      userId,
+			ChallengeID: challengeId,
+			FailedAt:    time.Now().UTC(),
+		}
+		return q.CreateMfaFailedAttempt(ctx, params)
+	})
+}
+
+// CountFailuresSince returns the codes of the user rejected after since.
+func (r *Repository) CountFailuresSince(ctx context.Context, userId uuid.UUID, since time.Time) (int64, error) {
+
+	params := sqlc.CountMfaFailedAttemptsSinceParams{
+		UserID:   userId,
+		FailedAt: since,
+	}
+
+	return r.queries.CountMfaFailedAttemptsSince(ctx, params)
+}
+
+// StartChallengeAttempt counts an attempt to pass the login challenge, which
+// is stored until expiresAt. It reports false when the challenge was
+// consumed or already had maxAttempts attempts. Expired challenges of the
+// user are deleted.
+func (r *Repository) StartChallengeAttempt(ctx context.Context, challengeId uuid.UUID, userId uuid.UUID, expiresAt time.Time, maxAttempts int) (bool, error) {
+	expired := sqlc.DeleteExpiredMfaChallengesParams{
+		UserID:    userId,
+		ExpiresAt: time.Now().UTC(),
+	}
+	if err := r.queries.DeleteExpiredMfaChallenges(ctx, expired); err != nil {
+		return false, err
+	}
+
+	params := sqlc.StartMfaChallengeAttemptParams{
+		ID:          challengeId,
+		UserID:      userId,
+		ExpiresAt:   expiresAt.UTC(),
+		MaxAttempts: int32(maxAttempts),
+	}
+
+	rows, err := r.queries.StartMfaChallengeAttempt(ctx, params)
+	return rows > 0, err
+}
+
+// ConsumeChallenge marks the login challenge as passed and reports false when
+// it already was.
+func (r *Repository) ConsumeChallenge(ctx context.Context, challengeId uuid.UUID) (bool, error) {
+
+	params := sqlc.ConsumeMfaChallengeParams{
+		ConsumedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+		ID:         challengeId,
+	}
+
+	rows, err := r.queries.ConsumeMfaChallenge(ctx, params)
+	return rows > 0, err
+}
+
+// IsChallengeConsumed reports whether the login challenge was passed.
+func (r *Repository) IsChallengeConsumed(ctx context.Context, challengeId uuid.UUID) (bool, error) {
+	challenge, err := r.queries.FindMfaChallenge(ctx, challengeId)
+	if err != nil {
+		return false, err
+	}
+	return challenge.ConsumedAt.Valid, nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, q *sqlc.Queries, userId uuid.UUID, codeHashes []string) error {
+	if err := q.DeleteMfaRecoveryCodes(ctx, userId); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	for _, hash := range codeHashes {
+		params := sqlc.CreateMfaRecoveryCodeParams{
+			ID:        uuid.New(),
+			UserID:    userId,
+			CodeHash:  hash,
+			CreatedAt: now,
+		}
+		if err := q.CreateMfaRecoveryCode(ctx, params); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,276 @@
+package mfa
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+	"user-management/internal/common/apperror"
+	"user-management/internal/user"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maxChallengeAttempts is the number of codes a login challenge accepts.
+	maxChallengeAttempts = 5
+	// challengeRetention keeps login challenges past the expiry of their
+	// token, longer than the leeway the token is verified with.
+	challengeRetention = time.Minute
+	// maxFailures is the number of rejected codes of an user within
+	// failureWindow after which no code is checked until the oldest of them
+	// is older than failureWindow.
+	maxFailures   = 10
+	failureWindow = 15 * time.Minute
+)
+
+var (
+	ErrMfaAlreadyEnabled    = apperror.New(apperror.Conflict, "mfa_already_enabled", "mfa is already enabled")
+	ErrMfaNotEnrolled       = apperror.New(apperror.Validation, "mfa_not_enrolled", "mfa enrollment has not been started")
+	ErrMfaNotEnabled        = apperror.New(apperror.Validation, "mfa_not_enabled", "mfa is not enabled")
+	ErrInvalidMfaCode       = apperror.New(apperror.Unauthorized, "invalid_mfa_code", "invalid mfa code")
+	ErrMfaChallengeExceeded = apperror.New(apperror.Unauthorized, "mfa_challenge_exceeded", "too many invalid mfa codes for the challenge, log in again")
+	ErrMfaChallengeUsed     = apperror.New(apperror.Unauthorized, "mfa_challenge_used", "the mfa challenge was already used, log in again")
+	ErrTooManyMfaFailures   = apperror.New(apperror.TooManyRequests, "too_many_mfa_failures", "too many invalid mfa codes, try again later")
+)
+
+type Service struct {
+	repo   *Repository
+	users  *user.Service
+	issuer string
+}
+
+// NewService creates the MFA service. The issuer is shown next to the account
+// in authenticator apps.
+func NewService(repo *Repository, users *user.Service, issuer string) *Service {
+	return &Service{repo: repo, users: users, issuer: issuer}
+}
+
+func (s *Service) Status(ctx context.Context, userId uuid.UUID) (Status, error) {
+	u, err := s.users.GetUserById(ctx, userId.String())
+	if err != nil {
+		return Status{}, err
+	}
+
+	if !u.MfaEnabled {
+		return Status{}, nil
+	}
+
+	remaining, err := s.repo.CountUnusedRecoveryCodes(ctx, userId)
+	if err != nil {
+		return Status{}, err
+	}
+
+	return Status{Enabled: true, RecoveryCodesRemaining: remaining}, nil
+}
+
+// Enroll starts enrollment with a new secret. MFA is only enabled once a code
+// generated from the secret is confirmed with Activate.
+func (s *Service) Enroll(ctx context.Context, userId uuid.UUID) (Enrollment, error) {
+	u, err := s.users.GetUserById(ctx, userId.String())
+	if err != nil {
+		return Enrollment{}, err
+	}
+
+	if u.MfaEnabled {
+		return Enrollment{}, ErrMfaAlreadyEnabled
+	}
+
+	secret, err := GenerateSecret()
+	if err != nil {
+		return Enrollment{}, err
+	}
+
+	if err := s.repo.SetSecret(ctx, userId, secret); err != nil {
+		return Enrollment{}, err
+	}
+
+	return Enrollment{Secret: secret, URI: URI(s.issuer, u.Email, secret)}, nil
+}
+
+// Activate enables MFA once the user proves the secret was stored by an
+// authenticator app, and returns the recovery codes.
+func (s *Service) Activate(ctx context.Context, userId uuid.UUID, code string) ([]string, error) {
+	u, err := s.users.GetUserById(ctx, userId.String())
+	if err != nil {
+		return nil, err
+	}
+
+	if u.MfaEnabled {
+		return nil, ErrMfaAlreadyEnabled
+	}
+	if u.MfaSecret == "" {
+		return nil, ErrMfaNotEnrolled
+	}
+
+	if err := s.verifyTOTP(ctx, u, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Enable(ctx, userId, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Verify accepts a TOTP code or an unused recovery code of the user. Once
+// maxFailures codes of the user were rejected within failureWindow, codes are
+// rejected with ErrTooManyMfaFailures without checking them.
+func (s *Service) Verify(ctx context.Context, userId uuid.UUID, code string) error {
+	return s.verify(ctx, userId, uuid.NullUUID{}, code)
+}
+
+// VerifyChallenge is Verify for the login challenge with the given id, whose
+// token expires at expiresAt. A challenge accepts maxChallengeAttempts codes,
+// later ones are rejected with ErrMfaChallengeExceeded. Once a code was
+// accepted, the challenge is consumed and rejected with ErrMfaChallengeUsed.
+func (s *Service) VerifyChallenge(ctx context.Context, userId uuid.UUID, challengeId uuid.UUID, expiresAt time.Time, code string) error {
+	// Counting the attempt before checking the code keeps parallel requests
+	// from checking more codes than the challenge accepts.
+	started, err := s.repo.StartChallengeAttempt(ctx, challengeId, userId, expiresAt.Add(challengeRetention), maxChallengeAttempts)
+	if err != nil {
+		return err
+	}
+	if !started {
+		consumed, err := s.repo.IsChallengeConsumed(ctx, challengeId)
+		if err != nil {
+			return err
+		}
+		if consumed {
+			return ErrMfaChallengeUsed
+		}
+		return ErrMfaChallengeExceeded
+	}
+
+	if err := s.verify(ctx, userId, uuid.NullUUID{UUID: challengeId, Valid: true}, code); err != nil {
+		return err
+	}
+
+	consumed, err := s.repo.ConsumeChallenge(ctx, challengeId)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return ErrMfaChallengeUsed
+	}
+	return nil
+}
+
+func (s *Service) verify(ctx context.Context, userId uuid.UUID, challengeId uuid.NullUUID, code string) error {
+	u, err := s.users.GetUserById(ctx, userId.String())
+	if err != nil {
+		return err
+	}
+
+	if !u.MfaEnabled {
+		return ErrMfaNotEnabled
+	}
+
+	since := time.Now().UTC().Add(-failureWindow)
+	failures, err := s.repo.CountFailuresSince(ctx, userId, since)
+	if err != nil {
+		return err
+	}
+	if failures >= maxFailures {
+		slog.WarnContext(ctx, "Too many invalid mfa codes", "userId", userId)
+		return ErrTooManyMfaFailures
+	}
+
+	err = s.checkCode(ctx, u, code)
+	if errors.Is(err, ErrInvalidMfaCode) {
+		if recordErr := s.repo.RecordFailure(ctx, userId, challengeId, since); recordErr != nil {
+			return recordErr
+		}
+	}
+	return err
+}
+
+func (s *Service) checkCode(ctx context.Context, u user.User, code string) error {
+	if isTOTPCode(code) {
+		return s.verifyTOTP(ctx, u, code)
+	}
+
+	used, err := s.repo.UseRecoveryCode(ctx, u.UserId, hashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidMfaCode
+	}
+	return nil
+}
+
+func (s *Service) Disable(ctx context.Context, userId uuid.UUID, code string) error {
+	if err := s.Verify(ctx, userId, code); err != nil {
+		return err
+	}
+	return s.repo.Disable(ctx, userId)
+}
+
+// Reset turns off MFA without a code, for administrators helping users who
+// lost both their device and recovery codes. Administrators can only reset
+// users holding no permission they lack.
+func (s *Service) Reset(ctx context.Context, userId uuid.UUID) error {
+	if _, err := s.users.GetUserById(ctx, userId.String()); err != nil {
+		return err
+	}
+	if err := s.users.CheckPermissionsHeld(ctx, userId); err != nil {
+		return err
+	}
+	return s.repo.Disable(ctx, userId)
+}
+
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userId uuid.UUID, code string) ([]string, error) {
+	if err := s.Verify(ctx, userId, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userId, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// verifyTOTP checks the code and records its time step, so a code cannot be
+// replayed while it is still valid.
+func (s *Service) verifyTOTP(ctx context.Context, u user.User, code string) error {
+	step, ok := Validate(u.MfaSecret, code, time.Now())
+	if !ok {
+		return ErrInvalidMfaCode
+	}
+
+	fresh, err := s.repo.UpdateLastStep(ctx, u.UserId, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return ErrInvalidMfaCode
+	}
+	return nil
+}
+
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes := make([]string, len(codes))
+	for i, c := range codes {
+		hashes[i] = hashRecoveryCode(c)
+	}
+
+	return codes, hashes, nil
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	httputils "user-management/internal/common/httputils"
+	"user-management/internal/logging"
+
+	"github.com/google/uuid"
+)
+
+const PrincipalKey contextKey = "principal"
+
+// Principal is the authenticated caller of a request. APIKeyID is set when
+// the request was authenticated with an API key instead of an access token.
+type Principal struct {
+	UserID      uuid.UUID
+	Email       string
+	Roles       []string
+	Permissions []string
+	APIKeyID    uuid.UUID
+}
+
+func (p *Principal) HasPermission(permission string) bool {
+	return slices.Contains(p.Permissions, permission)
+}
+
+// Authenticator validates the credential sent with a given Authorization scheme.
+type Authenticator interface {
+	Scheme() string
+	Authenticate(ctx context.Context, credential string) (*Principal, error)
+}
+
+// Authenticate rejects requests without a valid Authorization header and stores
+// the resolved principal in the request context.
+func Authenticate(authenticators ...Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scheme, credential, found := strings.Cut(r.Header.Get("Authorization"), " ")
+			if !found || credential == "" {
+				unauthorized(w, r, authenticators, "Missing credentials")
+				return
+			}
+
+			for _, a := range authenticators {
+				if !strings.EqualFold(a.Scheme(), scheme) {
+					continue
+				}
+
+				principal, err := a.Authenticate(r.Context(), strings.TrimSpace(credential))
+				if err != nil {
+					slog.WarnContext(r.Context(), "Authentication failed", "scheme", a.Scheme(), "error", err)
+					unauthorized(w, r, authenticators, "Invalid credentials")
+					return
+				}
+
+				logging.SetUserID(r.Context(), principal.UserID.String())
+				ctx := context.WithValue(r.Context(), PrincipalKey, principal)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			unauthorized(w, r, authenticators, "Unsupported authorization scheme")
+		})
+	}
+}
+
+// PrincipalFrom returns the principal stored by Authenticate.
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(PrincipalKey).(*Principal)
+	return principal, ok
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request, authenticators []Authenticator, message string) {
+	for _, a := range authenticators {
+		w.Header().Add("WWW-Authenticate", a.Scheme())
+	}
+	httputils.WriteError(w, http.StatusUnauthorized, message, r)
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	httputils "user-management/internal/common/httputils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// RequirePermission only lets principals holding the permission through.
+// It must be mounted after Authenticate.
+func RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFrom(r.Context())
+			if !ok {
+				httputils.WriteError(w, http.StatusUnauthorized, "Missing credentials", r)
+				return
+			}
+
+			if !principal.HasPermission(permission) {
+				slog.WarnContext(r.Context(), "Permission denied", "userId", principal.UserID, "permission", permission, "path", r.URL.Path)
+				httputils.WriteError(w, http.StatusForbidden, "Missing permission "+permission, r)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireSelfOrPermission lets principals act on their own user, identified by
+// the URL parameter, or on any user when they hold the permission.
+func RequireSelfOrPermission(param string, permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFrom(r.Context())
+			if !ok {
+				httputils.WriteError(w, http.StatusUnauthorized, "Missing credentials", r)
+				return
+			}
+
+			if chi.URLParam(r, param) != principal.UserID.String() && !principal.HasPermission(permission) {
+				slog.WarnContext(r.Context(), "Permission denied", "userId", principal.UserID, "permission", permission, "path", r.URL.Path)
+				httputils.WriteError(w, http.StatusForbidden, "Missing permission "+permission, r)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
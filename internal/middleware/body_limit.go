@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+	httputils "user-management/internal/common/httputils"
+)
+
+// MaxBodySize limits request bodies to limit bytes. Requests announcing a
+// larger body are rejected with 413 right away, reading past the limit fails
+// with an *http.MaxBytesError, which httputils.WriteProblem answers with 413
+// as well. A limit of 0 disables the check.
+func MaxBodySize(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limit <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				httputils.WriteProblem(w, r, &http.MaxBytesError{Limit: limit})
+				return
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
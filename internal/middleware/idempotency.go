@@ -0,0 +1,190 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"user-management/internal/common/apperror"
+	httputils "user-management/internal/common/httputils"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+var (
+	ErrIdempotencyKeyTooLong    = apperror.New(apperror.Validation, "idempotency_key_too_long", fmt.Sprintf("Idempotency-Key must be at most %d characters", maxIdempotencyKeyLength))
+	ErrIdempotencyKeyInProgress = apperror.New(apperror.Conflict, "idempotency_key_in_progress", "A request with this Idempotency-Key is still in progress")
+	ErrIdempotencyKeyReused     = apperror.New(apperror.Unprocessable, "idempotency_key_reused", "Idempotency-Key was already used for a different request")
+)
+
+// replayedHeaders are the response headers stored and replayed with an
+// idempotent response.
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// IdempotentResponse is the response stored for an Idempotency-Key. The
+// StatusCode is 0 while the first request with the key is still in progress.
+type IdempotentResponse struct {
+	Fingerprint string
+	StatusCode  int
+	Header      http.Header
+	Body        []byte
+}
+
+// ErrIdempotencyClaimLost is returned by an IdempotencyStore when the claim
+// of a key expired and the key was claimed again by a retry.
+var ErrIdempotencyClaimLost = errors.New("idempotency key claim was lost")
+
+// IdempotencyStore keeps the responses of requests sent with an
+// Idempotency-Key. Keys are scoped to the caller that sent them.
+type IdempotencyStore interface {
+	// Claim reserves the key for a request with the given fingerprint. It
+	// returns the id of the claim when the key was claimed, otherwise the
+	// response stored for it by an earlier request.
+	Claim(ctx context.Context, scope string, key string, fingerprint string) (string, *IdempotentResponse, error)
+	// Complete stores the response of the request holding the claim.
+	Complete(ctx context.Context, scope string, key string, claim string, resp IdempotentResponse) error
+	// Release frees a claimed key without a response, so it can be retried.
+	Release(ctx context.Context, scope string, key string, claim string) error
+}
+
+// Idempotency makes requests sent with an Idempotency-Key header safe to
+// retry. The first request runs and its response is stored, a retry with the
+// same key and body gets the stored response replayed. Reusing the key for a
+// different request is rejected with 422, and a retry sent while the first
+// request is still running gets 409. Server errors are not stored, so the
+// request runs again on retry.
+func Idempotency(store IdempotencyStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				httputils.WriteProblem(w, r, ErrIdempotencyKeyTooLong)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				httputils.WriteProblem(w, r, httputils.ErrInvalidRequest.WithMessage("Failed to read request body").Wrap(err))
+				return
+			}
+			r.Body.Close()
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			var scope string
+			if principal, ok := PrincipalFrom(r.Context()); ok {
+				scope = principal.UserID.String()
+			}
+			fingerprint := requestFingerprint(r, body)
+
+			claim, stored, err := store.Claim(r.Context(), scope, key, fingerprint)
+			switch {
+			case err != nil:
+				httputils.WriteProblem(w, r, fmt.Errorf("failed to claim idempotency key: %w", err))
+				return
+			case stored == nil:
+			case stored.Fingerprint != fingerprint:
+				httputils.WriteProblem(w, r, ErrIdempotencyKeyReused)
+				return
+			case stored.StatusCode == 0:
+				httputils.WriteProblem(w, r, ErrIdempotencyKeyInProgress)
+				return
+			default:
+				replay(w, stored)
+				return
+			}
+
+			// The claim is released unless a response was stored or the claim
+			// was lost, including when the handler panics.
+			ctx := context.WithoutCancel(r.Context())
+			completed := false
+			defer func() {
+				if completed {
+					return
+				}
+				if err := store.Release(ctx, scope, key, claim); err != nil {
+					logClaimError(r, "Failed to release idempotency key", err)
+				}
+			}()
+
+			var buf bytes.Buffer
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(&buf)
+
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if status >= http.StatusInternalServerError {
+				return
+			}
+
+			resp := IdempotentResponse{
+				Fingerprint: fingerprint,
+				StatusCode:  status,
+				Header:      http.Header{},
+				Body:        buf.Bytes(),
+			}
+			for _, h := range replayedHeaders {
+				if v := w.Header().Get(h); v != "" {
+					resp.Header.Set(h, v)
+				}
+			}
+
+			err = store.Complete(ctx, scope, key, claim, resp)
+			if err != nil {
+				logClaimError(r, "Failed to store idempotent response", err)
+			}
+			completed = err == nil || errors.Is(err, ErrIdempotencyClaimLost)
+		})
+	}
+}
+
+// logClaimError logs a failure to complete or release a claim. A lost claim
+// means the claim timeout was too short and a retry ran the request again.
+func logClaimError(r *http.Request, msg string, err error) {
+	if errors.Is(err, ErrIdempotencyClaimLost) {
+		slog.WarnContext(r.Context(), msg, "key", r.Header.Get(IdempotencyKeyHeader), "error", err)
+		return
+	}
+	slog.ErrorContext(r.Context(), msg, "error", err)
+}
+
+func replay(w http.ResponseWriter, resp *IdempotentResponse) {
+	for h, values := range resp.Header {
+		for _, v := range values {
+			w.Header().Add(h, v)
+		}
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(resp.StatusCode)
+	w.Write(resp.Body)
+}
+
+// requestFingerprint identifies the request a key was used for by its method,
+// path and body.
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.Path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
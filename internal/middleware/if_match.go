@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	httputils "user-management/internal/common/httputils"
+)
+
+const IfMatchKey contextKey = "ifMatch"
+
+// IfMatch reads the version expected by the If-Match header into the context.
+// The version is 0 when the header is missing or "*", which skips the check.
+// With required set, requests without the header get 428 Precondition Required.
+func IfMatch(required bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var version int64
+
+			switch header := r.Header.Get("If-Match"); header {
+			case "":
+				if required {
+					httputils.WriteError(w, http.StatusPreconditionRequired, "If-Match header is required", r)
+					return
+				}
+			case "*":
+			default:
+				parsed, err := httputils.ParseETag(header)
+				if err != nil {
+					httputils.WriteError(w, http.StatusBadRequest, "If-Match must be a single entity tag returned as ETag", r)
+					return
+				}
+				version = parsed
+			}
+
+			ctx := context.WithValue(r.Context(), IfMatchKey, version)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	httputils "user-management/internal/common/httputils"
+)
+
+const IncludeDeletedKey contextKey = "includeDeleted"
+
+// IncludeDeleted reads the include_deleted query parameter into the context.
+// Only principals holding the permission may ask for soft deleted rows. It
+// must be mounted after Authenticate.
+func IncludeDeleted(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			include := false
+
+			if v := r.URL.Query().Get("include_deleted"); v != "" {
+				parsed, err := strconv.ParseBool(v)
+				if err != nil {
+					httputils.WriteError(w, http.StatusBadRequest, "Invalid include_deleted value", r)
+					return
+				}
+				include = parsed
+			}
+
+			if include {
+				principal, ok := PrincipalFrom(r.Context())
+				if !ok || !principal.HasPermission(permission) {
+					slog.WarnContext(r.Context(), "Permission denied", "permission", permission, "path", r.URL.Path)
+					httputils.WriteError(w, http.StatusForbidden, "Missing permission "+permission, r)
+					return
+				}
+			}
+
+			ctx := context.WithValue(r.Context(), IncludeDeletedKey, include)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
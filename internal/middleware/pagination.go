@@ -4,6 +4,8 @@ import (
 	"context"
 	"net/http"
 	"strconv"
+	httputils "user-management/internal/common/httputils"
+	"user-management/internal/db/query"
 )
 
 type contextKey string
@@ -11,6 +13,10 @@ type contextKey string
 const (
 	PageKey  contextKey = "page"
 	LimitKey contextKey = "limit"
+
+	// PageRequestKey holds the query.PageRequest of keyset paginated lists,
+	// which also read the cursor and count parameters.
+	PageRequestKey contextKey = "pageRequest"
 )
 
 func Paginate(next http.Handler) http.Handler {
@@ -32,8 +38,20 @@ func Paginate(next http.Handler) http.Handler {
 			}
 		}
 
+		count, err := query.ParseCountMode(q.Get("count"))
+		if err != nil {
+			httputils.WriteProblem(w, r, err)
+			return
+		}
+
 		ctx := context.WithValue(r.Context(), PageKey, page)
 		ctx = context.WithValue(ctx, LimitKey, limit)
+		ctx = context.WithValue(ctx, PageRequestKey, query.PageRequest{
+			Limit:  limit,
+			Offset: (page - 1) * limit,
+			Cursor: q.Get("cursor"),
+			Count:  count,
+		})
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
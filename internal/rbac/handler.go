@@ -0,0 +1,188 @@
+package rbac
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	httputils "user-management/internal/common/httputils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+type Handler struct {
+	service  *Service
+	validate *validator.Validate
+}
+
+func NewHandler(service *Service, validate *validator.Validate) *Handler {
+	return &Handler{
+		service:  service,
+		validate: validate,
+	}
+}
+
+// GetRoles godoc
+// @Summary Get all roles
+// @Description Get all roles with their permissions
+// @Tags roles
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} Role
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /roles [get]
+func (h *Handler) GetRoles(w http.ResponseWriter, r *http.Request) {
+
+	roles, err := h.service.ListRoles(r.Context())
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(roles)
+}
+
+// UpdateRole godoc
+// @Summary Update a role
+// @Description Set whether users with the role must use MFA. Users without MFA get an access token without permissions until they enroll
+// @Tags roles
+// @Accept  json
+// @Produce  json
+// @Param role path string true "Role name"
+// @Param request body RoleUpdateRequest true "Role settings"
+// @Success 204
+// @Failure      400  {object}  httputils.Problem
+// @Failure      404  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /roles/{role} [patch]
+func (h *Handler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req RoleUpdateRequest
+	if err := httputils.DecodeAndValidateRequest(r, &req, h.validate); err != nil {
+		slog.WarnContext(r.Context(), "Role update failed", "error", err)
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	err := h.service.SetRequireMfa(r.Context(), chi.URLParam(r, "role"), *req.RequireMfa)
+
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetUserRoles godoc
+// @Summary Get roles of an user
+// @Description Get the roles assigned to an user
+// @Tags roles
+// @Accept  json
+// @Produce  json
+// @Param id path string true "User ID"
+// @Success 200 {array} Role
+// @Failure      400  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users/{id}/roles [get]
+func (h *Handler) GetUserRoles(w http.ResponseWriter, r *http.Request) {
+
+	userId, uuiderr := httputils.ParseUUIDFromURL(r, "id")
+	if uuiderr != nil {
+		httputils.WriteError(w, http.StatusBadRequest, "Invalid user ID format", r)
+		return
+	}
+
+	roles, err := h.service.GetUserRoles(r.Context(), userId)
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(roles)
+}
+
+// AssignUserRole godoc
+// @Summary Assign a role to an user
+// @Description Assign a role to an user. Takes effect when the user next logs in or refreshes the access token
+// @Tags roles
+// @Accept  json
+// @Produce  json
+// @Param id path string true "User ID"
+// @Param request body RoleAssignRequest true "Role to assign"
+// @Success 204
+// @Failure      400  {object}  httputils.Problem
+// @Failure      404  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users/{id}/roles [post]
+func (h *Handler) AssignUserRole(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	userId, uuiderr := httputils.ParseUUIDFromURL(r, "id")
+	if uuiderr != nil {
+		httputils.WriteError(w, http.StatusBadRequest, "Invalid user ID format", r)
+		return
+	}
+
+	var req RoleAssignRequest
+	if err := httputils.DecodeAndValidateRequest(r, &req, h.validate); err != nil {
+		slog.WarnContext(r.Context(), "Role assignment failed", "error", err)
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	err := h.service.AssignRole(r.Context(), userId, req.Role)
+
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveUserRole godoc
+// @Summary Remove a role from an user
+// @Description Remove a role from an user
+// @Tags roles
+// @Accept  json
+// @Produce  json
+// @Param id path string true "User ID"
+// @Param role path string true "Role name"
+// @Success 204
+// @Failure      400  {object}  httputils.Problem
+// @Failure      404  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users/{id}/roles/{role} [delete]
+func (h *Handler) RemoveUserRole(w http.ResponseWriter, r *http.Request) {
+
+	userId, uuiderr := httputils.ParseUUIDFromURL(r, "id")
+	if uuiderr != nil {
+		httputils.WriteError(w, http.StatusBadRequest, "Invalid user ID format", r)
+		return
+	}
+
+	err := h.service.RemoveRole(r.Context(), userId, chi.URLParam(r, "role"))
+
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,68 @@
+package rbac
+
+import (
+	"context"
+	"user-management/internal/db/sqlc"
+
+	"github.com/google/uuid"
+)
+
+type Repository struct {
+	queries *sqlc.Queries
+}
+
+func NewRepository(q *sqlc.Queries) *Repository {
+	return &Repository{queries: q}
+}
+
+func (r *Repository) GetAllRoles(ctx context.Context) ([]sqlc.Role, error) {
+	return r.queries.ListRoles(ctx)
+}
+
+func (r *Repository) GetRoleByName(ctx context.Context, name string) (sqlc.Role, error) {
+	return r.queries.FindRoleByName(ctx, name)
+}
+
+func (r *Repository) GetAllRolePermissions(ctx context.Context) ([]sqlc.RolePermission, error) {
+	return r.queries.ListRolePermissions(ctx)
+}
+
+func (r *Repository) GetUserRoles(ctx context.Context, userId uuid.UUID) ([]sqlc.Role, error) {
+	return r.queries.ListUserRoles(ctx, userId)
+}
+
+func (r *Repository) GetUserPermissions(ctx context.Context, userId uuid.UUID) ([]string, error) {
+	return r.queries.ListUserPermissions(ctx, userId)
+}
+
+func (r *Repository) AssignRole(ctx context.Context, userId uuid.UUID, role string) error {
+
+	params := sqlc.AssignUserRoleParams{
+		UserID:   userId,
+		RoleName: role,
+	}
+
+	return r.queries.AssignUserRole(ctx, params)
+}
+
+func (r *Repository) RemoveRole(ctx context.Context, userId uuid.UUID, role string) (bool, error) {
+
+	params := sqlc.RemoveUserRoleParams{
+		UserID:   userId,
+		RoleName: role,
+	}
+
+	rows, err := r.queries.RemoveUserRole(ctx, params)
+	return rows > 0, err
+}
+
+func (r *Repository) SetRequireMfa(ctx context.Context, role string, requireMfa bool) (bool, error) {
+
+	params := sqlc.UpdateRoleRequireMfaParams{
+		RequireMfa: requireMfa,
+		Name:       role,
+	}
+
+	rows, err := r.queries.UpdateRoleRequireMfa(ctx, params)
+	return rows > 0, err
+}
@@ -0,0 +1,51 @@
+package rbac
+
+import "user-management/internal/db/sqlc"
+
+const (
+	RoleAdmin    = "admin"
+	RoleOperator = "operator"
+	RoleViewer   = "viewer"
+)
+
+const (
+	PermUsersRead         = "users:read"
+	PermUsersWrite        = "users:write"
+	PermUsersDelete       = "users:delete"
+	PermInstrumentsRead   = "instruments:read"
+	PermInstrumentsWrite  = "instruments:write"
+	PermInstrumentsDelete = "instruments:delete"
+	PermRolesRead         = "roles:read"
+	PermRolesWrite        = "roles:write"
+	PermAuditRead         = "audit:read"
+)
+
+type Role struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	RequireMfa  bool     `json:"requireMfa"`
+}
+
+// FromSQLC maps roles and attaches the permissions granted to each of them.
+func FromSQLC(roles []sqlc.Role, grants []sqlc.RolePermission) []Role {
+	permissions := make(map[string][]string)
+	for _, g := range grants {
+		permissions[g.RoleName] = append(permissions[g.RoleName], g.PermissionName)
+	}
+
+	mapped := make([]Role, len(roles))
+	for i, r := range roles {
+		perms := permissions[r.Name]
+		if perms == nil {
+			perms = []string{}
+		}
+		mapped[i] = Role{
+			Name:        r.Name,
+			Description: r.Description,
+			Permissions: perms,
+			RequireMfa:  r.RequireMfa,
+		}
+	}
+	return mapped
+}
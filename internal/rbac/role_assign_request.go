@@ -0,0 +1,5 @@
+package rbac
+
+type RoleAssignRequest struct {
+	Role string `json:"role" validate:"required"`
+}
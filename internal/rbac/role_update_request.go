@@ -0,0 +1,5 @@
+package rbac
+
+type RoleUpdateRequest struct {
+	RequireMfa *bool `json:"requireMfa" validate:"required"`
+}
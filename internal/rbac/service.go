@@ -0,0 +1,130 @@
+package rbac
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"user-management/internal/common/apperror"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const foreignKeyViolation = "23503"
+
+var (
+	ErrRoleNotFound    = apperror.New(apperror.NotFound, "role_not_found", "role not found")
+	ErrUserNotFound    = apperror.New(apperror.NotFound, "user_not_found", "user not found")
+	ErrRoleNotAssigned = apperror.New(apperror.NotFound, "role_not_assigned", "role is not assigned to user")
+)
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+func (s *Service) ListRoles(ctx context.Context) ([]Role, error) {
+	roles, err := s.repo.GetAllRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	grants, err := s.repo.GetAllRolePermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromSQLC(roles, grants), nil
+}
+
+func (s *Service) GetUserRoles(ctx context.Context, userId uuid.UUID) ([]Role, error) {
+	roles, err := s.repo.GetUserRoles(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	grants, err := s.repo.GetAllRolePermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromSQLC(roles, grants), nil
+}
+
+// GetUserAccess returns the role names and the union of their permissions.
+func (s *Service) GetUserAccess(ctx context.Context, userId uuid.UUID) ([]string, []string, error) {
+	roles, err := s.repo.GetUserRoles(ctx, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	permissions, err := s.repo.GetUserPermissions(ctx, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := make([]string, len(roles))
+	for i, r := range roles {
+		names[i] = r.Name
+	}
+
+	return names, permissions, nil
+}
+
+// RequiresMfa reports whether any role of the user requires MFA.
+func (s *Service) RequiresMfa(ctx context.Context, userId uuid.UUID) (bool, error) {
+	roles, err := s.repo.GetUserRoles(ctx, userId)
+	if err != nil {
+		return false, err
+	}
+
+	for _, r := range roles {
+		if r.RequireMfa {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *Service) SetRequireMfa(ctx context.Context, role string, requireMfa bool) error {
+	updated, err := s.repo.SetRequireMfa(ctx, role, requireMfa)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrRoleNotFound
+	}
+	return nil
+}
+
+func (s *Service) AssignRole(ctx context.Context, userId uuid.UUID, role string) error {
+	if _, err := s.repo.GetRoleByName(ctx, role); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrRoleNotFound
+		}
+		return err
+	}
+
+	err := s.repo.AssignRole(ctx, userId, role)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+		return ErrUserNotFound
+	}
+
+	return err
+}
+
+func (s *Service) RemoveRole(ctx context.Context, userId uuid.UUID, role string) error {
+	removed, err := s.repo.RemoveRole(ctx, userId, role)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrRoleNotAssigned
+	}
+	return nil
+}
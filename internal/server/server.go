@@ -0,0 +1,56 @@
+// Package server builds the HTTP servers of the service from the server
+// config: connection timeouts, header limits and TLS with optional client
+// certificate authentication.
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"user-management/internal/config"
+)
+
+// New returns a server listening on port with the timeouts and limits of
+// cfg. TLS is configured with ConfigureTLS.
+func New(cfg config.Server, port int, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+}
+
+// ConfigureTLS makes srv serve HTTPS with the certificates of cfg, which are
+// reloaded when their files change until ctx is done. Without a certificate
+// configured srv keeps serving plain HTTP.
+func ConfigureTLS(ctx context.Context, srv *http.Server, cfg config.TLS) error {
+	if !Enabled(cfg) {
+		return nil
+	}
+
+	reloader, err := NewCertReloader(cfg)
+	if err != nil {
+		return err
+	}
+	srv.TLSConfig = reloader.TLSConfig()
+	go reloader.Watch(ctx, cfg.ReloadInterval)
+	return nil
+}
+
+// ListenAndServe serves HTTPS when srv has a TLS config, plain HTTP
+// otherwise. It returns http.ErrServerClosed once the server was shut down.
+func ListenAndServe(srv *http.Server) error {
+	if srv.TLSConfig != nil {
+		return srv.ListenAndServeTLS("", "")
+	}
+	return srv.ListenAndServe()
+}
+
+// Enabled reports whether cfg configures a certificate to serve HTTPS with.
+func Enabled(cfg config.TLS) bool {
+	return cfg.CertFile != "" || cfg.KeyFile != ""
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"sync/atomic"
+	"time"
+	"user-management/internal/config"
+)
+
+// Client authentication modes.
+const (
+	ClientAuthNone     = "none"
+	ClientAuthOptional = "optional"
+	ClientAuthRequire  = "require"
+)
+
+// CertReloader serves the certificate and client CAs of the TLS config and
+// reads them again when their files change. Connections already established
+// keep the certificate they were made with.
+type CertReloader struct {
+	cfg        config.TLS
+	clientAuth tls.ClientAuthType
+	minVersion uint16
+
+	state atomic.Pointer[certState]
+}
+
+type certState struct {
+	config   *tls.Config
+	modTimes []time.Time
+}
+
+// NewCertReloader validates cfg and loads its certificates.
+func NewCertReloader(cfg config.TLS) (*CertReloader, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, errors.New("tls requires both certFile and keyFile")
+	}
+
+	c := &CertReloader{cfg: cfg}
+	switch cfg.ClientAuth {
+	case "", ClientAuthNone:
+		c.clientAuth = tls.NoClientCert
+	case ClientAuthOptional:
+		c.clientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		c.clientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, fmt.Errorf("unknown tls clientAuth %q, expected none, optional or require", cfg.ClientAuth)
+	}
+	if c.clientAuth != tls.NoClientCert && cfg.ClientCAFile == "" {
+		return nil, fmt.Errorf("tls clientAuth %s requires a clientCAFile", cfg.ClientAuth)
+	}
+
+	switch cfg.MinVersion {
+	case "", "1.2":
+		c.minVersion = tls.VersionTLS12
+	case "1.3":
+		c.minVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("unknown tls minVersion %q, expected 1.2 or 1.3", cfg.MinVersion)
+	}
+
+	if err := c.Reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// TLSConfig returns the config of the server, which hands out the latest
+// loaded certificates to every new connection.
+func (c *CertReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: c.minVersion,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return c.state.Load().config, nil
+		},
+	}
+}
+
+// Reload reads the certificate, key and client CAs. On error the previous
+// certificates stay in use.
+func (c *CertReloader) Reload() error {
+	modTimes, err := c.modTimes()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.cfg.CertFile, c.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load tls certificate: %w", err)
+	}
+
+	cfg := &tls.Config{
+		MinVersion:   c.minVersion,
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   c.clientAuth,
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+	if c.cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(c.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read tls client CAs: %w", err)
+		}
+		cfg.ClientCAs = x509.NewCertPool()
+		if !cfg.ClientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in tls client CA file %s", c.cfg.ClientCAFile)
+		}
+	}
+
+	c.state.Store(&certState{config: cfg, modTimes: modTimes})
+	return nil
+}
+
+// Watch reloads the certificates every interval when one of their files
+// changed, until ctx is done. An interval of 0 disables reloading.
+func (c *CertReloader) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !c.changed() {
+				continue
+			}
+			if err := c.Reload(); err != nil {
+				slog.Error("Failed to reload TLS certificates, keeping the previous ones", "error", err)
+				continue
+			}
+			slog.Info("Reloaded TLS certificates", "certFile", c.cfg.CertFile)
+		}
+	}
+}
+
+// changed reports whether a file was modified since it was loaded.
+func (c *CertReloader) changed() bool {
+	modTimes, err := c.modTimes()
+	if err != nil {
+		slog.Error("Failed to check TLS certificates", "error", err)
+		return false
+	}
+	return !slices.EqualFunc(modTimes, c.state.Load().modTimes, time.Time.Equal)
+}
+
+func (c *CertReloader) modTimes() ([]time.Time, error) {
+	var modTimes []time.Time
+	for _, name := range []string{c.cfg.CertFile, c.cfg.KeyFile, c.cfg.ClientCAFile} {
+		if name == "" {
+			continue
+		}
+		info, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		modTimes = append(modTimes, info.ModTime())
+	}
+	return modTimes, nil
+}
@@ -0,0 +1,221 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+	"user-management/internal/config"
+	"user-management/internal/middleware"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultIssuer         = "user-management"
+	defaultAccessTokenTTL = 15 * time.Minute
+
+	// MFA challenge tokens carry their own audience so they are never accepted
+	// as access tokens.
+	mfaChallengeAudience = "mfa-challenge"
+	mfaChallengeTTL      = 5 * time.Minute
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+type Claims struct {
+	jwt.RegisteredClaims
+	Email       string   `json:"email"`
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// Issuer signs and verifies access tokens.
+type Issuer struct {
+	keys   *KeySet
+	issuer string
+	ttl    time.Duration
+}
+
+func NewIssuer(cfg config.Auth) (*Issuer, error) {
+	keys, err := NewKeySet(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer := cfg.Issuer
+	if issuer == "" {
+		issuer = defaultIssuer
+	}
+
+	ttl := cfg.AccessTokenTTL
+	if ttl <= 0 {
+		ttl = defaultAccessTokenTTL
+	}
+
+	return &Issuer{keys: keys, issuer: issuer, ttl: ttl}, nil
+}
+
+// Issue returns a signed access token for the principal and its expiry time.
+// Roles and permissions are embedded, so changes apply once the token is refreshed.
+func (i *Issuer) Issue(principal middleware.Principal) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(i.ttl)
+
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    i.issuer,
+			Subject:   principal.UserID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			ID:        uuid.NewString(),
+		},
+		Email:       principal.Email,
+		Roles:       principal.Roles,
+		Permissions: principal.Permissions,
+	}
+
+	return i.sign(claims, expiresAt)
+}
+
+// Verify parses the access token and checks its signature, issuer and expiry.
+func (i *Issuer) Verify(raw string) (*Claims, error) {
+	var claims Claims
+
+	_, err := jwt.ParseWithClaims(raw, &claims, i.keyFunc,
+		jwt.WithIssuer(i.issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30*time.Second),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	if slices.Contains(claims.Audience, mfaChallengeAudience) {
+		return nil, fmt.Errorf("%w: mfa challenge used as access token", ErrInvalidToken)
+	}
+
+	return &claims, nil
+}
+
+// IssueMfaChallenge returns a short lived token proving the password of the
+// user was checked, to be exchanged for a token pair with a second factor.
+func (i *Issuer) IssueMfaChallenge(userId uuid.UUID) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(mfaChallengeTTL)
+
+	claims := jwt.RegisteredClaims{
+		Issuer:    i.issuer,
+		Subject:   userId.String(),
+		Audience:  jwt.ClaimStrings{mfaChallengeAudience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		ID:        uuid.NewString(),
+	}
+
+	return i.sign(claims, expiresAt)
+}
+
+// MfaChallenge is a login waiting for the second factor.
+type MfaChallenge struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	ExpiresAt time.Time
+}
+
+// VerifyMfaChallenge checks a token from IssueMfaChallenge and returns the
+// challenge.
+func (i *Issuer) VerifyMfaChallenge(raw string) (MfaChallenge, error) {
+	var claims jwt.RegisteredClaims
+
+	_, err := jwt.ParseWithClaims(raw, &claims, i.keyFunc,
+		jwt.WithIssuer(i.issuer),
+		jwt.WithAudience(mfaChallengeAudience),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30*time.Second),
+	)
+	if err != nil {
+		return MfaChallenge{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	userId, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return MfaChallenge{}, fmt.Errorf("%w: invalid subject", ErrInvalidToken)
+	}
+
+	id, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return MfaChallenge{}, fmt.Errorf("%w: invalid id", ErrInvalidToken)
+	}
+
+	return MfaChallenge{ID: id, UserID: userId, ExpiresAt: claims.ExpiresAt.Time}, nil
+}
+
+func (i *Issuer) sign(claims jwt.Claims, expiresAt time.Time) (string, time.Time, error) {
+	key := i.keys.active
+
+	t := jwt.NewWithClaims(key.method, claims)
+	t.Header["kid"] = key.id
+
+	signed, err := t.SignedString(key.signKey)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	return signed, expiresAt, nil
+}
+
+func (i *Issuer) keyFunc(t *jwt.Token) (any, error) {
+	kid, _ := t.Header["kid"].(string)
+
+	key, ok := i.keys.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id: %q", kid)
+	}
+
+	if t.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %s", t.Method.Alg())
+	}
+
+	return key.verifyKey, nil
+}
+
+// Name is the issuer claim of the tokens.
+func (i *Issuer) Name() string {
+	return i.issuer
+}
+
+func (i *Issuer) TTL() time.Duration {
+	return i.ttl
+}
+
+func (i *Issuer) JWKS() JWKSet {
+	return i.keys.JWKS()
+}
+
+func (i *Issuer) Scheme() string {
+	return "Bearer"
+}
+
+func (i *Issuer) Authenticate(ctx context.Context, credential string) (*middleware.Principal, error) {
+	claims, err := i.Verify(credential)
+	if err != nil {
+		return nil, err
+	}
+
+	userId, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid subject", ErrInvalidToken)
+	}
+
+	return &middleware.Principal{
+		UserID:      userId,
+		Email:       claims.Email,
+		Roles:       claims.Roles,
+		Permissions: claims.Permissions,
+	}, nil
+}
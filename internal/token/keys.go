@@ -0,0 +1,171 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"user-management/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+type signingKey struct {
+	id        string
+	method    jwt.SigningMethod
+	signKey   any
+	verifyKey any
+}
+
+// KeySet holds every key tokens may be verified with. Only the active key is
+// used for signing, the others stay around until tokens signed by them expire.
+type KeySet struct {
+	active *signingKey
+	keys   map[string]*signingKey
+}
+
+func NewKeySet(cfg config.Auth) (*KeySet, error) {
+	if len(cfg.SigningKeys) == 0 {
+		slog.Warn("No JWT signing keys configured, generating an ephemeral EdDSA key. Issued tokens will not survive a restart")
+		return ephemeralKeySet()
+	}
+
+	set := &KeySet{keys: make(map[string]*signingKey, len(cfg.SigningKeys))}
+
+	for _, kc := range cfg.SigningKeys {
+		if kc.Id == "" {
+			return nil, fmt.Errorf("signing key without id")
+		}
+		if _, exists := set.keys[kc.Id]; exists {
+			return nil, fmt.Errorf("duplicate signing key id: %s", kc.Id)
+		}
+
+		key, err := loadKey(kc)
+		if err != nil {
+			return nil, fmt.Errorf("signing key %s: %w", kc.Id, err)
+		}
+		set.keys[kc.Id] = key
+	}
+
+	activeId := cfg.ActiveKeyId
+	if activeId == "" {
+		activeId = cfg.SigningKeys[0].Id
+	}
+
+	active, ok := set.keys[activeId]
+	if !ok {
+		return nil, fmt.Errorf("active signing key %s is not configured", activeId)
+	}
+	set.active = active
+
+	return set, nil
+}
+
+func loadKey(kc config.SigningKey) (*signingKey, error) {
+	switch kc.Algorithm {
+	case AlgorithmHS256:
+		if len(kc.Secret) < 32 {
+			return nil, fmt.Errorf("HS256 secret must be at least 32 bytes")
+		}
+		secret := []byte(kc.Secret)
+		return &signingKey{id: kc.Id, method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}, nil
+
+	case AlgorithmRS256:
+		pem, err := os.ReadFile(kc.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		private, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, err
+		}
+		return &signingKey{id: kc.Id, method: jwt.SigningMethodRS256, signKey: private, verifyKey: &private.PublicKey}, nil
+
+	case AlgorithmEdDSA:
+		pem, err := os.ReadFile(kc.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		private, err := jwt.ParseEdPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, err
+		}
+		edPrivate := private.(ed25519.PrivateKey)
+		return &signingKey{id: kc.Id, method: jwt.SigningMethodEdDSA, signKey: edPrivate, verifyKey: edPrivate.Public()}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported algorithm: %s", kc.Algorithm)
+}
+
+func ephemeralKeySet() (*KeySet, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &signingKey{id: "ephemeral", method: jwt.SigningMethodEdDSA, signKey: private, verifyKey: public}
+
+	return &KeySet{active: key, keys: map[string]*signingKey{key.id: key}}, nil
+}
+
+// JWK is a public key in the JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public part of every asymmetric key. HS256 secrets are never published.
+func (s *KeySet) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+
+	for id, key := range s.keys {
+		switch public := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA",
+				Kid: id,
+				Use: "sig",
+				Alg: AlgorithmRS256,
+				N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP",
+				Kid: id,
+				Use: "sig",
+				Alg: AlgorithmEdDSA,
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(public),
+			})
+		}
+	}
+
+	slices.SortFunc(set.Keys, func(a, b JWK) int {
+		return strings.Compare(a.Kid, b.Kid)
+	})
+
+	return set
+}
@@ -0,0 +1,61 @@
+package token
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"user-management/internal/common/converters"
+	"user-management/internal/db/sqlc"
+
+	"github.com/google/uuid"
+)
+
+type Repository struct {
+	queries *sqlc.Queries
+}
+
+func NewRepository(q *sqlc.Queries) *Repository {
+	return &Repository{queries: q}
+}
+
+func (r *Repository) Create(ctx context.Context, userId uuid.UUID, tokenHash string, expiresAt time.Time) (sqlc.RefreshToken, error) {
+
+	params := sqlc.CreateRefreshTokenParams{
+		ID:        uuid.New(),
+		UserID:    userId,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	return r.queries.CreateRefreshToken(ctx, params)
+}
+
+func (r *Repository) GetByHash(ctx context.Context, tokenHash string) (sqlc.RefreshToken, error) {
+	return r.queries.FindRefreshTokenByHash(ctx, tokenHash)
+}
+
+// Revoke revokes a single token for the given reason and reports whether it
+// was still active.
+func (r *Repository) Revoke(ctx context.Context, id uuid.UUID, reason string) (bool, error) {
+
+	params := sqlc.RevokeRefreshTokenParams{
+		RevokedAt:     sql.NullTime{Time: time.Now().UTC(), Valid: true},
+		RevokedReason: converters.NullableString(reason),
+		ID:            id,
+	}
+
+	rows, err := r.queries.RevokeRefreshToken(ctx, params)
+	return rows > 0, err
+}
+
+func (r *Repository) RevokeAllForUser(ctx context.Context, userId uuid.UUID, reason string) error {
+
+	params := sqlc.RevokeUserRefreshTokensParams{
+		RevokedAt:     sql.NullTime{Time: time.Now().UTC(), Valid: true},
+		RevokedReason: converters.NullableString(reason),
+		UserID:        userId,
+	}
+
+	return r.queries.RevokeUserRefreshTokens(ctx, params)
+}
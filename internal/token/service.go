@@ -0,0 +1,150 @@
+package token
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"time"
+	"user-management/internal/common/apperror"
+	"user-management/internal/middleware"
+
+	"github.com/google/uuid"
+)
+
+const defaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+// Reasons a refresh token was revoked for. Only reusing a rotated token is
+// treated as theft, a client retrying with a logged out token is not.
+const (
+	RevokedRotated       = "rotated"
+	RevokedLoggedOut     = "logged_out"
+	RevokedSessionsEnded = "sessions_ended"
+	RevokedReuseDetected = "reuse_detected"
+)
+
+var ErrInvalidRefreshToken = apperror.New(apperror.Unauthorized, "invalid_refresh_token", "invalid refresh token")
+
+// Service issues access and refresh token pairs. Refresh tokens are opaque,
+// single-use and only stored as SHA-256 hashes.
+type Service struct {
+	issuer     *Issuer
+	repo       *Repository
+	refreshTTL time.Duration
+}
+
+func NewService(issuer *Issuer, repo *Repository, refreshTTL time.Duration) *Service {
+	if refreshTTL <= 0 {
+		refreshTTL = defaultRefreshTokenTTL
+	}
+	return &Service{issuer: issuer, repo: repo, refreshTTL: refreshTTL}
+}
+
+func (s *Service) Issue(ctx context.Context, principal middleware.Principal) (TokenPair, error) {
+	accessToken, _, err := s.issuer.Issue(principal)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	refreshToken, err := generateRefreshToken()
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	expiresAt := time.Now().UTC().Add(s.refreshTTL)
+	if _, err := s.repo.Create(ctx, principal.UserID, hashRefreshToken(refreshToken), expiresAt); err != nil {
+		return TokenPair{}, err
+	}
+
+	return TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.issuer.TTL().Seconds()),
+	}, nil
+}
+
+// Consume revokes the refresh token and returns the user it was issued to.
+// Presenting a token that was already exchanged revokes every token of the
+// user, as it means the token was leaked and used twice. Tokens revoked for
+// any other reason are only rejected.
+func (s *Service) Consume(ctx context.Context, refreshToken string) (uuid.UUID, error) {
+	stored, err := s.repo.GetByHash(ctx, hashRefreshToken(refreshToken))
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if stored.RevokedAt.Valid {
+		if stored.RevokedReason.String != RevokedRotated {
+			return uuid.Nil, ErrInvalidRefreshToken
+		}
+		slog.WarnContext(ctx, "Rotated refresh token reused, revoking all sessions", "userId", stored.UserID)
+		if err := s.repo.RevokeAllForUser(ctx, stored.UserID, RevokedReuseDetected); err != nil {
+			return uuid.Nil, err
+		}
+		return uuid.Nil, ErrInvalidRefreshToken
+	}
+
+	if time.Now().UTC().After(stored.ExpiresAt) {
+		return uuid.Nil, ErrInvalidRefreshToken
+	}
+
+	revoked, err := s.repo.Revoke(ctx, stored.ID, RevokedRotated)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !revoked {
+		return uuid.Nil, ErrInvalidRefreshToken
+	}
+
+	return stored.UserID, nil
+}
+
+func (s *Service) Revoke(ctx context.Context, refreshToken string) error {
+	stored, err := s.repo.GetByHash(ctx, hashRefreshToken(refreshToken))
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = s.repo.Revoke(ctx, stored.ID, RevokedLoggedOut)
+	return err
+}
+
+func (s *Service) IssueMfaChallenge(userId uuid.UUID) (string, int, error) {
+	raw, _, err := s.issuer.IssueMfaChallenge(userId)
+	if err != nil {
+		return "", 0, err
+	}
+	return raw, int(mfaChallengeTTL.Seconds()), nil
+}
+
+func (s *Service) VerifyMfaChallenge(raw string) (MfaChallenge, error) {
+	return s.issuer.VerifyMfaChallenge(raw)
+}
+
+func (s *Service) RevokeAll(ctx context.Context, userId uuid.UUID) error {
+	return s.repo.RevokeAllForUser(ctx, userId, RevokedSessionsEnded)
+}
+
+func generateRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,12 @@
+package token
+
+type TokenPair struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	TokenType    string `json:"tokenType"`
+	ExpiresIn    int    `json:"expiresIn"`
+
+	// MfaEnrollmentRequired is set when a role of the user requires MFA but it
+	// is not enabled yet. The access token then carries no permissions.
+	MfaEnrollmentRequired bool `json:"mfaEnrollmentRequired,omitempty"`
+}
@@ -0,0 +1,42 @@
+// Package transfer reads and writes the files of the import and export
+// commands and the export endpoints. Files are CSV with a header row or
+// newline delimited JSON, with one object per line. Exports can also be
+// written as a JSON array. All formats use the same column names.
+package transfer
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Format is the file format of an import or export.
+type Format string
+
+const (
+	CSV    Format = "csv"
+	NDJSON Format = "ndjson"
+	// JSON is an array of objects. It can only be written.
+	JSON Format = "json"
+)
+
+// ParseFormat returns the format named by name or, when name is empty, the
+// format of the extension of path.
+func ParseFormat(name string, path string) (Format, error) {
+	if name == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".csv":
+			return CSV, nil
+		case ".ndjson", ".jsonl":
+			return NDJSON, nil
+		}
+		return "", fmt.Errorf("cannot tell the format of %q, set --format to csv or ndjson", path)
+	}
+
+	switch f := Format(strings.ToLower(name)); f {
+	case CSV, NDJSON:
+		return f, nil
+	default:
+		return "", fmt.Errorf("invalid format %q, expected csv or ndjson", name)
+	}
+}
@@ -0,0 +1,212 @@
+package transfer
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"user-management/internal/common/apperror"
+)
+
+// Action is what an import did with a record.
+type Action string
+
+const (
+	Created   Action = "created"
+	Updated   Action = "updated"
+	Unchanged Action = "unchanged"
+	Rejected  Action = "rejected"
+)
+
+// progressEvery is the number of records between progress reports.
+const progressEvery = 1000
+
+// ErrInvalidRecord is returned for records with invalid values, which are
+// listed in its Fields.
+var ErrInvalidRecord = apperror.New(apperror.Validation, "invalid_record", "invalid record")
+
+// Summary counts the records of an import by what was done with them.
+type Summary struct {
+	Records int
+	Actions map[Action]int
+}
+
+func (s Summary) String() string {
+	return fmt.Sprintf("%d records: %d created, %d updated, %d unchanged, %d rejected",
+		s.Records, s.Actions[Created], s.Actions[Updated], s.Actions[Unchanged], s.Actions[Rejected])
+}
+
+// Import reads the records of r and applies each with apply. Records that
+// cannot be read and records apply fails with a domain error are rejected
+// and written to rejects, which may be nil. Any other error stops the
+// import. progress is called every 1000 records.
+func Import(ctx context.Context, r Reader, rejects *Rejects, apply func(ctx context.Context, rec Record) (Action, error), progress func(Summary)) (Summary, error) {
+	summary := Summary{Actions: make(map[Action]int)}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return summary, nil
+		}
+		if err != nil {
+			return summary, err
+		}
+		summary.Records++
+
+		action := Rejected
+		err = rec.Err
+		if err == nil {
+			action, err = apply(ctx, rec)
+		}
+		if err != nil && apperror.KindOf(err) == apperror.Internal && rec.Err == nil {
+			return summary, fmt.Errorf("line %d: %w", rec.Line, err)
+		}
+		if err != nil {
+			action = Rejected
+			if rejects != nil {
+				if err := rejects.Write(r.Header(), rec, err); err != nil {
+					return summary, fmt.Errorf("failed to write rejected record: %w", err)
+				}
+			}
+		}
+		summary.Actions[action]++
+
+		if progress != nil && summary.Records%progressEvery == 0 {
+			progress(summary)
+		}
+	}
+}
+
+// Describe returns the reason a record was rejected for, listing the invalid
+// fields of validation errors.
+func Describe(err error) string {
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) {
+		return err.Error()
+	}
+	if len(appErr.Fields) == 0 {
+		return appErr.Message
+	}
+
+	fields := make([]string, len(appErr.Fields))
+	for i, f := range appErr.Fields {
+		fields[i] = f.Field + ": " + f.Message
+	}
+	return strings.Join(fields, "; ")
+}
+
+// Rejects writes the rejected records of an import in the format of the
+// imported file. CSV rows keep their columns and get the line and error
+// columns appended, NDJSON lines are objects with the line, the error and
+// the record.
+type Rejects struct {
+	format  Format
+	w       io.Writer
+	csv     *csv.Writer
+	started bool
+}
+
+// NewRejects returns a report of rejected records written to w.
+func NewRejects(format Format, w io.Writer) *Rejects {
+	rj := &Rejects{format: format, w: w}
+	if format == CSV {
+		rj.csv = csv.NewWriter(w)
+	}
+	return rj
+}
+
+// Write adds a rejected record. header is the header of the imported file.
+func (rj *Rejects) Write(header []string, rec Record, err error) error {
+	if rj.format == CSV {
+		if !rj.started {
+			rj.started = true
+			if err := rj.csv.Write(append(append([]string{}, header...), "line", "error")); err != nil {
+				return err
+			}
+		}
+		row := make([]string, 0, len(header)+2)
+		for _, name := range header {
+			row = append(row, rec.Values[name])
+		}
+		return rj.csv.Write(append(row, strconv.Itoa(rec.Line), Describe(err)))
+	}
+
+	record := json.RawMessage(rec.Raw)
+	if !json.Valid(rec.Raw) {
+		record, _ = json.Marshal(string(rec.Raw))
+	}
+	return json.NewEncoder(rj.w).Encode(struct {
+		Line   int             `json:"line"`
+		Error  string          `json:"error"`
+		Record json.RawMessage `json:"record"`
+	}{rec.Line, Describe(err), record})
+}
+
+// Flush writes buffered rows.
+func (rj *Rejects) Flush() error {
+	if rj.csv == nil {
+		return nil
+	}
+	rj.csv.Flush()
+	return rj.csv.Error()
+}
+
+// Fields reads typed values from a record and collects the errors of values
+// that cannot be converted.
+type Fields struct {
+	values map[string]string
+	errs   []apperror.FieldError
+}
+
+// NewFields returns the fields of rec.
+func NewFields(rec Record) *Fields {
+	return &Fields{values: rec.Values}
+}
+
+// String returns the value of the column, empty when it is missing.
+func (f *Fields) String(name string) string {
+	return f.values[name]
+}
+
+// Int16 returns the value of the column as a number, 0 when it is empty.
+func (f *Fields) Int16(name string) int16 {
+	v := f.values[name]
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.ParseInt(v, 10, 16)
+	if err != nil {
+		f.errs = append(f.errs, apperror.FieldError{Field: name, Message: "must be a whole number"})
+	}
+	return int16(n)
+}
+
+// Float64 returns the value of the column as a number, 0 when it is empty.
+func (f *Fields) Float64(name string) float64 {
+	v := f.values[name]
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		f.errs = append(f.errs, apperror.FieldError{Field: name, Message: "must be a number"})
+	}
+	return n
+}
+
+// Err returns ErrInvalidRecord listing the values that could not be
+// converted, or nil.
+func (f *Fields) Err() error {
+	if len(f.errs) == 0 {
+		return nil
+	}
+	return ErrInvalidRecord.WithFields(f.errs...)
+}
@@ -0,0 +1,141 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Record is a row of an imported file. Values are keyed by column name, Err
+// is set for rows that cannot be read, which are rejected without stopping
+// the import.
+type Record struct {
+	Line   int
+	Values map[string]string
+	Raw    []byte
+	Err    error
+}
+
+// Reader reads the records of an imported file. Read returns io.EOF after
+// the last record, any other error stops the import.
+type Reader interface {
+	Read() (Record, error)
+	// Header returns the columns of the file in their order, nil when the
+	// format has no header.
+	Header() []string
+}
+
+// NewReader returns a reader of the records of r in the given format.
+func NewReader(format Format, r io.Reader) Reader {
+	if format == NDJSON {
+		return &ndjsonReader{r: bufio.NewReader(r)}
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	return &csvReader{r: cr}
+}
+
+type csvReader struct {
+	r      *csv.Reader
+	header []string
+}
+
+func (c *csvReader) Header() []string {
+	return c.header
+}
+
+func (c *csvReader) Read() (Record, error) {
+	if c.header == nil {
+		header, err := c.r.Read()
+		if err != nil {
+			return Record{}, fmt.Errorf("failed to read the header: %w", err)
+		}
+		for i, name := range header {
+			// Spreadsheets often start the file with a byte order mark.
+			header[i] = strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF"))
+		}
+		c.header = header
+	}
+
+	row, err := c.r.Read()
+	if err != nil {
+		return Record{}, err
+	}
+	line, _ := c.r.FieldPos(0)
+
+	rec := Record{Line: line, Values: make(map[string]string, len(c.header))}
+	if len(row) > len(c.header) {
+		rec.Err = fmt.Errorf("row has %d fields, the header has %d", len(row), len(c.header))
+	}
+	for i, v := range row {
+		if i < len(c.header) {
+			rec.Values[c.header[i]] = strings.TrimSpace(v)
+		}
+	}
+	return rec, nil
+}
+
+type ndjsonReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+func (n *ndjsonReader) Header() []string {
+	return nil
+}
+
+func (n *ndjsonReader) Read() (Record, error) {
+	for {
+		raw, err := n.r.ReadBytes('\n')
+		if err != nil && (!errors.Is(err, io.EOF) || len(raw) == 0) {
+			return Record{}, err
+		}
+		n.line++
+
+		raw = bytes.TrimSpace(raw)
+		if len(raw) == 0 {
+			continue
+		}
+
+		rec := Record{Line: n.line, Raw: raw}
+		rec.Values, rec.Err = decodeObject(raw)
+		return rec, nil
+	}
+}
+
+// decodeObject reads a JSON object of scalar values into strings, numbers
+// keep the text they were written with.
+func decodeObject(raw []byte) (map[string]string, error) {
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+
+	var object map[string]any
+	if err := d.Decode(&object); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	values := make(map[string]string, len(object))
+	for k, v := range object {
+		switch v := v.(type) {
+		case nil:
+			values[k] = ""
+		case string:
+			values[k] = strings.TrimSpace(v)
+		case json.Number:
+			values[k] = v.String()
+		case bool:
+			values[k] = strconv.FormatBool(v)
+		default:
+			return nil, fmt.Errorf("%s must be a string, number or boolean", k)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,97 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+	"user-management/internal/common/apperror"
+	httputils "user-management/internal/common/httputils"
+)
+
+// ErrUnknownColumn is returned for exports selecting a column that does not
+// exist.
+var ErrUnknownColumn = apperror.New(apperror.Validation, "unknown_column", "unknown column")
+
+// mediaTypes are the media types of the formats in the order they are
+// offered to clients, the first one is used when the client accepts any.
+var mediaTypes = []struct {
+	mediaType string
+	format    Format
+}{
+	{"application/json", JSON},
+	{"text/csv", CSV},
+	{"application/x-ndjson", NDJSON},
+}
+
+// ServeExport streams the rows export passes to its callback in the format
+// the Accept header selects: JSON, CSV or NDJSON. The columns query
+// parameter selects the columns. The response is named name in the
+// Content-Disposition header, with the extension of the format.
+//
+// Rows are written as they are read, so exports are not limited by the
+// server write timeout, they stop when the client goes away. An error
+// after the first row cannot change the status anymore and only cuts the
+// response short.
+func ServeExport[T any](w http.ResponseWriter, r *http.Request, name string, columns []Column[T], export func(ctx context.Context, fn func(T) error) error) {
+	selected, err := SelectColumns(columns, r.URL.Query().Get("columns"))
+	if err != nil {
+		httputils.WriteProblem(w, r, ErrUnknownColumn.WithMessage(err.Error()))
+		return
+	}
+
+	offers := make([]string, len(mediaTypes))
+	for i, mt := range mediaTypes {
+		offers[i] = mt.mediaType
+	}
+	mediaType, ok := httputils.Negotiate(r, offers...)
+	w.Header().Add("Vary", "Accept")
+	if !ok {
+		httputils.WriteError(w, http.StatusNotAcceptable, "Exports are available as application/json, text/csv and application/x-ndjson", r)
+		return
+	}
+
+	var format Format
+	for _, mt := range mediaTypes {
+		if mt.mediaType == mediaType {
+			format = mt.format
+		}
+	}
+
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		slog.WarnContext(r.Context(), "Failed to clear the write deadline of the export", "path", r.URL.Path, "error", err)
+	}
+
+	tw := NewWriter(format, w, selected)
+	started := false
+	start := func() {
+		started = true
+		w.Header().Set("Content-Type", mediaType+"; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"."+string(format)))
+		w.WriteHeader(http.StatusOK)
+	}
+
+	err = export(r.Context(), func(v T) error {
+		if !started {
+			start()
+		}
+		return tw.Write(v)
+	})
+	if err != nil && !started {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Export failed", "path", r.URL.Path, "error", err)
+		return
+	}
+
+	if !started {
+		start()
+	}
+	if err := tw.Close(); err != nil {
+		slog.ErrorContext(r.Context(), "Export failed", "path", r.URL.Path, "error", err)
+	}
+}
@@ -0,0 +1,167 @@
+package transfer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Column is an exported column of T. Value returns the value of the column,
+// nil for an empty value.
+type Column[T any] struct {
+	Name  string
+	Value func(T) any
+}
+
+// Writer writes rows of T. CSV files start with a header of the column
+// names, NDJSON lines and the elements of JSON arrays are objects with the
+// columns in their order.
+type Writer[T any] struct {
+	format  Format
+	columns []Column[T]
+	csv     *csv.Writer
+	w       io.Writer
+	started bool
+}
+
+// SelectColumns returns the columns named in the comma separated list, in
+// its order, or all columns when the list is empty.
+func SelectColumns[T any](columns []Column[T], list string) ([]Column[T], error) {
+	if strings.TrimSpace(list) == "" {
+		return columns, nil
+	}
+
+	var selected []Column[T]
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		i := slices.IndexFunc(columns, func(c Column[T]) bool { return c.Name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown column %q, expected one of %s", name, strings.Join(Names(columns), ", "))
+		}
+		selected = append(selected, columns[i])
+	}
+	return selected, nil
+}
+
+// NewWriter returns a writer of the columns to w in the given format.
+func NewWriter[T any](format Format, w io.Writer, columns []Column[T]) *Writer[T] {
+	tw := &Writer[T]{format: format, columns: columns, w: w}
+	if format == CSV {
+		tw.csv = csv.NewWriter(w)
+	}
+	return tw
+}
+
+// Write writes a row. The CSV header is written before the first row.
+func (tw *Writer[T]) Write(v T) error {
+	first := !tw.started
+	tw.started = true
+
+	if tw.format == CSV {
+		if first {
+			if err := tw.csv.Write(Names(tw.columns)); err != nil {
+				return err
+			}
+		}
+		row := make([]string, len(tw.columns))
+		for i, c := range tw.columns {
+			row[i] = FormatValue(c.Value(v))
+		}
+		return tw.csv.Write(row)
+	}
+
+	var buf bytes.Buffer
+	if tw.format == JSON {
+		if first {
+			buf.WriteByte('[')
+		} else {
+			buf.WriteByte(',')
+		}
+	}
+	buf.WriteByte('{')
+	for i, c := range tw.columns {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(c.Name)
+		value, err := json.Marshal(c.Value(v))
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", c.Name, err)
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	if tw.format == NDJSON {
+		buf.WriteByte('\n')
+	}
+	_, err := tw.w.Write(buf.Bytes())
+	return err
+}
+
+// Close finishes the output. It writes buffered rows, the CSV header when no
+// row was written and the end of a JSON array. It does not close the
+// underlying writer.
+func (tw *Writer[T]) Close() error {
+	switch tw.format {
+	case CSV:
+		if !tw.started {
+			tw.started = true
+			tw.csv.Write(Names(tw.columns))
+		}
+		tw.csv.Flush()
+		return tw.csv.Error()
+	case JSON:
+		end := "]\n"
+		if !tw.started {
+			end = "[]\n"
+		}
+		_, err := io.WriteString(tw.w, end)
+		return err
+	}
+	return nil
+}
+
+// Names returns the names of the columns.
+func Names[T any](columns []Column[T]) []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// FormatValue formats a column value for CSV. Times are written as RFC 3339.
+func FormatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return v.Format(time.RFC3339Nano)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case *uuid.UUID:
+		if v == nil {
+			return ""
+		}
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,113 @@
+package user
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"user-management/internal/db/query"
+	"user-management/internal/db/sqlc"
+)
+
+// sortFields are the fields users can be sorted by.
+var sortFields = map[string]query.SortField{
+	"firstName": {Column: "FIRST_NAME", Type: "text"},
+	"lastName":  {Column: "LAST_NAME", Type: "text"},
+	"email":     {Column: "EMAIL", Type: "text"},
+	"age":       {Column: "AGE", Type: "smallint"},
+	"status":    {Column: "STATUS", Type: "text"},
+}
+
+var defaultSort = query.Sort{
+	{Name: "lastName", Column: "LAST_NAME", Type: "text"},
+	{Name: "firstName", Column: "FIRST_NAME", Type: "text"},
+}
+
+// tiebreaker makes the order of users total, so pages are stable.
+var tiebreaker = query.SortField{Name: "id", Column: "USER_ID", Type: "uuid"}
+
+// Filter narrows and orders the user list. Empty fields match every user,
+// the age range is inclusive.
+type Filter struct {
+	Statuses    []UserStatus
+	EmailDomain string
+	MinAge      *int16
+	MaxAge      *int16
+	NamePrefix  string
+	Query       string
+	Sort        query.Sort
+
+	IncludeDeleted bool
+}
+
+// ParseFilter reads the status, email_domain, min_age, max_age, name_prefix,
+// q and sort query parameters. status can be repeated or comma separated.
+func ParseFilter(q url.Values) (Filter, error) {
+	f := Filter{
+		EmailDomain: strings.TrimPrefix(q.Get("email_domain"), "@"),
+		NamePrefix:  q.Get("name_prefix"),
+		Query:       q.Get("q"),
+	}
+
+	for _, value := range q["status"] {
+		for _, s := range strings.Split(value, ",") {
+			status, err := ParseUserStatus(strings.TrimSpace(s))
+			if err != nil {
+				return Filter{}, query.ErrInvalidFilter.WithMessage(fmt.Sprintf("invalid status %q", strings.TrimSpace(s)))
+			}
+			f.Statuses = append(f.Statuses, status)
+		}
+	}
+
+	var err error
+	if f.MinAge, err = parseAge(q, "min_age"); err != nil {
+		return Filter{}, err
+	}
+	if f.MaxAge, err = parseAge(q, "max_age"); err != nil {
+		return Filter{}, err
+	}
+	if f.MinAge != nil && f.MaxAge != nil && *f.MinAge > *f.MaxAge {
+		return Filter{}, query.ErrInvalidFilter.WithMessage("min_age must not be greater than max_age")
+	}
+	if f.Sort, err = query.ParseSort(q.Get("sort"), sortFields, defaultSort); err != nil {
+		return Filter{}, err
+	}
+
+	return f, nil
+}
+
+func parseAge(q url.Values, name string) (*int16, error) {
+	v := q.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	age, err := strconv.ParseInt(v, 10, 16)
+	if err != nil || age < 0 {
+		return nil, query.ErrInvalidFilter.WithMessage(fmt.Sprintf("invalid %s, expected a positive number", name))
+	}
+	a := int16(age)
+	return &a, nil
+}
+
+// sortValues returns the values of the user for each of the keys, formatted
+// for a cursor.
+func sortValues(u sqlc.User, keys query.Sort) []string {
+	values := make([]string, len(keys))
+	for i, f := range keys {
+		switch f.Name {
+		case "firstName":
+			values[i] = u.FirstName
+		case "lastName":
+			values[i] = u.LastName
+		case "email":
+			values[i] = u.Email
+		case "age":
+			values[i] = strconv.Itoa(int(u.Age))
+		case "status":
+			values[i] = u.Status
+		case tiebreaker.Name:
+			values[i] = u.UserID.String()
+		}
+	}
+	return values
+}
@@ -1,70 +1,133 @@
 package user
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"log/slog"
 	"net/http"
+	"user-management/internal/batch"
 	httputils "user-management/internal/common/httputils"
+	"user-management/internal/db/query"
 	"user-management/internal/middleware"
+	"user-management/internal/rbac"
+	"user-management/internal/transfer"
 
 	"github.com/go-playground/validator/v10"
 )
 
+// EmailVerifier sends the verification mail to users created as
+// PendingVerification.
+type EmailVerifier interface {
+	SendVerification(ctx context.Context, u User) error
+}
+
 type Handler struct {
 	service  *Service
 	validate *validator.Validate
+	verifier EmailVerifier
+
+	maxBatchOperations int
 }
 
-func NewHandler(service *Service, validate *validator.Validate) *Handler {
+// NewHandler creates the user handler. Batches can have at most
+// maxBatchOperations operations.
+func NewHandler(service *Service, validate *validator.Validate, verifier EmailVerifier, maxBatchOperations int) *Handler {
 	return &Handler{
 		service:  service,
 		validate: validate,
+		verifier: verifier,
+
+		maxBatchOperations: maxBatchOperations,
 	}
 }
 
 // CreateUser godoc
 // @Summary Create a new user
-// @Description Create a new user
+// @Description Create a new user. The user starts as Active, or as PendingVerification when email verification is required and is then mailed a verification link. A status in the body is rejected with status_not_settable, it is changed afterwards with PATCH /users/{id} or the status endpoints
 // @Tags users
 // @Accept  json
 // @Produce  json
+// @Param Idempotency-Key header string false "Key making retries of the request safe, at most 255 characters"
 // @Success 200 {object} User
-// @Failure      400  {object}  httputils.ErrorResponse
-// @Failure      500  {object}  httputils.ErrorResponse
+// @Header 200 {string} Idempotent-Replayed "Set when the response is replayed for a retried Idempotency-Key"
+// @Failure      400  {object}  httputils.Problem
+// @Failure      409  {object}  httputils.Problem
+// @Failure      422  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /users [post]
 func (h *Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
 
 	defer r.Body.Close()
 
-	var req User
+	var req UserCreateRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		slog.Warn("Invalid request", "error", r)
-		httputils.WriteError(w, http.StatusBadRequest, "Invalid request", r)
+		slog.WarnContext(r.Context(), "Invalid request", "error", err)
+		httputils.WriteProblem(w, r, httputils.ErrInvalidRequest.Wrap(err))
 		return
 	}
 
-	if err := h.validate.Struct(req); err != nil {
-		details := httputils.ConvertValidationErrors(err)
-		slog.Warn("User update failed", "error", "Validation failed")
-		httputils.WriteDetailedError(w, http.StatusBadRequest, "Validation failed", details, r)
+	if err := httputils.Validate(&req, h.validate); err != nil {
+		slog.WarnContext(r.Context(), "User creation failed", "error", err)
+		httputils.WriteProblem(w, r, err)
 		return
 	}
 
 	user, err := h.service.CreateUser(r.Context(), &req)
 
 	if err != nil {
-		slog.Error("Failed to create user", "error", r)
-		httputils.WriteError(w, http.StatusInternalServerError, "Failed to create user", r)
+		httputils.WriteProblem(w, r, err)
 		return
 	}
 
+	h.sendVerification(r.Context(), user)
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(user)
 }
 
+func (h *Handler) sendVerification(ctx context.Context, user User) {
+	if user.Status == PendingVerification && h.verifier != nil {
+		if err := h.verifier.SendVerification(ctx, user); err != nil {
+			slog.ErrorContext(ctx, "Failed to send verification mail", "userId", user.UserId, "error", err)
+		}
+	}
+}
+
+// BatchUsers godoc
+// @Summary Create, update and delete users in a batch
+// @Description Apply up to batch.maxOperations operations, deletes require the delete permission. The data of create operations is a user create request, of update operations a user update request. Creates are applied first, with a single insert, then the other operations in their order.
+// @Description In transactional mode, the default, all operations are applied or none: invalid operations and the first failing operation reject the batch with a problem listing the fields of the operation, for example operations[2].Email.
+// @Description In best_effort mode each operation is applied on its own and the result of every operation reports its status and problem.
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Param Idempotency-Key header string false "Key making retries of the request safe, at most 255 characters"
+// @Param request body batch.Request true "Operations of the batch"
+// @Success 200 {object} batch.Response
+// @Failure      400  {object}  httputils.Problem
+// @Failure      404  {object}  httputils.Problem
+// @Failure      409  {object}  httputils.Problem
+// @Failure      412  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users:batch [post]
+func (h *Handler) BatchUsers(w http.ResponseWriter, r *http.Request) {
+	batch.Serve(w, r, h.validate, h.maxBatchOperations, rbac.PermUsersDelete, func(ctx context.Context, mode batch.Mode, items []BatchItem) ([]batch.Outcome, error) {
+		outcomes, err := h.service.Batch(ctx, mode, items)
+		for _, o := range outcomes {
+			if user, ok := o.Value.(User); ok && o.Op == batch.Create {
+				h.sendVerification(ctx, user)
+			}
+		}
+		return outcomes, err
+	})
+}
+
 // GetUserById godoc
 // @Summary Get user by id
 // @Description Get user details by id
@@ -73,22 +136,38 @@ func (h *Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
 // @Produce  json
 // @Success 200 {object} User
 // @Param id path string true "User ID"
-// @Failure      400  {object}  httputils.ErrorResponse
-// @Failure      404  {object}  httputils.ErrorResponse
-// @Failure      500  {object}  httputils.ErrorResponse
+// @Param include_deleted query bool false "Also find soft deleted users, requires users:delete"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Header 200 {string} ETag "Version of the user"
+// @Success 304
+// @Failure      400  {object}  httputils.Problem
+// @Failure      403  {object}  httputils.Problem
+// @Failure      404  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /users/{id} [get]
 func (h *Handler) GetUserById(w http.ResponseWriter, r *http.Request) {
 
 	userId, uuiderr := httputils.ParseUUIDFromURL(r, "id")
 	if uuiderr != nil {
-		http.Error(w, "Invalid user ID format", http.StatusBadRequest)
+		httputils.WriteError(w, http.StatusBadRequest, "Invalid user ID format", r)
 		return
 	}
 
-	users, err := h.service.GetUserById(r.Context(), userId.String())
+	var users User
+	var err error
+	if includeDeleted, _ := r.Context().Value(middleware.IncludeDeletedKey).(bool); includeDeleted {
+		users, err = h.service.GetUserByIdIncludingDeleted(r.Context(), userId.String())
+	} else {
+		users, err = h.service.GetUserById(r.Context(), userId.String())
+	}
 	if err != nil {
-		slog.Warn(fmt.Sprintf("User not found with id: %s", userId))
-		httputils.WriteError(w, http.StatusNotFound, "User not found", r)
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	if httputils.NotModified(w, r, httputils.ETag(users.Version)) {
 		return
 	}
 
@@ -105,90 +184,381 @@ func (h *Handler) GetUserById(w http.ResponseWriter, r *http.Request) {
 // @Produce  json
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(10)
+// @Param include_deleted query bool false "Also list soft deleted users, requires users:delete"
+// @Param status query []string false "Only users with one of the statuses" collectionFormat(csv)
+// @Param email_domain query string false "Only users with an email address at the domain"
+// @Param min_age query int false "Minimum age, inclusive"
+// @Param max_age query int false "Maximum age, inclusive"
+// @Param name_prefix query string false "Only users whose first or last name starts with the prefix, case insensitive"
+// @Param q query string false "Case insensitive search in first name, last name and email"
+// @Param sort query string false "Comma separated fields out of firstName, lastName, email, age and status, prefixed with - for descending order" default(lastName,firstName)
+// @Param cursor query string false "Cursor of the page to fetch, taken from the Link header of the previous response"
+// @Param count query string false "Report the total number of matching users" Enums(exact, estimated)
 // @Success 200 {array} User
-// @Failure      404  {object}  httputils.ErrorResponse
-// @Failure      500  {object}  httputils.ErrorResponse
+// @Header 200 {string} Link "Links to the next and previous page"
+// @Header 200 {integer} X-Total-Count "Number of matching users, with count=exact"
+// @Header 200 {integer} X-Total-Count-Estimate "Estimated number of matching users, with count=estimated"
+// @Failure      400  {object}  httputils.Problem
+// @Failure      403  {object}  httputils.Problem
+// @Failure      404  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /users [get]
 func (h *Handler) GetUsers(w http.ResponseWriter, r *http.Request) {
+	pageReq := r.Context().Value(middleware.PageRequestKey).(query.PageRequest)
 
-	page := r.Context().Value(middleware.PageKey).(int)
-	limit := r.Context().Value(middleware.LimitKey).(int)
-	offset := (page - 1) * limit
+	filter, err := ParseFilter(r.URL.Query())
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+	filter.IncludeDeleted, _ = r.Context().Value(middleware.IncludeDeletedKey).(bool)
 
-	users, err := h.service.ListUsersPaged(r.Context(), limit, offset)
+	result, err := h.service.ListUsersPaged(r.Context(), filter, pageReq)
 	if err != nil {
-		slog.Warn("Failed to fetch users")
-		httputils.WriteError(w, http.StatusNotFound, "Failed to fetch users", r)
+		httputils.WriteProblem(w, r, err)
 		return
 	}
 
+	httputils.WritePageHeaders(w, r, result.PageInfo)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(users)
+	json.NewEncoder(w).Encode(result.Items)
+}
+
+// ExportUsers godoc
+// @Summary Export users
+// @Description Stream every user matching the filter, in the format selected by the Accept header. The columns are id, firstName, lastName, email, phone, age, status, mfaEnabled, version and deletedAt
+// @Tags users
+// @Produce  json
+// @Produce  text/csv
+// @Produce  application/x-ndjson
+// @Param columns query string false "Comma separated columns to export, all columns when unset"
+// @Param include_deleted query bool false "Also export soft deleted users, requires users:delete"
+// @Param status query []string false "Only users with one of the statuses" collectionFormat(csv)
+// @Param email_domain query string false "Only users with an email address at the domain"
+// @Param min_age query int false "Minimum age, inclusive"
+// @Param max_age query int false "Maximum age, inclusive"
+// @Param name_prefix query string false "Only users whose first or last name starts with the prefix, case insensitive"
+// @Param q query string false "Case insensitive search in first name, last name and email"
+// @Param sort query string false "Comma separated fields out of firstName, lastName, email, age and status, prefixed with - for descending order" default(lastName,firstName)
+// @Success 200 {array} User
+// @Header 200 {string} Content-Disposition "Attachment named users.json, users.csv or users.ndjson"
+// @Failure      400  {object}  httputils.Problem
+// @Failure      403  {object}  httputils.Problem
+// @Failure      406  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users/export [get]
+func (h *Handler) ExportUsers(w http.ResponseWriter, r *http.Request) {
+	filter, err := ParseFilter(r.URL.Query())
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+	filter.IncludeDeleted, _ = r.Context().Value(middleware.IncludeDeletedKey).(bool)
+
+	transfer.ServeExport(w, r, "users", ExportColumns, func(ctx context.Context, fn func(User) error) error {
+		return h.service.ExportUsers(ctx, filter, fn)
+	})
 }
 
 // UpdateUserById godoc
 // @Summary Update user by id
-// @Description Update an user by id
+// @Description Update an user by id. A status change must be allowed by the status transition table. Changing the email of another user requires every permission the user holds
 // @Tags users
 // @Accept  json
 // @Produce  json
 // @Param id path string true "User ID"
+// @Param If-Match header string false "ETag of the user, required when server.requireIfMatch is set"
 // @Success 200 {object} User
-// @Failure      400  {object}  httputils.ErrorResponse
-// @Failure      404  {object}  httputils.ErrorResponse
-// @Failure      500  {object}  httputils.ErrorResponse
+// @Header 200 {string} ETag "Version of the updated user"
+// @Failure      400  {object}  httputils.Problem
+// @Failure      403  {object}  httputils.Problem
+// @Failure      404  {object}  httputils.Problem
+// @Failure      409  {object}  httputils.Problem
+// @Failure      412  {object}  httputils.Problem
+// @Failure      428  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /users/{id} [patch]
 func (h *Handler) UpdateUserById(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
 	userId, uuiderr := httputils.ParseUUIDFromURL(r, "id")
 	if uuiderr != nil {
-		http.Error(w, "Invalid user ID format", http.StatusNotFound)
+		httputils.WriteError(w, http.StatusBadRequest, "Invalid user ID format", r)
 		return
 	}
 
 	var req UserUpdateRequest
 	if err := httputils.DecodeAndValidateRequest(r, &req, h.validate); err != nil {
-		slog.Warn("User update failed", "error", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		slog.WarnContext(r.Context(), "User update failed", "error", err)
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	version := r.Context().Value(middleware.IfMatchKey).(int64)
+	updatedUser, err := h.service.UpdateUser(r.Context(), userId.String(), &req, version)
+
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", httputils.ETag(updatedUser.Version))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updatedUser)
+}
+
+// SuspendUser godoc
+// @Summary Suspend user
+// @Description Suspend an user. Suspended users cannot log in or use their API keys
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Param id path string true "User ID"
+// @Param request body UserStatusChangeRequest true "Reason for the change"
+// @Success 200 {object} User
+// @Header 200 {string} ETag "Version of the updated user"
+// @Failure      400  {object}  httputils.Problem
+// @Failure      404  {object}  httputils.Problem
+// @Failure      409  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users/{id}/suspend [post]
+func (h *Handler) SuspendUser(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, Suspended)
+}
+
+// LockUser godoc
+// @Summary Lock user
+// @Description Lock an user, for example after suspicious activity. Locked users cannot log in or use their API keys
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Param id path string true "User ID"
+// @Param request body UserStatusChangeRequest true "Reason for the change"
+// @Success 200 {object} User
+// @Header 200 {string} ETag "Version of the updated user"
+// @Failure      400  {object}  httputils.Problem
+// @Failure      404  {object}  httputils.Problem
+// @Failure      409  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users/{id}/lock [post]
+func (h *Handler) LockUser(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, Locked)
+}
+
+// ReactivateUser godoc
+// @Summary Reactivate user
+// @Description Make an inactive, suspended or locked user active again
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Param id path string true "User ID"
+// @Param request body UserStatusChangeRequest true "Reason for the change"
+// @Success 200 {object} User
+// @Header 200 {string} ETag "Version of the updated user"
+// @Failure      400  {object}  httputils.Problem
+// @Failure      404  {object}  httputils.Problem
+// @Failure      409  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users/{id}/reactivate [post]
+func (h *Handler) ReactivateUser(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, Active)
+}
+
+func (h *Handler) changeStatus(w http.ResponseWriter, r *http.Request, to UserStatus) {
+	defer r.Body.Close()
+
+	userId, uuiderr := httputils.ParseUUIDFromURL(r, "id")
+	if uuiderr != nil {
+		httputils.WriteError(w, http.StatusBadRequest, "Invalid user ID format", r)
+		return
+	}
+
+	var req UserStatusChangeRequest
+	if err := httputils.DecodeAndValidateRequest(r, &req, h.validate); err != nil {
+		slog.WarnContext(r.Context(), "Status change failed", "error", err)
+		httputils.WriteProblem(w, r, err)
 		return
 	}
 
-	updatedUser, err := h.service.UpdateUser(r.Context(), userId.String(), &req)
+	updatedUser, err := h.service.TransitionStatus(r.Context(), userId, to, req.Reason)
 
 	if err != nil {
-		slog.Warn("User update failed", "error", err)
-		httputils.WriteError(w, http.StatusInternalServerError, "User update failed", r)
+		httputils.WriteProblem(w, r, err)
 		return
 	}
 
+	w.Header().Set("ETag", httputils.ETag(updatedUser.Version))
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(updatedUser)
 }
 
+// GetUserStatusHistory godoc
+// @Summary Get user status history
+// @Description List the status transitions of an user, newest first
+// @Tags users
+// @Produce  json
+// @Param id path string true "User ID"
+// @Success 200 {array} StatusTransition
+// @Failure      400  {object}  httputils.Problem
+// @Failure      404  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users/{id}/status-history [get]
+func (h *Handler) GetUserStatusHistory(w http.ResponseWriter, r *http.Request) {
+
+	userId, uuiderr := httputils.ParseUUIDFromURL(r, "id")
+	if uuiderr != nil {
+		httputils.WriteError(w, http.StatusBadRequest, "Invalid user ID format", r)
+		return
+	}
+
+	history, err := h.service.ListStatusTransitions(r.Context(), userId)
+
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(history)
+}
+
+// ChangeUserPassword godoc
+// @Summary Change user password
+// @Description Set or change the password of an user. Users changing their own password must send the current one once a password has been set. Users with the users:write permission can reset the password of other users holding no permission they lack
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Param id path string true "User ID"
+// @Param request body UserPasswordChangeRequest true "Password change request"
+// @Success 204
+// @Failure      400  {object}  httputils.Problem
+// @Failure      401  {object}  httputils.Problem
+// @Failure      403  {object}  httputils.Problem
+// @Failure      404  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users/{id}/password [put]
+func (h *Handler) ChangeUserPassword(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	userId, uuiderr := httputils.ParseUUIDFromURL(r, "id")
+	if uuiderr != nil {
+		httputils.WriteError(w, http.StatusBadRequest, "Invalid user ID format", r)
+		return
+	}
+
+	var req UserPasswordChangeRequest
+	if err := httputils.DecodeAndValidateRequest(r, &req, h.validate); err != nil {
+		slog.WarnContext(r.Context(), "Password change failed", "error", err)
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	// Users changing their own password must prove they know the current one,
+	// administrators resetting someone else's password do not. They could log
+	// in as the user afterwards, so they must hold every permission the user
+	// holds.
+	var err error
+	if principal, ok := middleware.PrincipalFrom(r.Context()); ok && principal.UserID != userId {
+		err = h.service.ResetPassword(r.Context(), userId.String(), req.NewPassword)
+	} else {
+		err = h.service.ChangePassword(r.Context(), userId.String(), &req)
+	}
+
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // DeleteUser godoc
 // @Summary Delete user by id
-// @Description Delete an existing user by id
+// @Description Soft delete an existing user by id. Deleted users can be restored until they are purged
 // @Tags users
 // @Accept  json
 // @Produce  json
 // @Param id path string true "User ID"
+// @Param If-Match header string false "ETag of the user, required when server.requireIfMatch is set"
 // @Success 204
+// @Failure      404  {object}  httputils.Problem
+// @Failure      409  {object}  httputils.Problem
+// @Failure      412  {object}  httputils.Problem
+// @Failure      428  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /users/{id} [delete]
 func (h *Handler) DeleteUserById(w http.ResponseWriter, r *http.Request) {
 
 	userId, uuiderr := httputils.ParseUUIDFromURL(r, "id")
 	if uuiderr != nil {
-		http.Error(w, "Invalid user ID format", http.StatusBadRequest)
+		httputils.WriteError(w, http.StatusBadRequest, "Invalid user ID format", r)
 		return
 	}
 
-	err := h.service.DeleteUserById(r.Context(), userId.String())
+	version := r.Context().Value(middleware.IfMatchKey).(int64)
+	err := h.service.DeleteUserById(r.Context(), userId.String(), version)
+
 	if err != nil {
-		httputils.WriteError(w, http.StatusNotFound, "Failed to fetch users", r)
+		httputils.WriteProblem(w, r, err)
 		return
 	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// RestoreUser godoc
+// @Summary Restore user by id
+// @Description Restore a soft deleted user into the status it had before it was deleted
+// @Tags users
+// @Produce  json
+// @Param id path string true "User ID"
+// @Success 200 {object} User
+// @Header 200 {string} ETag "Version of the restored user"
+// @Failure      400  {object}  httputils.Problem
+// @Failure      404  {object}  httputils.Problem
+// @Failure      409  {object}  httputils.Problem
+// @Failure      500  {object}  httputils.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users/{id}/restore [post]
+func (h *Handler) RestoreUser(w http.ResponseWriter, r *http.Request) {
+
+	userId, uuiderr := httputils.ParseUUIDFromURL(r, "id")
+	if uuiderr != nil {
+		httputils.WriteError(w, http.StatusBadRequest, "Invalid user ID format", r)
+		return
+	}
+
+	restored, err := h.service.RestoreUser(r.Context(), userId)
+
+	if err != nil {
+		httputils.WriteProblem(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", httputils.ETag(restored.Version))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(restored)
+}
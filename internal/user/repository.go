@@ -2,19 +2,28 @@ package user
 
 import (
 	"context"
+	"database/sql"
 	"log/slog"
+	"strings"
+	"time"
+	"user-management/internal/audit"
 	"user-management/internal/common/converters"
+	"user-management/internal/db"
+	"user-management/internal/db/query"
 	"user-management/internal/db/sqlc"
+	"user-management/internal/token"
 
 	"github.com/google/uuid"
 )
 
 type Repository struct {
+	db      *sql.DB
+	tx      *sql.Tx
 	queries *sqlc.Queries
 }
 
-func NewRepository(q *sqlc.Queries) *Repository {
-	return &Repository{queries: q}
+func NewRepository(conn *sql.DB, q *sqlc.Queries) *Repository {
+	return &Repository{db: conn, queries: q}
 }
 
 func (r *Repository) Create(ctx context.Context, user *User) (sqlc.User, error) {
@@ -27,30 +36,194 @@ func (r *Repository) Create(ctx context.Context, user *User) (sqlc.User, error)
 		Phone:     user.Phone,
 		Age:       user.Age,
 		Status:    user.Status.String(),
+
+		PasswordHash: converters.NullableString(user.PasswordHash),
 	}
 
 	return r.queries.CreateUser(ctx, params)
 }
 
-func (r *Repository) GetAllPaged(ctx context.Context, limit int, offset int) ([]sqlc.User, error) {
+// CreateMany inserts the users with a single statement and returns them in
+// the order of users.
+func (r *Repository) CreateMany(ctx context.Context, users []*User) ([]sqlc.User, error) {
+
+	b := query.Insert("USERS", "USER_ID, FIRST_NAME, LAST_NAME, EMAIL, PHONE, AGE, STATUS, PASSWORD_HASH").Returning(userColumns)
+	for _, u := range users {
+		b.Values(u.UserId, u.FirstName, u.LastName, u.Email, u.Phone, u.Age, u.Status.String(), converters.NullableString(u.PasswordHash))
+	}
 
-	params := sqlc.ListAllUsersPagedParams{
-		Limit:  int32(limit),
-		Offset: int32(offset),
+	stmt, args := b.SQL()
+	saved, err := r.queryUsers(ctx, stmt, args)
+	if err != nil {
+		return nil, err
 	}
 
-	return r.queries.ListAllUsersPaged(ctx, params)
+	byId := make(map[uuid.UUID]sqlc.User, len(saved))
+	for _, u := range saved {
+		byId[u.UserID] = u
+	}
+	ordered := make([]sqlc.User, len(users))
+	for i, u := range users {
+		ordered[i] = byId[u.UserId]
+	}
+	return ordered, nil
+}
+
+// userColumns are the columns of USERS in the order of sqlc.User.
+const userColumns = "USER_ID, FIRST_NAME, LAST_NAME, EMAIL, PHONE, AGE, STATUS, PASSWORD_HASH, MFA_SECRET, MFA_ENABLED, MFA_LAST_STEP, DELETED_AT, DELETED_BY, VERSION"
+
+// GetAllPaged returns the users matching the filter in the order of keys. With
+// a cursor only the users after it are returned, and the order is reversed for
+// a backward cursor.
+func (r *Repository) GetAllPaged(ctx context.Context, f Filter, keys query.Sort, cur *query.Cursor, limit int, offset int) ([]sqlc.User, error) {
+
+	b := filterQuery(f)
+	if cur != nil && cur.Backward {
+		keys = keys.Reverse()
+	}
+	if cur != nil {
+		b.Seek(keys, cur.Values)
+	}
+	b.OrderBy(keys).Page(limit, offset)
+
+	stmt, args := b.SQL()
+	return r.queryUsers(ctx, stmt, args)
+}
+
+func (r *Repository) queryUsers(ctx context.Context, stmt string, args []any) ([]sqlc.User, error) {
+	rows, err := r.conn().QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []sqlc.User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// streamBatchSize is the number of rows Stream fetches at a time.
+const streamBatchSize = 1000
+
+// Stream calls fn with every user matching the filter in the order of keys.
+// The users are read from a server side cursor, so any number of users can
+// be streamed. An error returned by fn stops the stream.
+func (r *Repository) Stream(ctx context.Context, f Filter, keys query.Sort, fn func(sqlc.User) error) error {
+	stmt, args := filterQuery(f).OrderBy(keys).SQL()
+
+	return r.WithTx(ctx, func(tx *Repository) error {
+		return query.Stream(ctx, tx.tx, stmt, args, streamBatchSize, func(rows *sql.Rows) error {
+			u, err := scanUser(rows)
+			if err != nil {
+				return err
+			}
+			return fn(u)
+		})
+	})
+}
+
+func scanUser(rows *sql.Rows) (sqlc.User, error) {
+	var u sqlc.User
+	err := rows.Scan(
+		&u.UserID,
+		&u.FirstName,
+		&u.LastName,
+		&u.Email,
+		&u.Phone,
+		&u.Age,
+		&u.Status,
+		&u.PasswordHash,
+		&u.MfaSecret,
+		&u.MfaEnabled,
+		&u.MfaLastStep,
+		&u.DeletedAt,
+		&u.DeletedBy,
+		&u.Version,
+	)
+	return u, err
+}
+
+// Count returns the number of users matching the filter with the given mode.
+func (r *Repository) Count(ctx context.Context, f Filter, mode query.CountMode) (*int64, error) {
+	return query.Total(ctx, r.conn(), filterQuery(f), mode)
+}
+
+// CountByStatus counts the users that are not deleted by status.
+func (r *Repository) CountByStatus(ctx context.Context) ([]sqlc.CountUsersByStatusRow, error) {
+	return r.queries.CountUsersByStatus(ctx)
+}
+
+func filterQuery(f Filter) *query.Builder {
+	b := query.Select(userColumns, "USERS")
+	if !f.IncludeDeleted {
+		b.Where("DELETED_AT IS NULL")
+	}
+	if len(f.Statuses) > 0 {
+		statuses := make([]string, len(f.Statuses))
+		for i, s := range f.Statuses {
+			statuses[i] = s.String()
+		}
+		b.Where("STATUS = ANY(?)", statuses)
+	}
+	if f.EmailDomain != "" {
+		b.Where("LOWER(SPLIT_PART(EMAIL, '@', 2)) = LOWER(?)", f.EmailDomain)
+	}
+	if f.MinAge != nil {
+		b.Where("AGE >= ?", *f.MinAge)
+	}
+	if f.MaxAge != nil {
+		b.Where("AGE <= ?", *f.MaxAge)
+	}
+	if f.NamePrefix != "" {
+		prefix := strings.ToLower(query.Prefix(f.NamePrefix))
+		b.Where("LOWER(FIRST_NAME) LIKE ? OR LOWER(LAST_NAME) LIKE ?", prefix, prefix)
+	}
+	if f.Query != "" {
+		pattern := query.Contains(f.Query)
+		b.Where("FIRST_NAME ILIKE ? OR LAST_NAME ILIKE ? OR EMAIL ILIKE ?", pattern, pattern, pattern)
+	}
+	return b
 }
 
 func (r *Repository) GetUserById(ctx context.Context, userId string) (sqlc.User, error) {
+	return r.findUserById(ctx, userId, false)
+}
+
+// GetUserByIdIncludingDeleted also finds soft deleted users.
+func (r *Repository) GetUserByIdIncludingDeleted(ctx context.Context, userId string) (sqlc.User, error) {
+	return r.findUserById(ctx, userId, true)
+}
+
+func (r *Repository) findUserById(ctx context.Context, userId string, includeDeleted bool) (sqlc.User, error) {
 
 	parsedUUID, err := uuid.Parse(userId)
 	if err != nil {
-		slog.Error("Invalid UUID from DB", "error", err)
+		slog.ErrorContext(ctx, "Invalid UUID from DB", "error", err)
 		return sqlc.User{}, err
 	}
 
-	return r.queries.FindUserById(ctx, parsedUUID)
+	params := sqlc.FindUserByIdParams{
+		UserID:         parsedUUID,
+		IncludeDeleted: includeDeleted,
+	}
+
+	return r.queries.FindUserById(ctx, params)
+}
+
+// GetPermissions returns the union of the permissions of the roles of the
+// user.
+func (r *Repository) GetPermissions(ctx context.Context, userId uuid.UUID) ([]string, error) {
+	return r.queries.ListUserPermissions(ctx, userId)
+}
+
+func (r *Repository) GetUserByEmail(ctx context.Context, email string) (sqlc.User, error) {
+	return r.queries.FindUserByEmail(ctx, email)
 }
 
 func (r *Repository) Update(ctx context.Context, user *User) (sqlc.User, error) {
@@ -63,18 +236,127 @@ func (r *Repository) Update(ctx context.Context, user *User) (sqlc.User, error)
 		Age:       converters.NullableInt16(user.Age),
 		Status:    converters.NullableString(user.Status.String()),
 		UserID:    user.UserId,
+		Version:   user.Version,
 	}
 
 	return r.queries.UpdateUser(ctx, parms)
 }
 
-func (r *Repository) Delete(ctx context.Context, userId string) error {
+func (r *Repository) UpdatePassword(ctx context.Context, userId uuid.UUID, passwordHash string) error {
 
-	parsedUUID, err := uuid.Parse(userId)
-	if err != nil {
-		slog.Error("Invalid UUID from DB", "error", err)
-		return err
+	params := sqlc.UpdateUserPasswordParams{
+		PasswordHash: converters.NullableString(passwordHash),
+		UserID:       userId,
+	}
+
+	return r.queries.UpdateUserPassword(ctx, params)
+}
+
+// RevokeSessions revokes every refresh token of the user.
+func (r *Repository) RevokeSessions(ctx context.Context, userId uuid.UUID) error {
+
+	params := sqlc.RevokeUserRefreshTokensParams{
+		RevokedAt:     sql.NullTime{Time: time.Now().UTC(), Valid: true},
+		RevokedReason: converters.NullableString(token.RevokedSessionsEnded),
+		UserID:        userId,
+	}
+
+	return r.queries.RevokeUserRefreshTokens(ctx, params)
+}
+
+// WithTx runs fn with a repository bound to a new transaction, committing it
+// when fn returns nil. A repository already bound to a transaction runs fn in
+// its transaction, which is committed by the outermost WithTx.
+func (r *Repository) WithTx(ctx context.Context, fn func(tx *Repository) error) error {
+	if r.tx != nil {
+		return fn(r)
+	}
+	return db.InTx(ctx, r.db, func(tx *sql.Tx) error {
+		return fn(&Repository{db: r.db, tx: tx, queries: db.TxQueries(tx)})
+	})
+}
+
+// conn returns the transaction of the repository, if any, or the database.
+func (r *Repository) conn() sqlc.DBTX {
+	if r.tx != nil {
+		return db.Observe(r.tx)
 	}
+	return db.Observe(r.db)
+}
+
+// Audit records the event with the queries of the repository, so it commits
+// together with a change made through the same WithTx repository.
+func (r *Repository) Audit(ctx context.Context, e audit.Event) error {
+	return audit.Write(ctx, r.queries, e)
+}
+
+// Transition changes the status only when it is still from and records the
+// transition. It reports whether the status changed and must run in WithTx.
+func (r *Repository) Transition(ctx context.Context, userId uuid.UUID, from UserStatus, to UserStatus, reason string, actor uuid.NullUUID) (bool, error) {
+	rows, err := r.queries.UpdateUserStatus(ctx, sqlc.UpdateUserStatusParams{
+		Status:     to.String(),
+		UserID:     userId,
+		FromStatus: from.String(),
+	})
+	if err != nil || rows == 0 {
+		return false, err
+	}
+
+	return true, r.createTransition(ctx, userId, from, to, reason, actor, time.Now().UTC())
+}
+
+func (r *Repository) createTransition(ctx context.Context, userId uuid.UUID, from UserStatus, to UserStatus, reason string, actor uuid.NullUUID, at time.Time) error {
+	return r.queries.CreateUserStatusTransition(ctx, sqlc.CreateUserStatusTransitionParams{
+		ID:         uuid.New(),
+		UserID:     userId,
+		FromStatus: from.String(),
+		ToStatus:   to.String(),
+		Reason:     reason,
+		ActorID:    actor,
+		CreatedAt:  at,
+	})
+}
+
+func (r *Repository) GetStatusTransitions(ctx context.Context, userId uuid.UUID) ([]sqlc.UserStatusTransition, error) {
+	return r.queries.ListUserStatusTransitions(ctx, userId)
+}
+
+// SoftDelete marks the user as deleted and records the transition to Deleted.
+// It reports whether the user was deleted, which is not the case when it
+// changed since version was read, and must run in WithTx.
+func (r *Repository) SoftDelete(ctx context.Context, userId uuid.UUID, from UserStatus, version int64, reason string, actor uuid.NullUUID) (bool, error) {
+	now := time.Now().UTC()
+
+	rows, err := r.queries.SoftDeleteUser(ctx, sqlc.SoftDeleteUserParams{
+		DeletedAt: now,
+		DeletedBy: actor,
+		Status:    Deleted.String(),
+		UserID:    userId,
+		Version:   version,
+	})
+	if err != nil || rows == 0 {
+		return false, err
+	}
+
+	return true, r.createTransition(ctx, userId, from, Deleted, reason, actor, now)
+}
+
+// Restore clears the deletion of an user, puts it back into the given status
+// and records the transition. It must run in WithTx.
+func (r *Repository) Restore(ctx context.Context, userId uuid.UUID, to UserStatus, reason string, actor uuid.NullUUID) (bool, error) {
+	rows, err := r.queries.RestoreUser(ctx, sqlc.RestoreUserParams{
+		Status: to.String(),
+		UserID: userId,
+	})
+	if err != nil || rows == 0 {
+		return false, err
+	}
+
+	return true, r.createTransition(ctx, userId, Deleted, to, reason, actor, time.Now().UTC())
+}
 
-	return r.queries.DeleteUserByID(ctx, parsedUUID)
+// Purge hard deletes users soft deleted before the given time and returns
+// their ids.
+func (r *Repository) Purge(ctx context.Context, deletedBefore time.Time) ([]uuid.UUID, error) {
+	return r.queries.PurgeDeletedUsers(ctx, deletedBefore)
 }
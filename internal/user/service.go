@@ -2,49 +2,278 @@ package user
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+	"user-management/internal/audit"
+	"user-management/internal/batch"
+	"user-management/internal/common/apperror"
+	"user-management/internal/db/query"
+	"user-management/internal/db/sqlc"
+	"user-management/internal/middleware"
+	"user-management/internal/tracing"
+	"user-management/internal/transfer"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// AuditEntity is the entity type of users in the audit log.
+const AuditEntity = "user"
+
+// uniqueViolation is the PostgreSQL error code of unique constraint violations.
+const uniqueViolation = "23505"
+
+var (
+	ErrUserNotFound            = apperror.New(apperror.NotFound, "user_not_found", "user not found")
+	ErrEmailTaken              = apperror.New(apperror.Conflict, "email_taken", "email address is already in use")
+	ErrInvalidCredentials      = apperror.New(apperror.Unauthorized, "invalid_credentials", "invalid credentials")
+	ErrUserNotActive           = apperror.New(apperror.Forbidden, "user_not_active", "user is not active")
+	ErrEmailNotVerified        = apperror.New(apperror.Forbidden, "email_not_verified", "email address is not verified")
+	ErrInvalidStatusTransition = apperror.New(apperror.Conflict, "invalid_status_transition", "status transition is not allowed")
+	ErrStatusChanged           = apperror.New(apperror.Conflict, "status_changed", "user status was changed concurrently")
+	ErrUserNotDeleted          = apperror.New(apperror.Conflict, "user_not_deleted", "user is not deleted")
+	ErrVersionMismatch         = apperror.New(apperror.PreconditionFailed, "version_mismatch", "user version does not match")
+	ErrPermissionsExceeded     = apperror.New(apperror.Forbidden, "permissions_exceeded", "user holds permissions the caller lacks")
+	ErrStatusNotSettable       = apperror.New(apperror.Validation, "status_not_settable", "status cannot be set when creating a user, change it with PATCH /users/{id} or the suspend, lock and reactivate endpoints")
 )
 
 type Service struct {
-	repo *Repository
+	repo                *Repository
+	cursors             *query.CursorCodec
+	requireVerification bool
 }
 
-func NewService(repo *Repository) *Service {
-	return &Service{repo: repo}
+// NewService creates the user service. With requireVerification new users
+// start as PendingVerification and can only log in once their email is verified.
+// Pagination cursors are signed with cursors.
+func NewService(repo *Repository, cursors *query.CursorCodec, requireVerification bool) *Service {
+	return &Service{repo: repo, cursors: cursors, requireVerification: requireVerification}
 }
 
-func (s *Service) CreateUser(ctx context.Context, u *User) (User, error) {
-	newUser := NewUser(u.FirstName, u.LastName, u.Email, u.Phone, u.Age)
-	savedUser, err := s.repo.Create(ctx, newUser)
+func (s *Service) CreateUser(ctx context.Context, u *UserCreateRequest) (_ User, err error) {
+	ctx, span := tracing.Start(ctx, "user.Service.CreateUser")
+	defer tracing.End(span, &err)
+
+	if u.Status != nil {
+		return User{}, ErrStatusNotSettable
+	}
+
+	newUser, err := s.newUser(u)
+	if err != nil {
+		return User{}, err
+	}
+
+	var savedUser sqlc.User
+	err = s.repo.WithTx(ctx, func(tx *Repository) error {
+		var err error
+		savedUser, err = tx.Create(ctx, newUser)
+		if err != nil {
+			return emailTaken(err)
+		}
+		return tx.Audit(ctx, auditEvent(audit.ActionCreate, savedUser.UserID, nil, FromSQLC(savedUser)))
+	})
 	if err != nil {
 		return User{}, err
 	}
 	return FromSQLC(savedUser), nil
 }
 
-func (s *Service) ListUsersPaged(ctx context.Context, limit int, offset int) ([]User, error) {
-	users, err := s.repo.GetAllPaged(ctx, limit, offset)
+func (s *Service) newUser(u *UserCreateRequest) (*User, error) {
+	status := Active
+	if s.requireVerification {
+		status = PendingVerification
+	}
+
+	newUser := NewUser(u.FirstName, u.LastName, u.Email, u.Phone, u.Age, status)
+
+	if u.Password != "" {
+		hash, err := HashPassword(u.Password)
+		if err != nil {
+			return nil, err
+		}
+		newUser.PasswordHash = hash
+	}
+	return newUser, nil
+}
+
+// BatchItem is an operation of a users batch.
+type BatchItem = batch.Item[UserCreateRequest, UserUpdateRequest]
+
+// Batch applies the operations of a users batch, see batch.Run. In
+// transactional mode all operations commit together and the first failure
+// rolls them back and is returned.
+func (s *Service) Batch(ctx context.Context, mode batch.Mode, items []BatchItem) (_ []batch.Outcome, err error) {
+	ctx, span := tracing.Start(ctx, "user.Service.Batch")
+	defer tracing.End(span, &err)
+
+	if mode == batch.BestEffort {
+		return batch.Run(ctx, items, false, s.createBatch, s.applyBatchItem)
+	}
+
+	var outcomes []batch.Outcome
+	err = s.repo.WithTx(ctx, func(tx *Repository) error {
+		txService := &Service{repo: tx, cursors: s.cursors, requireVerification: s.requireVerification}
+
+		var err error
+		outcomes, err = batch.Run(ctx, items, true, txService.createBatch, txService.applyBatchItem)
+		return err
+	})
+	return outcomes, err
+}
+
+// createBatch inserts the users of the create items with one statement.
+func (s *Service) createBatch(ctx context.Context, items []BatchItem) ([]batch.Outcome, error) {
+	newUsers := make([]*User, len(items))
+	for i, item := range items {
+		var err error
+		if newUsers[i], err = s.newUser(item.Create); err != nil {
+			return nil, err
+		}
+	}
+
+	var saved []sqlc.User
+	err := s.repo.WithTx(ctx, func(tx *Repository) error {
+		var err error
+		saved, err = tx.CreateMany(ctx, newUsers)
+		if err != nil {
+			return emailTaken(err)
+		}
+		for _, u := range saved {
+			if err := tx.Audit(ctx, auditEvent(audit.ActionCreate, u.UserID, nil, FromSQLC(u))); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	return FromSQLCList(users), nil
+
+	outcomes := make([]batch.Outcome, len(items))
+	for i, item := range items {
+		outcomes[i] = item.Done(saved[i].UserID, FromSQLC(saved[i]))
+	}
+	return outcomes, nil
+}
+
+func (s *Service) applyBatchItem(ctx context.Context, item BatchItem) batch.Outcome {
+	var u User
+	var err error
+
+	switch item.Op {
+	case batch.Create:
+		u, err = s.CreateUser(ctx, item.Create)
+	case batch.Update:
+		u, err = s.UpdateUser(ctx, item.ID.String(), item.Update, item.Version)
+	case batch.Delete:
+		if err = s.DeleteUserById(ctx, item.ID.String(), item.Version); err == nil {
+			return item.Done(item.ID, nil)
+		}
+	}
+
+	if err != nil {
+		return item.Failed(err)
+	}
+	return item.Done(u.UserId, u)
+}
+
+// ListUsersPaged returns the page of users matching the filter selected by
+// req, which is invalid with query.ErrInvalidCursor when its cursor is.
+func (s *Service) ListUsersPaged(ctx context.Context, f Filter, req query.PageRequest) (_ query.Page[User], err error) {
+	ctx, span := tracing.Start(ctx, "user.Service.ListUsersPaged")
+	defer tracing.End(span, &err)
+
+	keys := f.Sort.With(tiebreaker)
+
+	fetch := func(cur *query.Cursor, limit int, offset int) ([]sqlc.User, error) {
+		return s.repo.GetAllPaged(ctx, f, keys, cur, limit, offset)
+	}
+	values := func(u sqlc.User) []string {
+		return sortValues(u, keys)
+	}
+
+	page, err := query.Paginate(s.cursors, keys, req, fetch, values)
+	if err != nil {
+		return query.Page[User]{}, err
+	}
+
+	result := query.Page[User]{Items: FromSQLCList(page.Items), PageInfo: page.PageInfo}
+	if result.Total, err = s.repo.Count(ctx, f, req.Count); err != nil {
+		return query.Page[User]{}, err
+	}
+	result.Estimated = result.Total != nil && req.Count == query.CountEstimated
+	return result, nil
+}
+
+// ExportUsers calls fn with every user matching the filter, in the order of the
+// filter. Unlike the pages of ListUsersPaged, the users are streamed, an error
+// returned by fn stops the stream.
+func (s *Service) ExportUsers(ctx context.Context, f Filter, fn func(User) error) (err error) {
+	ctx, span := tracing.Start(ctx, "user.Service.ExportUsers")
+	defer tracing.End(span, &err)
+
+	return s.repo.Stream(ctx, f, f.Sort.With(tiebreaker), func(row sqlc.User) error {
+		return fn(FromSQLC(row))
+	})
 }
 
-func (s *Service) GetUserById(ctx context.Context, userId string) (User, error) {
+func (s *Service) GetUserById(ctx context.Context, userId string) (_ User, err error) {
+	ctx, span := tracing.Start(ctx, "user.Service.GetUserById")
+	defer tracing.End(span, &err)
+
 	u, err := s.repo.GetUserById(ctx, userId)
 	if err != nil {
-		return User{}, err
+		return User{}, notFound(err)
 	}
 	return FromSQLC(u), nil
 }
 
-func (s *Service) UpdateUser(ctx context.Context, userId string, u *UserUpdateRequest) (User, error) {
+func (s *Service) GetUserByIdIncludingDeleted(ctx context.Context, userId string) (_ User, err error) {
+	ctx, span := tracing.Start(ctx, "user.Service.GetUserByIdIncludingDeleted")
+	defer tracing.End(span, &err)
+
+	u, err := s.repo.GetUserByIdIncludingDeleted(ctx, userId)
+	if err != nil {
+		return User{}, notFound(err)
+	}
+	return FromSQLC(u), nil
+}
+
+func (s *Service) GetUserByEmail(ctx context.Context, email string) (_ User, err error) {
+	ctx, span := tracing.Start(ctx, "user.Service.GetUserByEmail")
+	defer tracing.End(span, &err)
+
+	u, err := s.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		return User{}, notFound(err)
+	}
+	return FromSQLC(u), nil
+}
+
+// UpdateUser applies the set fields of the request. A non zero expectedVersion
+// must match the version of the user, otherwise ErrVersionMismatch is returned.
+func (s *Service) UpdateUser(ctx context.Context, userId string, u *UserUpdateRequest, expectedVersion int64) (_ User, err error) {
+	ctx, span := tracing.Start(ctx, "user.Service.UpdateUser")
+	defer tracing.End(span, &err)
 
 	existing, err := s.repo.GetUserById(ctx, userId)
 	if err != nil {
-		return User{}, err
+		return User{}, notFound(err)
+	}
+	if expectedVersion != 0 && expectedVersion != existing.Version {
+		return User{}, ErrVersionMismatch
+	}
+	before := FromSQLC(existing)
+
+	// A new email lets the principal reset the password of the user.
+	if u.Email != "" && u.Email != existing.Email {
+		if err := s.CheckPermissionsHeld(ctx, existing.UserID); err != nil {
+			return User{}, err
+		}
 	}
 
 	if u.FirstName != "" {
@@ -62,9 +291,6 @@ func (s *Service) UpdateUser(ctx context.Context, userId string, u *UserUpdateRe
 	if u.Age > 0 {
 		existing.Age = u.Age
 	}
-	if u.Status != "" {
-		existing.Status = u.Status
-	}
 
 	id, err := uuid.Parse(userId)
 	if err != nil {
@@ -77,17 +303,531 @@ func (s *Service) UpdateUser(ctx context.Context, userId string, u *UserUpdateRe
 		return User{}, err
 	}
 
-	savedUser, err := s.repo.Update(ctx, userToBeUpdate)
+	// Status changes go through the transition table and are recorded, the
+	// remaining fields are updated with the current status.
+	current := userToBeUpdate.Status
+	next := current
+	if u.Status != "" {
+		next, err = ParseUserStatus(u.Status)
+		if err != nil {
+			return User{}, apperror.New(apperror.Validation, "invalid_status", err.Error())
+		}
+		if next != current && !canChangeStatus(current, next) {
+			return User{}, ErrInvalidStatusTransition
+		}
+	}
+
+	var savedUser sqlc.User
+	err = s.repo.WithTx(ctx, func(tx *Repository) error {
+		var err error
+		savedUser, err = tx.Update(ctx, userToBeUpdate)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrVersionMismatch
+		}
+		if err != nil {
+			return emailTaken(err)
+		}
+
+		if next != current {
+			if err := transition(ctx, tx, id, current, next, "Status updated"); err != nil {
+				return err
+			}
+			savedUser.Status = next.String()
+			savedUser.Version++
+		}
+
+		return tx.Audit(ctx, auditEvent(audit.ActionUpdate, id, before, FromSQLC(savedUser)))
+	})
 	if err != nil {
 		return User{}, err
 	}
+
 	return FromSQLC(savedUser), nil
 }
 
-func (s *Service) DeleteUserById(ctx context.Context, userId string) error {
-	err := s.repo.Delete(ctx, userId)
+// ImportUser creates an user with the email of u, or updates the fields in
+// which the existing user differs from u. With dryRun nothing is written and
+// the action that would be taken is returned.
+func (s *Service) ImportUser(ctx context.Context, u *UserImport, dryRun bool) (_ transfer.Action, err error) {
+	ctx, span := tracing.Start(ctx, "user.Service.ImportUser")
+	defer tracing.End(span, &err)
+
+	action := transfer.Updated
+	existing, err := s.GetUserByEmail(ctx, u.Email)
+	if errors.Is(err, ErrUserNotFound) {
+		if dryRun {
+			return transfer.Created, nil
+		}
+		created, err := s.CreateUser(ctx, &UserCreateRequest{
+			FirstName: u.FirstName,
+			LastName:  u.LastName,
+			Email:     u.Email,
+			Phone:     u.Phone,
+			Age:       u.Age,
+		})
+		if err != nil {
+			return transfer.Rejected, err
+		}
+		if u.Status == "" || u.Status == created.Status.String() {
+			return transfer.Created, nil
+		}
+		// The status of new users is chosen by CreateUser, a different
+		// status in the file is applied as a transition.
+		existing, action = created, transfer.Created
+	} else if err != nil {
+		return transfer.Rejected, err
+	}
+
+	var changes UserUpdateRequest
+	if u.FirstName != existing.FirstName {
+		changes.FirstName = u.FirstName
+	}
+	if u.LastName != existing.LastName {
+		changes.LastName = u.LastName
+	}
+	if u.Phone != "" && u.Phone != existing.Phone {
+		changes.Phone = u.Phone
+	}
+	if u.Age > 0 && u.Age != existing.Age {
+		changes.Age = u.Age
+	}
+	if u.Status != "" && u.Status != existing.Status.String() {
+		next, err := ParseUserStatus(u.Status)
+		if err != nil {
+			return transfer.Rejected, apperror.New(apperror.Validation, "invalid_status", err.Error())
+		}
+		if !canChangeStatus(existing.Status, next) {
+			return transfer.Rejected, ErrInvalidStatusTransition
+		}
+		changes.Status = u.Status
+	}
+
+	if changes == (UserUpdateRequest{}) {
+		return transfer.Unchanged, nil
+	}
+	if !dryRun {
+		if _, err := s.UpdateUser(ctx, existing.UserId.String(), &changes, existing.Version); err != nil {
+			return transfer.Rejected, err
+		}
+	}
+	return action, nil
+}
+
+// TransitionStatus moves an user to the given status when the transition
+// table allows it. The authenticated principal, if any, is recorded as the
+// actor of the change.
+func (s *Service) TransitionStatus(ctx context.Context, userId uuid.UUID, to UserStatus, reason string) (_ User, err error) {
+	ctx, span := tracing.Start(ctx, "user.Service.TransitionStatus")
+	defer tracing.End(span, &err)
+
+	existing, err := s.repo.GetUserById(ctx, userId.String())
+	if err != nil {
+		return User{}, notFound(err)
+	}
+
+	current := FromSQLC(existing)
+	if !canChangeStatus(current.Status, to) {
+		return User{}, ErrInvalidStatusTransition
+	}
+
+	// The user is read back, the transition also increments the version.
+	var updated sqlc.User
+	err = s.repo.WithTx(ctx, func(tx *Repository) error {
+		if err := transition(ctx, tx, userId, current.Status, to, reason); err != nil {
+			return err
+		}
+
+		updated, err = tx.GetUserById(ctx, userId.String())
+		if err != nil {
+			return err
+		}
+		return tx.Audit(ctx, auditEvent(audit.ActionUpdate, userId, current, FromSQLC(updated)))
+	})
+	if err != nil {
+		return User{}, err
+	}
+
+	return FromSQLC(updated), nil
+}
+
+func (s *Service) ListStatusTransitions(ctx context.Context, userId uuid.UUID) (_ []StatusTransition, err error) {
+	ctx, span := tracing.Start(ctx, "user.Service.ListStatusTransitions")
+	defer tracing.End(span, &err)
+
+	if _, err := s.repo.GetUserById(ctx, userId.String()); err != nil {
+		return nil, notFound(err)
+	}
+
+	transitions, err := s.repo.GetStatusTransitions(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	mapped := make([]StatusTransition, len(transitions))
+	for i, t := range transitions {
+		mapped[i] = StatusTransitionFromSQLC(t)
+	}
+	return mapped, nil
+}
+
+// canChangeStatus only allows moving in and out of Deleted through
+// DeleteUserById and RestoreUser, which also maintain the deletion columns.
+func canChangeStatus(from UserStatus, to UserStatus) bool {
+	return from != Deleted && to != Deleted && from.CanTransitionTo(to)
+}
+
+func transition(ctx context.Context, tx *Repository, userId uuid.UUID, from UserStatus, to UserStatus, reason string) error {
+	changed, err := tx.Transition(ctx, userId, from, to, reason, actorFrom(ctx))
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return ErrStatusChanged
+	}
+	return nil
+}
+
+// DeleteUserById soft deletes an user. It can be restored with RestoreUser
+// until the deleted users are purged. A non zero expectedVersion must match
+// the version of the user.
+func (s *Service) DeleteUserById(ctx context.Context, userId string, expectedVersion int64) (err error) {
+	ctx, span := tracing.Start(ctx, "user.Service.DeleteUserById")
+	defer tracing.End(span, &err)
+
+	existing, err := s.repo.GetUserById(ctx, userId)
+	if err != nil {
+		return notFound(err)
+	}
+	if expectedVersion != 0 && expectedVersion != existing.Version {
+		return ErrVersionMismatch
+	}
+
+	current := FromSQLC(existing)
+	if !current.Status.CanTransitionTo(Deleted) {
+		return ErrInvalidStatusTransition
+	}
+
+	return s.repo.WithTx(ctx, func(tx *Repository) error {
+		deleted, err := tx.SoftDelete(ctx, current.UserId, current.Status, current.Version, "User deleted", actorFrom(ctx))
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return ErrVersionMismatch
+		}
+
+		after, err := tx.GetUserByIdIncludingDeleted(ctx, userId)
+		if err != nil {
+			return err
+		}
+		return tx.Audit(ctx, auditEvent(audit.ActionDelete, current.UserId, current, FromSQLC(after)))
+	})
+}
+
+// RestoreUser undoes the deletion of an user, putting it back into the status
+// it had before it was deleted.
+func (s *Service) RestoreUser(ctx context.Context, userId uuid.UUID) (_ User, err error) {
+	ctx, span := tracing.Start(ctx, "user.Service.RestoreUser")
+	defer tracing.End(span, &err)
+
+	existing, err := s.repo.GetUserByIdIncludingDeleted(ctx, userId.String())
+	if err != nil {
+		return User{}, notFound(err)
+	}
+	if !existing.DeletedAt.Valid {
+		return User{}, ErrUserNotDeleted
+	}
+
+	previous, err := s.statusBeforeDeletion(ctx, userId)
+	if err != nil {
+		return User{}, err
+	}
+
+	var after sqlc.User
+	err = s.repo.WithTx(ctx, func(tx *Repository) error {
+		restored, err := tx.Restore(ctx, userId, previous, "User restored", actorFrom(ctx))
+		if err != nil {
+			return err
+		}
+		if !restored {
+			return ErrUserNotDeleted
+		}
+
+		after, err = tx.GetUserById(ctx, userId.String())
+		if err != nil {
+			return err
+		}
+		return tx.Audit(ctx, auditEvent(audit.ActionRestore, userId, FromSQLC(existing), FromSQLC(after)))
+	})
+	if err != nil {
+		return User{}, err
+	}
+
+	return FromSQLC(after), nil
+}
+
+// statusBeforeDeletion looks up the status the user was deleted from. Users
+// deleted without a recorded transition come back as InActive.
+func (s *Service) statusBeforeDeletion(ctx context.Context, userId uuid.UUID) (UserStatus, error) {
+	transitions, err := s.repo.GetStatusTransitions(ctx, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, t := range transitions {
+		if t.ToStatus != Deleted.String() {
+			continue
+		}
+		previous, err := ParseUserStatus(t.FromStatus)
+		if err != nil || !Deleted.CanTransitionTo(previous) {
+			break
+		}
+		return previous, nil
+	}
+	return InActive, nil
+}
+
+// CountByStatus returns the number of users that are not deleted by status,
+// including the statuses no user has.
+func (s *Service) CountByStatus(ctx context.Context) (_ map[string]int64, err error) {
+	ctx, span := tracing.Start(ctx, "user.Service.CountByStatus")
+	defer tracing.End(span, &err)
+
+	rows, err := s.repo.CountByStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, status := range Statuses() {
+		counts[status.String()] = 0
+	}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// PurgeDeleted hard deletes users soft deleted before the given time and
+// returns how many were removed.
+func (s *Service) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (_ int64, err error) {
+	ctx, span := tracing.Start(ctx, "user.Service.PurgeDeleted")
+	defer tracing.End(span, &err)
+
+	var purged []uuid.UUID
+	err = s.repo.WithTx(ctx, func(tx *Repository) error {
+		var err error
+		purged, err = tx.Purge(ctx, deletedBefore)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range purged {
+			if err := tx.Audit(ctx, auditEvent(audit.ActionPurge, id, nil, nil)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(purged)), nil
+}
+
+func auditEvent(action string, userId uuid.UUID, before any, after any) audit.Event {
+	return audit.Event{
+		Action:     action,
+		EntityType: AuditEntity,
+		EntityId:   userId,
+		Before:     before,
+		After:      after,
+	}
+}
+
+// notFound turns a missing user row into ErrUserNotFound, which still matches
+// sql.ErrNoRows for callers checking for it.
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrUserNotFound.Wrap(err)
+	}
+	return err
+}
+
+// emailTaken turns a violation of the unique email constraint into
+// ErrEmailTaken.
+func emailTaken(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == "users_email_key" {
+		return ErrEmailTaken.Wrap(err)
+	}
+	return err
+}
+
+// actorFrom returns the authenticated principal as the actor of a change. It
+// is empty for changes made by the system or from the CLI.
+func actorFrom(ctx context.Context) uuid.NullUUID {
+	if principal, ok := middleware.PrincipalFrom(ctx); ok {
+		return uuid.NullUUID{UUID: principal.UserID, Valid: true}
+	}
+	return uuid.NullUUID{}
+}
+
+// MarkEmailVerified activates an user waiting for email verification. Users
+// in any other status are left unchanged.
+func (s *Service) MarkEmailVerified(ctx context.Context, userId uuid.UUID) (err error) {
+	ctx, span := tracing.Start(ctx, "user.Service.MarkEmailVerified")
+	defer tracing.End(span, &err)
+
+	existing, err := s.repo.GetUserById(ctx, userId.String())
+	if err != nil {
+		return notFound(err)
+	}
+
+	return s.repo.WithTx(ctx, func(tx *Repository) error {
+		changed, err := tx.Transition(ctx, userId, PendingVerification, Active, "Email verified", uuid.NullUUID{})
+		if err != nil || !changed {
+			return err
+		}
+
+		after, err := tx.GetUserById(ctx, userId.String())
+		if err != nil {
+			return err
+		}
+		return tx.Audit(ctx, auditEvent(audit.ActionUpdate, userId, FromSQLC(existing), FromSQLC(after)))
+	})
+}
+
+// ResetPassword sets a new password without checking the current one.
+func (s *Service) ResetPassword(ctx context.Context, userId string, password string) (err error) {
+	ctx, span := tracing.Start(ctx, "user.Service.ResetPassword")
+	defer tracing.End(span, &err)
+
+	existing, err := s.repo.GetUserById(ctx, userId)
+	if err != nil {
+		return notFound(err)
+	}
+	if err := s.CheckPermissionsHeld(ctx, existing.UserID); err != nil {
+		return err
+	}
+
+	return s.SetPassword(ctx, userId, password)
+}
+
+// CheckPermissionsHeld returns ErrPermissionsExceeded when the user holds a
+// permission the authenticated principal lacks. Taking over the user, by
+// resetting their password, MFA or email, would grant the principal these
+// permissions. Principals acting on themselves and changes made by the
+// system or from the CLI are not checked.
+func (s *Service) CheckPermissionsHeld(ctx context.Context, userId uuid.UUID) error {
+	principal, ok := middleware.PrincipalFrom(ctx)
+	if !ok || principal.UserID == userId {
+		return nil
+	}
+
+	permissions, err := s.repo.GetPermissions(ctx, userId)
 	if err != nil {
 		return err
 	}
+
+	for _, p := range permissions {
+		if !principal.HasPermission(p) {
+			slog.WarnContext(ctx, "Change of a user holding more permissions denied", "userId", principal.UserID, "targetUserId", userId, "permission", p)
+			return ErrPermissionsExceeded
+		}
+	}
 	return nil
 }
+
+func (s *Service) SetPassword(ctx context.Context, userId string, password string) (err error) {
+	ctx, span := tracing.Start(ctx, "user.Service.SetPassword")
+	defer tracing.End(span, &err)
+
+	id, err := uuid.Parse(userId)
+	if err != nil {
+		return fmt.Errorf("invalid userId: %w", err)
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	// Sessions started with the old password end with it, so a stolen refresh
+	// token does not outlive the password change. The password hash is never
+	// part of the diff, the action itself is the record.
+	return s.repo.WithTx(ctx, func(tx *Repository) error {
+		if err := tx.UpdatePassword(ctx, id, hash); err != nil {
+			return err
+		}
+		if err := tx.RevokeSessions(ctx, id); err != nil {
+			return err
+		}
+		return tx.Audit(ctx, auditEvent(audit.ActionPasswordChange, id, nil, nil))
+	})
+}
+
+// ChangePassword replaces the password of a user after checking the current one.
+// Users without a password yet can set one without providing the current password.
+func (s *Service) ChangePassword(ctx context.Context, userId string, req *UserPasswordChangeRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "user.Service.ChangePassword")
+	defer tracing.End(span, &err)
+
+	existing, err := s.repo.GetUserById(ctx, userId)
+	if err != nil {
+		return notFound(err)
+	}
+
+	if existing.PasswordHash.Valid {
+		ok, err := VerifyPassword(existing.PasswordHash.String, req.CurrentPassword)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrInvalidCredentials.WithMessage("current password is incorrect")
+		}
+	}
+
+	return s.SetPassword(ctx, userId, req.NewPassword)
+}
+
+// Authenticate returns the user identified by email when the password matches.
+// Unknown emails still pay for a hash comparison so response timing does not
+// reveal which accounts exist.
+func (s *Service) Authenticate(ctx context.Context, email string, password string) (_ User, err error) {
+	ctx, span := tracing.Start(ctx, "user.Service.Authenticate")
+	defer tracing.End(span, &err)
+
+	existing, err := s.repo.GetUserByEmail(ctx, email)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return User{}, err
+	}
+
+	if err != nil || !existing.PasswordHash.Valid {
+		VerifyPassword(dummyPasswordHash(), password)
+		return User{}, ErrInvalidCredentials
+	}
+
+	ok, err := VerifyPassword(existing.PasswordHash.String, password)
+	if err != nil {
+		return User{}, err
+	}
+	if !ok {
+		return User{}, ErrInvalidCredentials
+	}
+
+	authenticated := FromSQLC(existing)
+	if authenticated.Status == PendingVerification {
+		return User{}, ErrEmailNotVerified
+	}
+	if authenticated.Status != Active {
+		return User{}, ErrUserNotActive
+	}
+
+	return authenticated, nil
+}
+
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, _ := HashPassword(uuid.NewString())
+	return hash
+})
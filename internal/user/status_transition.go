@@ -0,0 +1,52 @@
+package user
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+	"user-management/internal/db/sqlc"
+
+	"github.com/google/uuid"
+)
+
+// StatusTransition records a status change of an user. ActorId is empty for
+// changes made by the system, such as email verification.
+type StatusTransition struct {
+	Id        uuid.UUID  `json:"id"`
+	UserId    uuid.UUID  `json:"userId"`
+	From      UserStatus `json:"from"`
+	To        UserStatus `json:"to"`
+	Reason    string     `json:"reason"`
+	ActorId   *uuid.UUID `json:"actorId,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+type UserStatusChangeRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+func StatusTransitionFromSQLC(t sqlc.UserStatusTransition) StatusTransition {
+	mapped := StatusTransition{
+		Id:        t.ID,
+		UserId:    t.UserID,
+		From:      parseStoredStatus(t.FromStatus, t.UserID),
+		To:        parseStoredStatus(t.ToStatus, t.UserID),
+		Reason:    t.Reason,
+		CreatedAt: t.CreatedAt,
+	}
+
+	if t.ActorID.Valid {
+		mapped.ActorId = &t.ActorID.UUID
+	}
+
+	return mapped
+}
+
+func parseStoredStatus(s string, userId uuid.UUID) UserStatus {
+	status, err := ParseUserStatus(s)
+	if err != nil {
+		slog.Error(fmt.Sprintf("Invalid status from DB, defaulting to INACTIVE userId %s", userId), "error", s)
+		return InActive
+	}
+	return status
+}
@@ -1,8 +1,7 @@
 package user
 
 import (
-	"fmt"
-	"log/slog"
+	"time"
 	"user-management/internal/db/sqlc"
 
 	"github.com/google/uuid"
@@ -16,9 +15,20 @@ type User struct {
 	Phone     string     `json:"phone" validate:"omitempty,e164"`
 	Age       int16      `json:"age" validate:"omitempty,gt=0"`
 	Status    UserStatus `json:"status" validate:"omitempty,userStatus"`
+
+	MfaEnabled bool `json:"mfaEnabled"`
+
+	// Version is incremented on every change and sent as the ETag.
+	Version int64 `json:"version"`
+
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+	DeletedBy *uuid.UUID `json:"deletedBy,omitempty"`
+
+	PasswordHash string `json:"-"`
+	MfaSecret    string `json:"-"`
 }
 
-func NewUser(firstName string, lastName string, email string, phone string, age int16) *User {
+func NewUser(firstName string, lastName string, email string, phone string, age int16, status UserStatus) *User {
 	return &User{
 		UserId:    uuid.New(),
 		FirstName: firstName,
@@ -26,7 +36,7 @@ func NewUser(firstName string, lastName string, email string, phone string, age
 		Email:     email,
 		Phone:     phone,
 		Age:       age,
-		Status:    Active,
+		Status:    status,
 	}
 }
 
@@ -45,17 +55,14 @@ func UpdatableUser(userId uuid.UUID, u *sqlc.User) (*User, error) {
 		Phone:     u.Phone,
 		Age:       u.Age,
 		Status:    status,
+		Version:   u.Version,
 	}, nil
 }
 
 func FromSQLC(u sqlc.User) User {
-	parsedStatus, err := ParseUserStatus(u.Status)
-	if err != nil {
-		slog.Error(fmt.Sprintf("Invalid status from DB, defaulting to INACTIVE userId %s", u.UserID), "error", u.Status)
-		parsedStatus = InActive
-	}
+	parsedStatus := parseStoredStatus(u.Status, u.UserID)
 
-	return User{
+	mapped := User{
 		UserId:    u.UserID,
 		FirstName: u.FirstName,
 		LastName:  u.LastName,
@@ -63,7 +70,23 @@ func FromSQLC(u sqlc.User) User {
 		Phone:     u.Phone,
 		Age:       u.Age,
 		Status:    parsedStatus,
+
+		MfaEnabled: u.MfaEnabled,
+
+		Version: u.Version,
+
+		PasswordHash: u.PasswordHash.String,
+		MfaSecret:    u.MfaSecret.String,
+	}
+
+	if u.DeletedAt.Valid {
+		mapped.DeletedAt = &u.DeletedAt.Time
 	}
+	if u.DeletedBy.Valid {
+		mapped.DeletedBy = &u.DeletedBy.UUID
+	}
+
+	return mapped
 }
 
 func FromSQLCList(users []sqlc.User) []User {
@@ -0,0 +1,46 @@
+package user
+
+import (
+	"user-management/internal/transfer"
+)
+
+// UserImport is an user read from an imported file. Empty fields keep the
+// values of an existing user, new users get the default status.
+type UserImport struct {
+	FirstName string `validate:"required,min=2,max=50"`
+	LastName  string `validate:"required,min=2,max=50"`
+	Email     string `validate:"required,email"`
+	Phone     string `validate:"omitempty,e164"`
+	Age       int16  `validate:"omitempty,gt=0"`
+	Status    string `validate:"omitempty,userStatus"`
+}
+
+// ImportFromRecord reads the firstName, lastName, email, phone, age and
+// status columns of a record. Other columns, like those of an export, are
+// ignored.
+func ImportFromRecord(rec transfer.Record) (UserImport, error) {
+	f := transfer.NewFields(rec)
+	u := UserImport{
+		FirstName: f.String("firstName"),
+		LastName:  f.String("lastName"),
+		Email:     f.String("email"),
+		Phone:     f.String("phone"),
+		Age:       f.Int16("age"),
+		Status:    f.String("status"),
+	}
+	return u, f.Err()
+}
+
+// ExportColumns are the columns of exported users.
+var ExportColumns = []transfer.Column[User]{
+	{Name: "id", Value: func(u User) any { return u.UserId.String() }},
+	{Name: "firstName", Value: func(u User) any { return u.FirstName }},
+	{Name: "lastName", Value: func(u User) any { return u.LastName }},
+	{Name: "email", Value: func(u User) any { return u.Email }},
+	{Name: "phone", Value: func(u User) any { return u.Phone }},
+	{Name: "age", Value: func(u User) any { return u.Age }},
+	{Name: "status", Value: func(u User) any { return u.Status.String() }},
+	{Name: "mfaEnabled", Value: func(u User) any { return u.MfaEnabled }},
+	{Name: "version", Value: func(u User) any { return u.Version }},
+	{Name: "deletedAt", Value: func(u User) any { return u.DeletedAt }},
+}
@@ -0,0 +1,6 @@
+package user
+
+type UserPasswordChangeRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword" validate:"required,min=8,max=128"`
+}
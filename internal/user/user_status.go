@@ -3,6 +3,7 @@ package user
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 )
 
 type UserStatus int
@@ -10,36 +11,73 @@ type UserStatus int
 const (
 	Active UserStatus = iota
 	InActive
+	PendingVerification
+	Suspended
+	Locked
+	Deleted
 )
 
+// stateName is the single definition of the statuses. String, ParseUserStatus,
+// the JSON encoding and the userStatus validator are all derived from it, and
+// the names are what is stored in the database.
 var stateName = map[UserStatus]string{
-	Active:   "Active",
-	InActive: "InActive",
+	Active:              "Active",
+	InActive:            "InActive",
+	PendingVerification: "PendingVerification",
+	Suspended:           "Suspended",
+	Locked:              "Locked",
+	Deleted:             "Deleted",
+}
+
+// transitions lists the statuses each status may change to. Deleted users are
+// restored to the status they had before they were deleted.
+var transitions = map[UserStatus][]UserStatus{
+	PendingVerification: {Active, Deleted},
+	Active:              {InActive, Suspended, Locked, Deleted},
+	InActive:            {Active, Suspended, Deleted},
+	Suspended:           {Active, Deleted},
+	Locked:              {Active, Suspended, Deleted},
+	Deleted:             {PendingVerification, Active, InActive, Suspended, Locked},
+}
+
+// Statuses returns every status in declaration order.
+func Statuses() []UserStatus {
+	statuses := make([]UserStatus, 0, len(stateName))
+	for s := range stateName {
+		statuses = append(statuses, s)
+	}
+	slices.Sort(statuses)
+	return statuses
 }
 
 func (s UserStatus) String() string {
 	return stateName[s]
 }
 
+func (s UserStatus) IsValid() bool {
+	_, ok := stateName[s]
+	return ok
+}
+
+// CanTransitionTo reports whether the transition table allows changing from s to next.
+func (s UserStatus) CanTransitionTo(next UserStatus) bool {
+	return slices.Contains(transitions[s], next)
+}
+
 func ParseUserStatus(s string) (UserStatus, error) {
-	switch s {
-	case "Active":
-		return Active, nil
-	case "InActive":
-		return InActive, nil
+	for status, name := range stateName {
+		if name == s {
+			return status, nil
+		}
 	}
 	return 0, fmt.Errorf("invalid user status: %s", s)
 }
 
 func (s UserStatus) MarshalJSON() ([]byte, error) {
-	switch s {
-	case 0:
-		return json.Marshal("Active")
-	case 1:
-		return json.Marshal("InActive")
-	default:
+	if !s.IsValid() {
 		return nil, fmt.Errorf("unknown status value")
 	}
+	return json.Marshal(s.String())
 }
 
 func (s *UserStatus) UnmarshalJSON(data []byte) error {
@@ -48,14 +86,11 @@ func (s *UserStatus) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	switch str {
-	case "Active":
-		*s = 0
-	case "InActive":
-		*s = 1
-	default:
+	status, err := ParseUserStatus(str)
+	if err != nil {
 		return fmt.Errorf("invalid status string: %s", str)
 	}
 
+	*s = status
 	return nil
 }
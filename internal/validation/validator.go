@@ -10,8 +10,15 @@ func RegisterValidations(validate *validator.Validate) {
 	validate.RegisterValidation("userStatus", validateUserStatus)
 }
 
+// validateUserStatus accepts user.UserStatus values and status names.
 func validateUserStatus(fl validator.FieldLevel) bool {
-	status := fl.Field().String()
-	_, err := user.ParseUserStatus(status)
-	return err == nil
+	switch v := fl.Field().Interface().(type) {
+	case user.UserStatus:
+		return v.IsValid()
+	case string:
+		_, err := user.ParseUserStatus(v)
+		return err == nil
+	default:
+		return false
+	}
 }
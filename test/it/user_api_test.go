@@ -11,6 +11,7 @@ import (
 	"strings"
 	"testing"
 	"user-management/internal/app"
+	"user-management/internal/config"
 	"user-management/internal/db"
 	"user-management/internal/user"
 
@@ -60,7 +61,11 @@ func TestMain(m *testing.M) {
 	dbConn := db.Connect(connStr)
 	defer dbConn.Close()
 
-	newApp := app.NewApp(dbConn)
+	newApp, err := app.NewApp(dbConn, &config.Config{})
+	if err != nil {
+		slog.Error("failed to initialize app", "error", err)
+		return
+	}
 
 	r := chi.NewRouter()
 	newApp.RegisterRoutes(r)
@@ -72,8 +77,7 @@ func TestCreateUserAPI(t *testing.T) {
         "lastName": "Viharagama",
         "email": "chethiya.viharagama@yaalalabs.com",
         "phone": "+94768680618",
-        "age": 11,
-        "status": "Active"
+        "age": 11
 	}`
 
 	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(reqBody))
@@ -103,8 +107,7 @@ func TestGetAllUsersAPI(t *testing.T) {
         "lastName": "User",
         "email": "test.user@example.com",
         "phone": "+94768680619",
-        "age": 25,
-        "status": "Active"
+        "age": 25
 	}`
 
 	createReq := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(reqBody))
@@ -132,8 +135,7 @@ func TestGetUserByIdAPI(t *testing.T) {
         "lastName": "Doe",
         "email": "john.doe@example.com",
         "phone": "+94768680620",
-        "age": 30,
-        "status": "Active"
+        "age": 30
 	}`
 
 	createReq := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(reqBody))
@@ -189,8 +191,7 @@ func TestUpdateUserAPI(t *testing.T) {
         "lastName": "Doe",
         "email": "jane.doe@example.com",
         "phone": "+94768680622",
-        "age": 29,
-        "status": "InActive"
+        "age": 29
 	}`
 
 	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(reqBody))
@@ -241,8 +242,7 @@ func TestDeleteUserAPI(t *testing.T) {
         "lastName": "Doe",
         "email": "jane.doe@example.com",
         "phone": "+94768680622",
-        "age": 29,
-        "status": "InActive"
+        "age": 29
 	}`
 
 	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(reqBody))
@@ -268,8 +268,7 @@ func TestDeleteUserWithMultipleUsersAPI(t *testing.T) {
         "lastName": "Doe",
         "email": "jane.doe@example.com",
         "phone": "+94768680622",
-        "age": 29,
-        "status": "InActive"
+        "age": 29
 	}`
 
 	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(reqBodyOne))
@@ -291,8 +290,7 @@ func TestDeleteUserWithMultipleUsersAPI(t *testing.T) {
         "lastName": "Nishanath",
         "email": "chethiya.nishanath@example.com",
         "phone": "+94768680600",
-        "age": 29,
-        "status": "InActive"
+        "age": 29
 	}`
 
 	reqTwo := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(reqBodyTwo))
@@ -0,0 +1,35 @@
+package apikey_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+	"user-management/internal/apikey"
+	"user-management/internal/db/sqlc"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromSQLC(t *testing.T) {
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	k := sqlc.ApiKey{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		Name:      "batch job",
+		Prefix:    "0123456789ab",
+		KeyHash:   "hash",
+		Scopes:    "instruments:read instruments:write",
+		ExpiresAt: sql.NullTime{Time: expiresAt, Valid: true},
+		CreatedAt: time.Now().UTC(),
+	}
+
+	mapped := apikey.FromSQLC(k)
+
+	assert.Equal(t, k.ID, mapped.Id)
+	assert.Equal(t, k.UserID, mapped.UserId)
+	assert.Equal(t, []string{"instruments:read", "instruments:write"}, mapped.Scopes)
+	assert.Equal(t, &expiresAt, mapped.ExpiresAt)
+	assert.Nil(t, mapped.LastUsedAt)
+}
@@ -0,0 +1,41 @@
+package apikey_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"user-management/internal/apikey"
+	"user-management/internal/middleware"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	handler := apikey.RateLimit(1, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	send := func(principal *middleware.Principal) int {
+		r := httptest.NewRequest("GET", "/instruments", nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+		if principal != nil {
+			r = r.WithContext(context.WithValue(r.Context(), middleware.PrincipalKey, principal))
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	key := &middleware.Principal{UserID: uuid.New(), APIKeyID: uuid.New()}
+	assert.Equal(t, http.StatusNoContent, send(key))
+	assert.Equal(t, http.StatusTooManyRequests, send(key), "the budget of the key is used up")
+	assert.Equal(t, http.StatusNoContent, send(&middleware.Principal{UserID: key.UserID, APIKeyID: uuid.New()}), "every key has its own budget")
+
+	token := &middleware.Principal{UserID: key.UserID}
+	assert.Equal(t, http.StatusNoContent, send(token), "requests with an access token are not limited per key")
+	assert.Equal(t, http.StatusNoContent, send(token))
+	assert.Equal(t, http.StatusNoContent, send(nil))
+}
@@ -0,0 +1,127 @@
+package audit_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"user-management/internal/audit"
+	"user-management/internal/db/sqlc"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type entity struct {
+	Name   string `json:"name"`
+	Age    int    `json:"age"`
+	Secret string `json:"-"`
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		before any
+		after  any
+		want   []audit.Change
+	}{
+		{
+			name:   "Create lists every field",
+			before: nil,
+			after:  entity{Name: "Jane", Age: 30},
+			want: []audit.Change{
+				{Field: "age", Before: nil, After: float64(30)},
+				{Field: "name", Before: nil, After: "Jane"},
+			},
+		},
+		{
+			name:   "Update lists changed fields only",
+			before: entity{Name: "Jane", Age: 30},
+			after:  entity{Name: "Jane", Age: 31},
+			want: []audit.Change{
+				{Field: "age", Before: float64(30), After: float64(31)},
+			},
+		},
+		{
+			name:   "Hidden fields are ignored",
+			before: entity{Name: "Jane", Secret: "a"},
+			after:  entity{Name: "Jane", Secret: "b"},
+			want:   []audit.Change{},
+		},
+		{
+			name:   "No sides",
+			before: nil,
+			after:  nil,
+			want:   []audit.Change{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := audit.Diff(tt.before, tt.after)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestHash(t *testing.T) {
+	record := sqlc.AuditLog{
+		ID:         uuid.New(),
+		ActorID:    uuid.NullUUID{UUID: uuid.New(), Valid: true},
+		RequestID:  "host/abc-000001",
+		Action:     audit.ActionUpdate,
+		EntityType: "user",
+		EntityID:   uuid.New(),
+		Changes:    `[{"field":"age","before":30,"after":31}]`,
+		PrevHash:   "previous",
+		CreatedAt:  time.Date(2025, 6, 1, 12, 0, 0, 123456000, time.UTC),
+	}
+
+	hash := audit.Hash(record)
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, audit.Hash(record), "hash must be deterministic")
+
+	record.Seq = 42
+	record.Hash = "ignored"
+	assert.Equal(t, hash, audit.Hash(record), "seq and hash are not covered")
+
+	tampered := record
+	tampered.Changes = `[{"field":"age","before":30,"after":32}]`
+	assert.NotEqual(t, hash, audit.Hash(tampered))
+
+	relinked := record
+	relinked.PrevHash = "other"
+	assert.NotEqual(t, hash, audit.Hash(relinked))
+
+	local := record
+	local.CreatedAt = record.CreatedAt.In(time.FixedZone("UTC+5", 5*60*60))
+	assert.Equal(t, hash, audit.Hash(local), "time zone must not change the hash")
+}
+
+func TestParseFilter(t *testing.T) {
+	id := uuid.New()
+
+	f, err := audit.ParseFilter(url.Values{
+		"entity": {"user"},
+		"id":     {id.String()},
+		"action": {"update"},
+		"from":   {"2025-06-01T00:00:00+02:00"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "user", f.EntityType)
+	assert.Equal(t, &id, f.EntityId)
+	assert.Nil(t, f.ActorId)
+	assert.Equal(t, "update", f.Action)
+	require.NotNil(t, f.From)
+	assert.Equal(t, time.Date(2025, 5, 31, 22, 0, 0, 0, time.UTC), *f.From)
+	assert.Nil(t, f.To)
+
+	_, err = audit.ParseFilter(url.Values{"id": {"not-a-uuid"}})
+	assert.Error(t, err)
+
+	_, err = audit.ParseFilter(url.Values{"to": {"yesterday"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,274 @@
+package batch_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"user-management/internal/batch"
+	"user-management/internal/common/apperror"
+	httputils "user-management/internal/common/httputils"
+	"user-management/internal/middleware"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type createRequest struct {
+	Name string `json:"name" validate:"required,min=2"`
+}
+
+type updateRequest struct {
+	Name string `json:"name" validate:"omitempty,min=2"`
+}
+
+type item = batch.Item[createRequest, updateRequest]
+
+var errConflict = apperror.New(apperror.Conflict, "name_taken", "name is already in use")
+
+func TestParse(t *testing.T) {
+	id := uuid.New()
+	req := batch.Request{Operations: []batch.Operation{
+		{Op: batch.Create, Data: json.RawMessage(`{"name":"Ann"}`)},
+		{Op: batch.Create, Data: json.RawMessage(`{"name":"A"}`)},
+		{Op: batch.Update, Data: json.RawMessage(`{"name":"Bob"}`)},
+		{Op: batch.Update, ID: id, Version: 3, Data: json.RawMessage(`{"name":"Bob"}`)},
+		{Op: batch.Delete, ID: id},
+		{Op: batch.Create, Data: json.RawMessage(`{"name":`)},
+		{Op: batch.Create},
+	}}
+
+	items, invalid, err := batch.Parse[createRequest, updateRequest](req, validator.New(), 10)
+	require.NoError(t, err)
+
+	require.Len(t, items, 3)
+	assert.Equal(t, 0, items[0].Index)
+	assert.Equal(t, "Ann", items[0].Create.Name)
+	assert.Equal(t, 3, items[1].Index)
+	assert.Equal(t, id, items[1].ID)
+	assert.Equal(t, int64(3), items[1].Version)
+	assert.Equal(t, "Bob", items[1].Update.Name)
+	assert.Equal(t, batch.Delete, items[2].Op)
+
+	indexes := make([]int, len(invalid))
+	for i, o := range invalid {
+		indexes[i] = o.Index
+		assert.Equal(t, apperror.Validation, apperror.KindOf(o.Err), "operation %d", o.Index)
+	}
+	assert.Equal(t, []int{1, 2, 5, 6}, indexes)
+}
+
+func TestParseTooManyOperations(t *testing.T) {
+	req := batch.Request{Operations: make([]batch.Operation, 3)}
+
+	_, _, err := batch.Parse[createRequest, updateRequest](req, validator.New(), 2)
+
+	assert.ErrorIs(t, err, batch.ErrTooManyOperations)
+}
+
+func TestInvalid(t *testing.T) {
+	err := batch.Invalid([]batch.Outcome{
+		{Index: 1, Err: httputils.ErrValidationFailed.WithFields(httputils.FieldError{Field: "Name", Message: "failed on the 'min' rule"})},
+		{Index: 4, Err: httputils.ErrInvalidRequest.WithMessage("Invalid JSON")},
+	})
+
+	var appErr *apperror.Error
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, "validation_failed", appErr.Code)
+	assert.Equal(t, []httputils.FieldError{
+		{Field: "operations[1].Name", Message: "failed on the 'min' rule"},
+		{Field: "operations[4]", Message: "Invalid JSON"},
+	}, appErr.Fields)
+}
+
+func TestFailed(t *testing.T) {
+	err := batch.Failed(&batch.ItemError{Index: 2, Err: errConflict})
+
+	var appErr *apperror.Error
+	require.ErrorAs(t, err, &appErr)
+	assert.ErrorIs(t, err, errConflict)
+	assert.Equal(t, []httputils.FieldError{{Field: "operations[2]", Message: "name is already in use"}}, appErr.Fields)
+
+	cause := errors.New("connection reset")
+	assert.Equal(t, cause, batch.Failed(cause))
+}
+
+func TestRun(t *testing.T) {
+	items := []item{
+		{Index: 0, Op: batch.Update, ID: uuid.New()},
+		{Index: 1, Op: batch.Create, Create: &createRequest{Name: "Ann"}},
+		{Index: 2, Op: batch.Delete, ID: uuid.New()},
+		{Index: 3, Op: batch.Create, Create: &createRequest{Name: "Bob"}},
+	}
+
+	var applied []int
+	createAll := func(ctx context.Context, items []item) ([]batch.Outcome, error) {
+		outcomes := make([]batch.Outcome, len(items))
+		for i, it := range items {
+			outcomes[i] = it.Done(uuid.New(), it.Create.Name)
+		}
+		return outcomes, nil
+	}
+	apply := func(ctx context.Context, it item) batch.Outcome {
+		applied = append(applied, it.Index)
+		if it.Op == batch.Delete {
+			return it.Failed(errConflict)
+		}
+		return it.Done(it.ID, nil)
+	}
+
+	t.Run("Best effort", func(t *testing.T) {
+		applied = nil
+		outcomes, err := batch.Run(context.Background(), items, false, createAll, apply)
+
+		require.NoError(t, err)
+		assert.Equal(t, []int{0, 2}, applied)
+		require.Len(t, outcomes, 4)
+		assert.Equal(t, 1, outcomes[0].Index)
+		assert.Equal(t, "Ann", outcomes[0].Value)
+		assert.ErrorIs(t, outcomes[3].Err, errConflict)
+	})
+
+	t.Run("Stop on error", func(t *testing.T) {
+		applied = nil
+		_, err := batch.Run(context.Background(), items, true, createAll, apply)
+
+		var itemErr *batch.ItemError
+		require.ErrorAs(t, err, &itemErr)
+		assert.Equal(t, 2, itemErr.Index)
+		assert.ErrorIs(t, err, errConflict)
+	})
+
+	t.Run("Best effort falls back to single creates", func(t *testing.T) {
+		applied = nil
+		failing := func(ctx context.Context, items []item) ([]batch.Outcome, error) {
+			return nil, errConflict
+		}
+		outcomes, err := batch.Run(context.Background(), items, false, failing, apply)
+
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 3, 0, 2}, applied)
+		assert.Len(t, outcomes, 4)
+	})
+
+	t.Run("Stop on error fails with the create error", func(t *testing.T) {
+		failing := func(ctx context.Context, items []item) ([]batch.Outcome, error) {
+			return nil, errConflict
+		}
+		_, err := batch.Run(context.Background(), items, true, failing, apply)
+
+		assert.Equal(t, errConflict, err)
+	})
+}
+
+func TestServe(t *testing.T) {
+	apply := func(ctx context.Context, mode batch.Mode, items []item) ([]batch.Outcome, error) {
+		var outcomes []batch.Outcome
+		for _, it := range items {
+			if it.Op == batch.Update {
+				if mode == batch.Transactional {
+					return nil, &batch.ItemError{Index: it.Index, Err: errConflict}
+				}
+				outcomes = append(outcomes, it.Failed(errConflict))
+				continue
+			}
+			outcomes = append(outcomes, it.Done(uuid.New(), nil))
+		}
+		return outcomes, nil
+	}
+
+	id := uuid.New().String()
+	tests := []struct {
+		name        string
+		body        string
+		permissions []string
+		want        int
+		wantCode    string
+		wantField   string
+		wantResults []int
+	}{
+		{
+			name:        "Transactional",
+			body:        `{"operations":[{"op":"create","data":{"name":"Ann"}},{"op":"delete","id":"` + id + `"}]}`,
+			permissions: []string{"things:delete"},
+			want:        http.StatusOK,
+			wantResults: []int{http.StatusCreated, http.StatusNoContent},
+		},
+		{
+			name:      "Transactional with invalid operation",
+			body:      `{"operations":[{"op":"create","data":{"name":"Ann"}},{"op":"create","data":{"name":"A"}}]}`,
+			want:      http.StatusBadRequest,
+			wantCode:  "validation_failed",
+			wantField: "operations[1].Name",
+		},
+		{
+			name:      "Transactional with failing operation",
+			body:      `{"operations":[{"op":"create","data":{"name":"Ann"}},{"op":"update","id":"` + id + `","data":{}}]}`,
+			want:      http.StatusConflict,
+			wantCode:  "name_taken",
+			wantField: "operations[1]",
+		},
+		{
+			name:        "Best effort",
+			body:        `{"mode":"best_effort","operations":[{"op":"update","id":"` + id + `","data":{}},{"op":"create","data":{"name":"A"}},{"op":"create","data":{"name":"Ann"}}]}`,
+			want:        http.StatusOK,
+			wantResults: []int{http.StatusConflict, http.StatusBadRequest, http.StatusCreated},
+		},
+		{
+			name:     "Unknown mode",
+			body:     `{"mode":"eventually","operations":[{"op":"create","data":{"name":"Ann"}}]}`,
+			want:     http.StatusBadRequest,
+			wantCode: "validation_failed",
+		},
+		{
+			name:     "Too many operations",
+			body:     `{"operations":[{"op":"create"},{"op":"create"},{"op":"create"},{"op":"create"}]}`,
+			want:     http.StatusBadRequest,
+			wantCode: "too_many_operations",
+		},
+		{
+			name:     "Delete without permission",
+			body:     `{"operations":[{"op":"delete","id":"` + id + `"}]}`,
+			want:     http.StatusForbidden,
+			wantCode: "forbidden",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal := &middleware.Principal{UserID: uuid.New(), Permissions: tt.permissions}
+			req := httptest.NewRequest(http.MethodPost, "/things:batch", strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), middleware.PrincipalKey, principal))
+			w := httptest.NewRecorder()
+
+			batch.Serve(w, req, validator.New(), 3, "things:delete", apply)
+
+			assert.Equal(t, tt.want, w.Code)
+			if tt.wantCode != "" {
+				var problem httputils.Problem
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+				assert.Equal(t, tt.wantCode, problem.Code)
+				if tt.wantField != "" {
+					require.NotEmpty(t, problem.Errors)
+					assert.Equal(t, tt.wantField, problem.Errors[0].Field)
+				}
+				return
+			}
+
+			var resp batch.Response
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+			statuses := make([]int, len(resp.Results))
+			for i, r := range resp.Results {
+				assert.Equal(t, i, r.Index)
+				statuses[i] = r.Status
+			}
+			assert.Equal(t, tt.wantResults, statuses)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"user-management/internal/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryName(t *testing.T) {
+	assert.Equal(t, "FindUserById", db.QueryName("-- name: FindUserById :one\nSELECT * FROM USERS"))
+	assert.Equal(t, db.UnnamedQuery, db.QueryName("SELECT 1"))
+}
+
+// fakeConn fails every statement with err.
+type fakeConn struct {
+	err error
+}
+
+func (c fakeConn) ExecContext(context.Context, string, ...interface{}) (sql.Result, error) {
+	return nil, c.err
+}
+
+func (c fakeConn) PrepareContext(context.Context, string) (*sql.Stmt, error) {
+	return nil, c.err
+}
+
+func (c fakeConn) QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error) {
+	return nil, c.err
+}
+
+func (c fakeConn) QueryRowContext(context.Context, string, ...interface{}) *sql.Row {
+	return nil
+}
+
+func TestObserve(t *testing.T) {
+	conn := fakeConn{err: errors.New("connection reset")}
+	require.Equal(t, conn, db.Observe(conn), "without hooks the connection is returned as is")
+
+	var calls []string
+	hook := func(label string) db.QueryHook {
+		return func(ctx context.Context, name string) (context.Context, func(err error)) {
+			calls = append(calls, label+" start "+name)
+			return ctx, func(err error) {
+				calls = append(calls, label+" end "+err.Error())
+			}
+		}
+	}
+	db.AddQueryHook(hook("outer"))
+	db.AddQueryHook(hook("inner"))
+
+	_, err := db.Observe(conn).ExecContext(context.Background(), "-- name: DeleteUser :exec\nDELETE FROM USERS")
+
+	assert.ErrorIs(t, err, conn.err)
+	assert.Equal(t, []string{
+		"outer start DeleteUser",
+		"inner start DeleteUser",
+		"inner end connection reset",
+		"outer end connection reset",
+	}, calls)
+}
+
+// tracedConn starts the QueryTracer for its queries as the pgx driver does,
+// keeping the context pgx ends them with once the rows are closed.
+type tracedConn struct {
+	fakeConn
+	queryCtx *context.Context
+}
+
+func (c tracedConn) QueryContext(ctx context.Context, _ string, _ ...interface{}) (*sql.Rows, error) {
+	*c.queryCtx = db.QueryTracer{}.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{})
+	return nil, nil
+}
+
+func TestObserveEndsQueriesWhenRowsClose(t *testing.T) {
+	var ended []error
+	db.AddQueryHook(func(ctx context.Context, name string) (context.Context, func(err error)) {
+		return ctx, func(err error) {
+			ended = append(ended, err)
+		}
+	})
+
+	var queryCtx context.Context
+	_, err := db.Observe(tracedConn{queryCtx: &queryCtx}).QueryContext(context.Background(), "-- name: ListUsers :many\nSELECT * FROM USERS")
+	require.NoError(t, err)
+	assert.Empty(t, ended, "the query runs until its rows are closed")
+
+	closeErr := errors.New("connection reset")
+	db.QueryTracer{}.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{Err: closeErr})
+	db.QueryTracer{}.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{})
+	assert.Equal(t, []error{closeErr}, ended)
+
+	ended = nil
+	_, err = db.Observe(fakeConn{err: closeErr}).QueryContext(context.Background(), "-- name: ListUsers :many\nSELECT * FROM USERS")
+	assert.ErrorIs(t, err, closeErr)
+	assert.Equal(t, []error{closeErr}, ended, "failed queries end when they return")
+}
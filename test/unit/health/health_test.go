@@ -0,0 +1,94 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"user-management/internal/health"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ok(ctx context.Context) error { return nil }
+
+func probe(t *testing.T, handler http.HandlerFunc) (int, health.Report) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var report health.Report
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&report))
+	return rec.Code, report
+}
+
+func TestReadiness(t *testing.T) {
+	c := health.NewChecker(time.Second)
+	c.Register("database", ok)
+	c.Register("migrations", ok)
+
+	status, report := probe(t, c.Readiness)
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, health.StatusOK, report.Status)
+	assert.Equal(t, map[string]health.CheckResult{
+		"database":   {Status: health.StatusOK},
+		"migrations": {Status: health.StatusOK},
+	}, report.Checks)
+}
+
+func TestReadinessFailingCheck(t *testing.T) {
+	c := health.NewChecker(time.Second)
+	c.Register("database", ok)
+	c.Register("migrations", func(ctx context.Context) error { return errors.New("2 of 15 migrations are pending") })
+
+	status, report := probe(t, c.Readiness)
+
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, health.StatusUnavailable, report.Status)
+	assert.Equal(t, health.StatusOK, report.Checks["database"].Status)
+	assert.Equal(t, health.CheckResult{Status: health.StatusUnavailable, Error: "2 of 15 migrations are pending"}, report.Checks["migrations"])
+}
+
+func TestReadinessTimeout(t *testing.T) {
+	c := health.NewChecker(10 * time.Millisecond)
+	c.Register("database", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	status, report := probe(t, c.Readiness)
+
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["database"].Error)
+}
+
+func TestRegisterReplacesCheck(t *testing.T) {
+	c := health.NewChecker(time.Second)
+	c.Register("database", func(ctx context.Context) error { return errors.New("down") })
+	c.Register("database", ok)
+
+	status, report := probe(t, c.Readiness)
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, report.Checks, 1)
+}
+
+func TestDrain(t *testing.T) {
+	c := health.NewChecker(time.Second)
+	c.Register("database", ok)
+	c.Drain()
+
+	status, report := probe(t, c.Readiness)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, health.ErrDraining.Error(), report.Checks["shutdown"].Error)
+
+	status, report = probe(t, c.Live)
+	assert.Equal(t, http.StatusOK, status, "liveness does not depend on draining")
+	assert.Equal(t, health.StatusOK, report.Status)
+}
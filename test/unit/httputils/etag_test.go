@@ -0,0 +1,54 @@
+package httputils_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	httputils "user-management/internal/common/httputils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestETagRoundTrip(t *testing.T) {
+	etag := httputils.ETag(42)
+	assert.Equal(t, `"42"`, etag)
+
+	version, err := httputils.ParseETag(etag)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), version)
+}
+
+func TestNotModified(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{name: "No header", header: "", want: false},
+		{name: "Same version", header: `"7"`, want: true},
+		{name: "Other version", header: `"6"`, want: false},
+		{name: "Weak tag", header: `W/"7"`, want: true},
+		{name: "List", header: `"5", "7"`, want: true},
+		{name: "Wildcard", header: "*", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+			if tt.header != "" {
+				req.Header.Set("If-None-Match", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			got := httputils.NotModified(w, req, httputils.ETag(7))
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, `"7"`, w.Header().Get("ETag"))
+			if tt.want {
+				assert.Equal(t, http.StatusNotModified, w.Code)
+			}
+		})
+	}
+}
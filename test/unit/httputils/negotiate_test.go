@@ -0,0 +1,45 @@
+package httputils_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	httputils "user-management/internal/common/httputils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiate(t *testing.T) {
+	offers := []string{"application/json", "text/csv", "application/x-ndjson"}
+
+	tests := []struct {
+		name   string
+		accept string
+		want   string
+		ok     bool
+	}{
+		{name: "No header", accept: "", want: "application/json", ok: true},
+		{name: "Any", accept: "*/*", want: "application/json", ok: true},
+		{name: "Exact", accept: "text/csv", want: "text/csv", ok: true},
+		{name: "Parameters are ignored", accept: "text/csv; charset=utf-8", want: "text/csv", ok: true},
+		{name: "Quality", accept: "application/json;q=0.2, application/x-ndjson;q=0.8", want: "application/x-ndjson", ok: true},
+		{name: "Specific range wins", accept: "text/*;q=0.1, */*;q=0.5, text/csv;q=0.9", want: "text/csv", ok: true},
+		{name: "Excluded offer", accept: "application/json;q=0, */*", want: "text/csv", ok: true},
+		{name: "Case insensitive", accept: "Text/CSV", want: "text/csv", ok: true},
+		{name: "No match", accept: "application/xml", want: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+
+			got, ok := httputils.Negotiate(req, offers...)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
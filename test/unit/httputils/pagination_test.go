@@ -0,0 +1,35 @@
+package httputils_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	httputils "user-management/internal/common/httputils"
+	"user-management/internal/db/query"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWritePageHeaders(t *testing.T) {
+	r := httptest.NewRequest("GET", "/users?page=3&limit=10&sort=-age", nil)
+	w := httptest.NewRecorder()
+	total := int64(42)
+
+	httputils.WritePageHeaders(w, r, query.PageInfo{Next: "n", Prev: "p", Total: &total})
+
+	assert.Equal(t, `</users?cursor=n&limit=10&sort=-age>; rel="next", </users?cursor=p&limit=10&sort=-age>; rel="prev"`, w.Header().Get("Link"))
+	assert.Equal(t, "42", w.Header().Get("X-Total-Count"))
+	assert.Empty(t, w.Header().Get("X-Total-Count-Estimate"))
+}
+
+func TestWritePageHeadersEstimatedTotal(t *testing.T) {
+	r := httptest.NewRequest("GET", "/instruments", nil)
+	w := httptest.NewRecorder()
+	total := int64(1000)
+
+	httputils.WritePageHeaders(w, r, query.PageInfo{Total: &total, Estimated: true})
+
+	assert.Empty(t, w.Header().Get("Link"))
+	assert.Empty(t, w.Header().Get("X-Total-Count"))
+	assert.Equal(t, "1000", w.Header().Get("X-Total-Count-Estimate"))
+}
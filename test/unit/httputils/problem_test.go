@@ -0,0 +1,137 @@
+package httputils_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"user-management/internal/common/apperror"
+	httputils "user-management/internal/common/httputils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var errThingNotFound = apperror.New(apperror.NotFound, "thing_not_found", "thing not found")
+
+func writeProblem(err error) (*httptest.ResponseRecorder, httputils.Problem) {
+	r := httptest.NewRequest(http.MethodGet, "/things/1", nil)
+	w := httptest.NewRecorder()
+	httputils.WriteProblem(w, r, err)
+
+	var problem httputils.Problem
+	json.NewDecoder(w.Body).Decode(&problem)
+	return w, problem
+}
+
+func TestWriteProblemMapsDomainErrors(t *testing.T) {
+	tests := []struct {
+		kind   apperror.Kind
+		status int
+	}{
+		{apperror.NotFound, http.StatusNotFound},
+		{apperror.Conflict, http.StatusConflict},
+		{apperror.Validation, http.StatusBadRequest},
+		{apperror.Unauthorized, http.StatusUnauthorized},
+		{apperror.Forbidden, http.StatusForbidden},
+		{apperror.PreconditionFailed, http.StatusPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.kind), func(t *testing.T) {
+			w, problem := writeProblem(apperror.New(tt.kind, "some_code", "some message"))
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, httputils.ProblemContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.status, problem.Status)
+			assert.Equal(t, "some_code", problem.Code)
+			assert.Equal(t, "some message", problem.Detail)
+			assert.Equal(t, http.StatusText(tt.status), problem.Title)
+			assert.Equal(t, "/things/1", problem.Instance)
+			assert.True(t, strings.HasSuffix(problem.Type, ":some_code"))
+		})
+	}
+}
+
+func TestWriteProblemUnwrapsWrappedErrors(t *testing.T) {
+	err := errThingNotFound.Wrap(sql.ErrNoRows)
+
+	w, problem := writeProblem(err)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "thing_not_found", problem.Code)
+	assert.Equal(t, "thing not found", problem.Detail)
+	assert.ErrorIs(t, err, errThingNotFound)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestWriteProblemHidesUnexpectedErrors(t *testing.T) {
+	w, problem := writeProblem(errors.New("connection refused"))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "internal_server_error", problem.Code)
+	assert.NotContains(t, problem.Detail, "connection refused")
+}
+
+func TestWriteProblemIncludesTraceID(t *testing.T) {
+	traceID := trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  trace.SpanID{1},
+	}))
+	r := httptest.NewRequestWithContext(ctx, http.MethodGet, "/things/1", nil)
+	w := httptest.NewRecorder()
+
+	httputils.WriteProblem(w, r, errThingNotFound)
+
+	var problem httputils.Problem
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", problem.TraceID)
+
+	_, problem = writeProblem(errThingNotFound)
+	assert.Empty(t, problem.TraceID)
+}
+
+func TestWriteProblemListsInvalidFields(t *testing.T) {
+	var req struct {
+		Email string `validate:"required,email"`
+	}
+	err := httputils.Validate(&req, validator.New())
+	require.Error(t, err)
+
+	w, problem := writeProblem(err)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "validation_failed", problem.Code)
+	assert.Equal(t, []httputils.FieldError{{Field: "Email", Message: "failed on the 'required' rule"}}, problem.Errors)
+}
+
+func TestDecodeAndValidateRequestRejectsInvalidJSON(t *testing.T) {
+	var req struct{}
+	r := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader("{"))
+
+	err := httputils.DecodeAndValidateRequest(r, &req, validator.New())
+
+	assert.ErrorIs(t, err, httputils.ErrInvalidRequest)
+	assert.Equal(t, apperror.Validation, apperror.KindOf(err))
+}
+
+func TestWriteErrorDerivesCodeFromStatus(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/things", nil)
+	w := httptest.NewRecorder()
+
+	httputils.WriteError(w, http.StatusPreconditionRequired, "If-Match header is required", r)
+
+	var problem httputils.Problem
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, "precondition_required", problem.Code)
+	assert.Equal(t, "If-Match header is required", problem.Detail)
+	assert.Equal(t, httputils.ProblemContentType, w.Header().Get("Content-Type"))
+}
@@ -0,0 +1,62 @@
+package instrument_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"user-management/internal/instrument"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	q := url.Values{
+		"exchange":      {"NASDAQ"},
+		"type":          {"EQUITY,ETF"},
+		"symbol_prefix": {"AA"},
+		"min_price":     {"1.5"},
+		"max_price":     {"100"},
+		"updated_since": {"2025-06-01T10:00:00+02:00"},
+		"sort":          {"-last_price,symbol"},
+	}
+
+	f, err := instrument.ParseFilter(q)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"NASDAQ"}, f.Exchanges)
+	assert.Equal(t, []string{"EQUITY", "ETF"}, f.Types)
+	assert.Equal(t, "AA", f.SymbolPrefix)
+	assert.Equal(t, 1.5, *f.MinPrice)
+	assert.Equal(t, 100.0, *f.MaxPrice)
+	assert.Equal(t, time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC), *f.UpdatedSince)
+	require.Len(t, f.Sort, 2)
+	assert.Equal(t, "LAST_PRICE", f.Sort[0].Column)
+	assert.True(t, f.Sort[0].Desc)
+}
+
+func TestParseFilterDefaultSort(t *testing.T) {
+	f, err := instrument.ParseFilter(url.Values{})
+	require.NoError(t, err)
+
+	require.Len(t, f.Sort, 1)
+	assert.Equal(t, "SYMBOL", f.Sort[0].Column)
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	tests := map[string]url.Values{
+		"Invalid price":        {"min_price": {"cheap"}},
+		"Negative price":       {"max_price": {"-1"}},
+		"Inverted price range": {"min_price": {"10"}, "max_price": {"5"}},
+		"Invalid time":         {"updated_since": {"yesterday"}},
+		"Unknown sort field":   {"sort": {"ID"}},
+	}
+
+	for name, q := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := instrument.ParseFilter(q)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"user-management/internal/config"
+	"user-management/internal/logging"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// capture installs a JSON logger as default logger and returns the buffer
+// it writes to.
+func capture(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	h, err := logging.New(config.Logging{Level: "DEBUG", Format: logging.FormatJSON}, &buf)
+	require.NoError(t, err)
+
+	previous := slog.Default()
+	slog.SetDefault(slog.New(h))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+// records decodes the JSON records written to buf.
+func records(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var got []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record), line)
+		got = append(got, record)
+	}
+	return got
+}
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	h, err := logging.New(config.Logging{Level: "WARN", Format: logging.FormatText}, &buf)
+	require.NoError(t, err)
+
+	logger := slog.New(h)
+	logger.Info("dropped")
+	logger.Warn("kept", "attempt", 2)
+	assert.NotContains(t, buf.String(), "dropped")
+	assert.Contains(t, buf.String(), `msg=kept attempt=2`)
+
+	_, err = logging.New(config.Logging{Format: "xml"}, &buf)
+	assert.ErrorContains(t, err, `unknown logging format "xml"`)
+}
+
+func TestParseLevel(t *testing.T) {
+	assert.Equal(t, slog.LevelDebug, logging.ParseLevel("debug"))
+	assert.Equal(t, slog.LevelWarn, logging.ParseLevel("WARNING"))
+	assert.Equal(t, slog.LevelError, logging.ParseLevel("Error"))
+	assert.Equal(t, slog.LevelInfo, logging.ParseLevel("verbose"))
+}
+
+func TestRedact(t *testing.T) {
+	buf := capture(t)
+
+	slog.Info("User created",
+		"email", "jane.doe@example.com",
+		"phone", "+94771234567",
+		"refreshToken", "m3C0b0a4yGkq1o6ZbS7d9v5cRr8s2JQmXnHh0Yt1Ue4",
+		"password", "S3cret-password",
+		"authorization", "Bearer eyJhbGciOi",
+		"error", errors.New("user john@example.com already exists"),
+		"keyId", "7f3e1c9a",
+		slog.Group("user", "email", "ann@example.org"),
+	)
+
+	got := records(t, buf)[0]
+	assert.Equal(t, "j***@example.com", got["email"])
+	assert.Equal(t, "***67", got["phone"])
+	assert.Equal(t, "[REDACTED]", got["refreshToken"])
+	assert.Equal(t, "[REDACTED]", got["password"])
+	assert.Equal(t, "[REDACTED]", got["authorization"])
+	assert.Equal(t, "user j***@example.com already exists", got["error"])
+	assert.Equal(t, "7f3e1c9a", got["keyId"])
+	assert.Equal(t, map[string]any{"email": "a***@example.org"}, got["user"])
+	assert.NotContains(t, buf.String(), "S3cret")
+}
+
+func TestMiddleware(t *testing.T) {
+	buf := capture(t)
+
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID)
+	r.Use(logging.Middleware)
+	r.Route("/users", func(r chi.Router) {
+		r.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				logging.SetUserID(r.Context(), "0b8a4f7e")
+				next.ServeHTTP(w, r)
+			})
+		})
+		r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+			slog.WarnContext(r.Context(), "Handling")
+			w.WriteHeader(http.StatusTeapot)
+			w.Write([]byte("short and stout"))
+		})
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+	got := records(t, buf)
+	require.Len(t, got, 2)
+
+	handling, access := got[0], got[1]
+	assert.Equal(t, "Handling", handling["msg"])
+	assert.NotEmpty(t, handling["request_id"])
+	assert.Equal(t, "/users/{id}", handling["route"])
+	assert.Equal(t, "0b8a4f7e", handling["user_id"])
+
+	assert.Equal(t, "Request completed", access["msg"])
+	assert.Equal(t, "INFO", access["level"])
+	assert.Equal(t, handling["request_id"], access["request_id"])
+	assert.Equal(t, "/users/{id}", access["route"])
+	assert.Equal(t, "0b8a4f7e", access["user_id"])
+	assert.Equal(t, "GET", access["method"])
+	assert.Equal(t, "/users/42", access["path"])
+	assert.Equal(t, float64(http.StatusTeapot), access["status"])
+	assert.Equal(t, float64(len("short and stout")), access["bytes"])
+}
+
+func TestMiddlewareLogsServerErrors(t *testing.T) {
+	buf := capture(t)
+
+	r := chi.NewRouter()
+	r.Use(logging.Middleware)
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	got := records(t, buf)
+	require.Len(t, got, 1)
+	assert.Equal(t, "ERROR", got[0]["level"])
+	assert.Nil(t, got[0]["user_id"])
+}
@@ -0,0 +1,74 @@
+package mail_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"user-management/internal/config"
+	"user-management/internal/mail"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_SelectsDriver(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Mail
+		want any
+	}{
+		{name: "default", cfg: config.Mail{}, want: &mail.LogMailer{}},
+		{name: "log", cfg: config.Mail{Driver: "log"}, want: &mail.LogMailer{}},
+		{name: "file", cfg: config.Mail{Driver: "file", Dir: t.TempDir()}, want: &mail.FileMailer{}},
+		{name: "smtp", cfg: config.Mail{Driver: "SMTP", SMTP: config.SMTP{Host: "localhost"}}, want: &mail.SMTPMailer{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := mail.New(tt.cfg)
+			require.NoError(t, err)
+			assert.IsType(t, tt.want, m)
+		})
+	}
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	invalid := map[string]config.Mail{
+		"unknown driver":    {Driver: "carrier-pigeon"},
+		"file without dir":  {Driver: "file"},
+		"smtp without host": {Driver: "smtp"},
+	}
+
+	for name, cfg := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := mail.New(cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFileMailer_WritesMessage(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mails")
+	m := mail.NewFileMailer(dir, "noreply@example.com")
+
+	err := m.Send(context.Background(), mail.Message{
+		To:      "john.doe@example.com",
+		Subject: "Verify your email address",
+		Body:    "Hello John,\nopen the link.",
+	})
+	require.NoError(t, err)
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, ".eml", filepath.Ext(files[0].Name()))
+
+	content, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "From: noreply@example.com\r\n")
+	assert.Contains(t, string(content), "To: john.doe@example.com\r\n")
+	assert.Contains(t, string(content), "Subject: Verify your email address\r\n")
+	assert.Contains(t, string(content), "\r\n\r\nHello John,\r\nopen the link.")
+}
@@ -0,0 +1,83 @@
+package metrics_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"user-management/internal/metrics"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMiddlewareLabelsByRoutePattern(t *testing.T) {
+	m := metrics.New()
+	r := chi.NewRouter()
+	r.Use(m.Middleware)
+	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	r.Get("/fail", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusInternalServerError) })
+
+	for _, path := range []string{"/users/1", "/users/2", "/fail", "/unknown"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	out := scrape(t, m)
+	assert.Contains(t, out, `http_requests_total{method="GET",route="/users/{id}",status="200"} 2`)
+	assert.Contains(t, out, `http_requests_total{method="GET",route="/fail",status="500"} 1`)
+	assert.Contains(t, out, `http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, out, `http_request_errors_total{method="GET",route="/fail",status="500"} 1`)
+	assert.NotContains(t, out, `http_request_errors_total{method="GET",route="/users/{id}"`)
+	assert.Contains(t, out, `http_request_duration_seconds_count{method="GET",route="/users/{id}",status="200"} 2`)
+}
+
+func TestObserveQuery(t *testing.T) {
+	m := metrics.New()
+
+	_, end := m.ObserveQuery(context.Background(), "FindUserById")
+	end(sql.ErrNoRows)
+	_, end = m.ObserveQuery(context.Background(), "FindUserById")
+	end(errors.New("connection reset"))
+
+	out := scrape(t, m)
+	assert.Contains(t, out, `db_query_duration_seconds_count{query="FindUserById"} 2`)
+	assert.Contains(t, out, `db_query_errors_total{query="FindUserById"} 1`)
+}
+
+func TestRegisterCounts(t *testing.T) {
+	m := metrics.New()
+	require.NoError(t, m.RegisterCounts("users_by_status", "Users by status.", "status", func(ctx context.Context) (map[string]int64, error) {
+		return map[string]int64{"Active": 3, "Suspended": 0}, nil
+	}))
+
+	out := scrape(t, m)
+	assert.Contains(t, out, `users_by_status{status="Active"} 3`)
+	assert.Contains(t, out, `users_by_status{status="Suspended"} 0`)
+}
+
+func TestRegisterCountsFailureKeepsOtherMetrics(t *testing.T) {
+	m := metrics.New()
+	require.NoError(t, m.RegisterCounts("users_by_status", "Users by status.", "status", func(ctx context.Context) (map[string]int64, error) {
+		return nil, errors.New("database is down")
+	}))
+
+	out := scrape(t, m)
+	assert.NotContains(t, out, "users_by_status{")
+	assert.Contains(t, out, "go_goroutines")
+}
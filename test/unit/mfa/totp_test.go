@@ -0,0 +1,91 @@
+package mfa_test
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"user-management/internal/mfa"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Secret of the SHA-1 test vectors in RFC 6238 appendix B.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode_RFC6238Vectors(t *testing.T) {
+	// The RFC lists eight digit codes; six digit codes are their last six digits.
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, expected := range vectors {
+		code, err := mfa.Code(rfcSecret, time.Unix(unix, 0))
+
+		require.NoError(t, err)
+		assert.Equal(t, expected, code, "time %d", unix)
+	}
+}
+
+func TestValidate_AllowsOneStepOfDrift(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	previous, err := mfa.Code(rfcSecret, now.Add(-30*time.Second))
+	require.NoError(t, err)
+
+	step, ok := mfa.Validate(rfcSecret, previous, now)
+
+	assert.True(t, ok)
+	assert.Equal(t, now.Unix()/30-1, step)
+}
+
+func TestValidate_RejectsOldCodes(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	old, err := mfa.Code(rfcSecret, now.Add(-90*time.Second))
+	require.NoError(t, err)
+
+	_, ok := mfa.Validate(rfcSecret, old, now)
+
+	assert.False(t, ok)
+}
+
+func TestValidate_RejectsWrongCode(t *testing.T) {
+	_, ok := mfa.Validate(rfcSecret, "000000", time.Unix(59, 0))
+
+	assert.False(t, ok)
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := mfa.GenerateSecret()
+	require.NoError(t, err)
+
+	code, err := mfa.Code(secret, time.Now())
+	require.NoError(t, err)
+	assert.Len(t, code, 6)
+
+	other, err := mfa.GenerateSecret()
+	require.NoError(t, err)
+	assert.NotEqual(t, secret, other)
+}
+
+func TestURI(t *testing.T) {
+	uri := mfa.URI("user-management", "john.doe@example.com", "JBSWY3DPEHPK3PXP")
+
+	parsed, err := url.Parse(uri)
+	require.NoError(t, err)
+
+	assert.Equal(t, "otpauth", parsed.Scheme)
+	assert.Equal(t, "totp", parsed.Host)
+	assert.Equal(t, "/user-management:john.doe@example.com", parsed.Path)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", parsed.Query().Get("secret"))
+	assert.Equal(t, "user-management", parsed.Query().Get("issuer"))
+	assert.Equal(t, "6", parsed.Query().Get("digits"))
+}
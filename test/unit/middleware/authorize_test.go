@@ -0,0 +1,144 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"user-management/internal/middleware"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type staticAuthenticator struct {
+	scheme    string
+	principal *middleware.Principal
+}
+
+func (a staticAuthenticator) Scheme() string {
+	return a.scheme
+}
+
+func (a staticAuthenticator) Authenticate(ctx context.Context, credential string) (*middleware.Principal, error) {
+	if credential != "valid" {
+		return nil, errors.New("invalid credential")
+	}
+	return a.principal, nil
+}
+
+func newRouter(principal *middleware.Principal, route func(r chi.Router)) http.Handler {
+	r := chi.NewRouter()
+	r.Use(middleware.Authenticate(staticAuthenticator{scheme: "Bearer", principal: principal}))
+	route(r)
+	return r
+}
+
+func ok(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestAuthenticate(t *testing.T) {
+	principal := &middleware.Principal{UserID: uuid.New()}
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          int
+	}{
+		{name: "Missing header", authorization: "", want: http.StatusUnauthorized},
+		{name: "Missing credential", authorization: "Bearer", want: http.StatusUnauthorized},
+		{name: "Invalid credential", authorization: "Bearer invalid", want: http.StatusUnauthorized},
+		{name: "Unsupported scheme", authorization: "Basic valid", want: http.StatusUnauthorized},
+		{name: "Valid credential", authorization: "Bearer valid", want: http.StatusOK},
+		{name: "Scheme is case insensitive", authorization: "bearer valid", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *middleware.Principal
+			r := newRouter(principal, func(r chi.Router) {
+				r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+					got, _ = middleware.PrincipalFrom(r.Context())
+					w.WriteHeader(http.StatusOK)
+				})
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.want, w.Code)
+			if tt.want == http.StatusOK {
+				assert.Equal(t, principal, got)
+			} else {
+				assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []string
+		want        int
+	}{
+		{name: "Has permission", permissions: []string{"instruments:read", "instruments:write"}, want: http.StatusOK},
+		{name: "Missing permission", permissions: []string{"instruments:read"}, want: http.StatusForbidden},
+		{name: "No permissions", permissions: nil, want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal := &middleware.Principal{UserID: uuid.New(), Permissions: tt.permissions}
+			r := newRouter(principal, func(r chi.Router) {
+				r.With(middleware.RequirePermission("instruments:write")).Patch("/instruments/{id}", ok)
+			})
+
+			req := httptest.NewRequest(http.MethodPatch, "/instruments/"+uuid.NewString(), nil)
+			req.Header.Set("Authorization", "Bearer valid")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+func TestRequireSelfOrPermission(t *testing.T) {
+	self := uuid.New()
+
+	tests := []struct {
+		name        string
+		target      uuid.UUID
+		permissions []string
+		want        int
+	}{
+		{name: "Own user", target: self, want: http.StatusOK},
+		{name: "Other user without permission", target: uuid.New(), want: http.StatusForbidden},
+		{name: "Other user with permission", target: uuid.New(), permissions: []string{"users:write"}, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal := &middleware.Principal{UserID: self, Permissions: tt.permissions}
+			r := newRouter(principal, func(r chi.Router) {
+				r.With(middleware.RequireSelfOrPermission("id", "users:write")).Put("/users/{id}/password", ok)
+			})
+
+			req := httptest.NewRequest(http.MethodPut, "/users/"+tt.target.String()+"/password", nil)
+			req.Header.Set("Authorization", "Bearer valid")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	httputils "user-management/internal/common/httputils"
+	"user-management/internal/middleware"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	type request struct {
+		Name string `json:"name"`
+	}
+	handler := middleware.MaxBodySize(16)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req request
+		if err := httputils.DecodeAndValidateRequest(r, &req, validator.New()); err != nil {
+			httputils.WriteProblem(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name     string
+		body     string
+		unsized  bool
+		want     int
+		wantCode string
+	}{
+		{name: "Within the limit", body: `{"name":"jane"}`, want: http.StatusNoContent},
+		{name: "Announced too large", body: `{"name":"jane doe"}`, want: http.StatusRequestEntityTooLarge, wantCode: "request_too_large"},
+		{name: "Read too large", body: `{"name":"jane doe"}`, unsized: true, want: http.StatusRequestEntityTooLarge, wantCode: "request_too_large"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(tt.body))
+			if tt.unsized {
+				r.ContentLength = -1
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.want, w.Code)
+			if tt.wantCode != "" {
+				var problem httputils.Problem
+				json.NewDecoder(w.Body).Decode(&problem)
+				assert.Equal(t, tt.wantCode, problem.Code)
+				assert.Equal(t, "Request body must not be larger than 16 bytes", problem.Detail)
+			}
+		})
+	}
+}
+
+func TestMaxBodySizeDisabled(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	w := httptest.NewRecorder()
+	middleware.MaxBodySize(0)(next).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(strings.Repeat("x", 1<<10))))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
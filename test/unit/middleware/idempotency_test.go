@@ -0,0 +1,187 @@
+package middleware_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"user-management/internal/middleware"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryStore is an in memory IdempotencyStore.
+type memoryStore struct {
+	mu        sync.Mutex
+	responses map[string]*middleware.IdempotentResponse
+	claims    map[string]string
+	claimed   int
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{responses: map[string]*middleware.IdempotentResponse{}, claims: map[string]string{}}
+}
+
+func (s *memoryStore) Claim(ctx context.Context, scope string, key string, fingerprint string) (string, *middleware.IdempotentResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stored, ok := s.responses[scope+key]; ok {
+		return "", stored, nil
+	}
+	s.claimed++
+	claim := strconv.Itoa(s.claimed)
+	s.responses[scope+key] = &middleware.IdempotentResponse{Fingerprint: fingerprint}
+	s.claims[scope+key] = claim
+	return claim, nil, nil
+}
+
+func (s *memoryStore) Complete(ctx context.Context, scope string, key string, claim string, resp middleware.IdempotentResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.claims[scope+key] != claim {
+		return middleware.ErrIdempotencyClaimLost
+	}
+	s.responses[scope+key] = &resp
+	return nil
+}
+
+func (s *memoryStore) Release(ctx context.Context, scope string, key string, claim string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.claims[scope+key] != claim {
+		return middleware.ErrIdempotencyClaimLost
+	}
+	delete(s.responses, scope+key)
+	delete(s.claims, scope+key)
+	return nil
+}
+
+// expire drops the claim of a key, as if it timed out and a retry claimed it.
+func (s *memoryStore) expire(scope string, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.claims[scope+key] = "retry"
+}
+
+func newIdempotentHandler(store middleware.IdempotencyStore, status int, calls *int) http.Handler {
+	return middleware.Idempotency(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write(body)
+	}))
+}
+
+func post(h http.Handler, key string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(middleware.IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplaysResponse(t *testing.T) {
+	calls := 0
+	h := newIdempotentHandler(newMemoryStore(), http.StatusCreated, &calls)
+
+	first := post(h, "key-1", `{"name":"a"}`)
+	retry := post(h, "key-1", `{"name":"a"}`)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, "application/json", retry.Header().Get("Content-Type"))
+	assert.Equal(t, "true", retry.Header().Get(middleware.IdempotentReplayedHeader))
+	assert.Empty(t, first.Header().Get(middleware.IdempotentReplayedHeader))
+}
+
+func TestIdempotencyRejectsDifferentBody(t *testing.T) {
+	calls := 0
+	h := newIdempotentHandler(newMemoryStore(), http.StatusCreated, &calls)
+
+	post(h, "key-1", `{"name":"a"}`)
+	w := post(h, "key-1", `{"name":"b"}`)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"idempotency_key_reused"`)
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	store := newMemoryStore()
+	calls := 0
+	h := newIdempotentHandler(store, http.StatusCreated, &calls)
+
+	// Claim the key as a request that is still running would.
+	body := `{"name":"a"}`
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+	req.Header.Set(middleware.IdempotencyKeyHeader, "key-1")
+	first := httptest.NewRecorder()
+	middleware.Idempotency(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w2 := post(h, "key-1", body)
+		assert.Equal(t, http.StatusConflict, w2.Code)
+		assert.Contains(t, w2.Body.String(), `"code":"idempotency_key_in_progress"`)
+		w.WriteHeader(http.StatusCreated)
+	})).ServeHTTP(first, req)
+
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Equal(t, 0, calls)
+}
+
+func TestIdempotencyDoesNotStoreServerErrors(t *testing.T) {
+	calls := 0
+	h := newIdempotentHandler(newMemoryStore(), http.StatusInternalServerError, &calls)
+
+	post(h, "key-1", `{"name":"a"}`)
+	w := post(h, "key-1", `{"name":"a"}`)
+
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestIdempotencyWithoutKey(t *testing.T) {
+	calls := 0
+	h := newIdempotentHandler(newMemoryStore(), http.StatusCreated, &calls)
+
+	post(h, "", `{"name":"a"}`)
+	post(h, "", `{"name":"a"}`)
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotencyKeyTooLong(t *testing.T) {
+	calls := 0
+	h := newIdempotentHandler(newMemoryStore(), http.StatusCreated, &calls)
+
+	w := post(h, strings.Repeat("k", 256), `{"name":"a"}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"idempotency_key_too_long"`)
+	assert.Equal(t, 0, calls)
+}
+
+func TestIdempotencyKeepsLostClaim(t *testing.T) {
+	store := newMemoryStore()
+	h := middleware.Idempotency(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		store.expire("", "key-1")
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	w := post(h, "key-1", `{"name":"a"}`)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "retry", store.claims["key-1"], "the key stays claimed by the retry")
+	assert.Zero(t, store.responses["key-1"].StatusCode, "the response is not stored over the claim of the retry")
+}
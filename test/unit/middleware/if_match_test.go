@@ -0,0 +1,50 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"user-management/internal/middleware"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      string
+		required    bool
+		want        int
+		wantVersion int64
+	}{
+		{name: "Missing and optional", want: http.StatusOK},
+		{name: "Missing and required", required: true, want: http.StatusPreconditionRequired},
+		{name: "Wildcard", header: "*", required: true, want: http.StatusOK},
+		{name: "Version", header: `"3"`, required: true, want: http.StatusOK, wantVersion: 3},
+		{name: "Weak tag", header: `W/"3"`, want: http.StatusBadRequest},
+		{name: "Unquoted", header: "3", want: http.StatusBadRequest},
+		{name: "List", header: `"3", "4"`, want: http.StatusBadRequest},
+		{name: "Zero", header: `"0"`, want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got int64
+			handler := middleware.IfMatch(tt.required)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Context().Value(middleware.IfMatchKey).(int64)
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodPatch, "/users/1", nil)
+			if tt.header != "" {
+				req.Header.Set("If-Match", tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.want, w.Code)
+			assert.Equal(t, tt.wantVersion, got)
+		})
+	}
+}
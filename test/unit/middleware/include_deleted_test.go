@@ -0,0 +1,50 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"user-management/internal/middleware"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIncludeDeleted(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		permissions []string
+		want        int
+		wantInclude bool
+	}{
+		{name: "Not requested", query: "", want: http.StatusOK},
+		{name: "Requested with permission", query: "?include_deleted=true", permissions: []string{"users:delete"}, want: http.StatusOK, wantInclude: true},
+		{name: "Requested without permission", query: "?include_deleted=true", permissions: []string{"users:read"}, want: http.StatusForbidden},
+		{name: "Explicitly false without permission", query: "?include_deleted=false", want: http.StatusOK},
+		{name: "Invalid value", query: "?include_deleted=maybe", permissions: []string{"users:delete"}, want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got bool
+			principal := &middleware.Principal{UserID: uuid.New(), Permissions: tt.permissions}
+			r := newRouter(principal, func(r chi.Router) {
+				r.With(middleware.IncludeDeleted("users:delete")).Get("/users", func(w http.ResponseWriter, r *http.Request) {
+					got = r.Context().Value(middleware.IncludeDeletedKey).(bool)
+					w.WriteHeader(http.StatusOK)
+				})
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/users"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer valid")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.want, w.Code)
+			assert.Equal(t, tt.wantInclude, got)
+		})
+	}
+}
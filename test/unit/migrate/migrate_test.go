@@ -0,0 +1,132 @@
+package migrate_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"user-management/internal/db/migrate"
+	"user-management/internal/db/migrations"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func file(body string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(body)}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_add_name.up.sql":        file("ALTER TABLE T ADD COLUMN NAME TEXT;"),
+		"0001_create_table.up.sql":    file("CREATE TABLE T (ID INT);"),
+		"0001_create_table.down.sql":  file("DROP TABLE T;"),
+		"0010_add_index.up.sql":       file("CREATE INDEX I ON T (ID);"),
+		"0010_add_index.down.sql":     file("DROP INDEX I;"),
+		"migrations.go":               file("package migrations"),
+		"README.md":                   file("not a migration"),
+		"0002_add_name.unrelated.txt": file(""),
+	}
+
+	got, err := migrate.Load(fsys)
+	require.NoError(t, err)
+
+	require.Len(t, got, 3)
+	assert.Equal(t, migrate.Migration{Version: 1, Name: "create_table", Up: "CREATE TABLE T (ID INT);", Down: "DROP TABLE T;"}, got[0])
+	assert.Equal(t, 2, got[1].Version)
+	assert.Empty(t, got[1].Down)
+	assert.Equal(t, 10, got[2].Version)
+}
+
+func TestLoadRejectsInvalidFiles(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+		want string
+	}{
+		{name: "Bad name", fsys: fstest.MapFS{"create_table.sql": file("")}, want: "name must look like"},
+		{name: "Zero version", fsys: fstest.MapFS{"0000_init.up.sql": file("")}, want: "version must be positive"},
+		{name: "Two names", fsys: fstest.MapFS{"0001_a.up.sql": file("A"), "0001_b.up.sql": file("B")}, want: "is also named"},
+		{name: "Down only", fsys: fstest.MapFS{"0001_a.down.sql": file("A")}, want: "has no up migration"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := migrate.Load(tt.fsys)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+// TestEmbeddedMigrations checks that every embedded migration can be
+// reverted and that versions have no gaps.
+func TestEmbeddedMigrations(t *testing.T) {
+	got, err := migrate.Load(migrations.FS)
+	require.NoError(t, err)
+	require.NotEmpty(t, got)
+
+	for i, m := range got {
+		assert.Equal(t, i+1, m.Version, "version of %s", m.Name)
+		assert.NotEmpty(t, m.Down, "down migration of %04d_%s", m.Version, m.Name)
+	}
+}
+
+var testMigrations = []migrate.Migration{
+	{Version: 1, Name: "one", Up: "1", Down: "-1"},
+	{Version: 2, Name: "two", Up: "2", Down: "-2"},
+	{Version: 3, Name: "three", Up: "3"},
+	{Version: 4, Name: "four", Up: "4", Down: "-4"},
+}
+
+func describe(steps []migrate.Step) []string {
+	var got []string
+	for _, s := range steps {
+		got = append(got, string(s.Direction)+" "+s.Migration.Name)
+	}
+	return got
+}
+
+func TestPlan(t *testing.T) {
+	tests := []struct {
+		name    string
+		applied []int
+		target  int
+		want    []string
+	}{
+		{name: "Fresh database", applied: nil, target: 4, want: []string{"up one", "up two", "up three", "up four"}},
+		{name: "Partially applied", applied: []int{1, 2}, target: 4, want: []string{"up three", "up four"}},
+		{name: "Up to date", applied: []int{1, 2, 3, 4}, target: 4, want: nil},
+		{name: "Missing version is applied", applied: []int{1, 3}, target: 3, want: []string{"up two"}},
+		{name: "Down", applied: []int{1, 2, 3, 4}, target: 3, want: []string{"down four"}},
+		{name: "Down to a version", applied: []int{1, 2}, target: 0, want: []string{"down two", "down one"}},
+		{name: "Up to a version", applied: []int{1}, target: 2, want: []string{"up two"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps, err := migrate.Plan(testMigrations, tt.applied, tt.target)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, describe(steps))
+		})
+	}
+}
+
+func TestPlanErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		applied []int
+		target  int
+		want    string
+	}{
+		{name: "Unknown target", applied: nil, target: 7, want: "version 7 does not exist"},
+		{name: "Negative target", applied: nil, target: -1, want: "version -1 does not exist"},
+		{name: "Irreversible", applied: []int{1, 2, 3, 4}, target: 2, want: "0003_three cannot be reverted"},
+		{name: "Unknown applied version", applied: []int{1, 2, 3, 4, 5}, target: 4, want: "version 5 is applied but unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := migrate.Plan(testMigrations, tt.applied, tt.target)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
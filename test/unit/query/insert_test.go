@@ -0,0 +1,26 @@
+package query_test
+
+import (
+	"testing"
+
+	"user-management/internal/db/query"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsert(t *testing.T) {
+	stmt, args := query.Insert("USERS", "ID, NAME").
+		Values("a1", "Ann").
+		Values("b2", "Bob").
+		Returning("ID, NAME, VERSION").
+		SQL()
+
+	assert.Equal(t, "INSERT INTO USERS (ID, NAME) VALUES ($1, $2), ($3, $4) RETURNING ID, NAME, VERSION", stmt)
+	assert.Equal(t, []any{"a1", "Ann", "b2", "Bob"}, args)
+}
+
+func TestInsertPanicsOnMissingValue(t *testing.T) {
+	assert.Panics(t, func() {
+		query.Insert("USERS", "ID, NAME").Values("a1")
+	})
+}
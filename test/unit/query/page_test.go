@@ -0,0 +1,118 @@
+package query_test
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+
+	"user-management/internal/db/query"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorCodecRoundTrip(t *testing.T) {
+	codec := query.NewCursorCodec("secret")
+	cur := query.Cursor{Sort: "-age,id", Values: []string{"30", "a1"}, Backward: true}
+
+	got, err := codec.Decode(codec.Encode(cur))
+
+	require.NoError(t, err)
+	assert.Equal(t, cur, got)
+}
+
+func TestCursorCodecRejectsTamperedCursors(t *testing.T) {
+	codec := query.NewCursorCodec("secret")
+	token := codec.Encode(query.Cursor{Sort: "id", Values: []string{"a1"}})
+	forged := query.NewCursorCodec("other").Encode(query.Cursor{Sort: "id", Values: []string{"a1"}})
+
+	for _, token := range []string{"", "garbage", token[:len(token)-2], "x" + token, forged} {
+		_, err := codec.Decode(token)
+		assert.ErrorIs(t, err, query.ErrInvalidCursor, token)
+	}
+}
+
+// fetchNumbers pages through the ids 1 to 25 ordered by id.
+func fetchNumbers(cur *query.Cursor, limit int, offset int) ([]int, error) {
+	var rows []int
+	for n := 1; n <= 25; n++ {
+		rows = append(rows, n)
+	}
+	if cur != nil {
+		after, _ := strconv.Atoi(cur.Values[0])
+		if cur.Backward {
+			slices.Reverse(rows)
+		}
+		rows = slices.DeleteFunc(rows, func(n int) bool {
+			return cur.Backward && n >= after || !cur.Backward && n <= after
+		})
+	}
+	rows = rows[min(offset, len(rows)):]
+	return rows[:min(limit, len(rows))], nil
+}
+
+func numberValues(n int) []string {
+	return []string{strconv.Itoa(n)}
+}
+
+func TestPaginateFollowsCursors(t *testing.T) {
+	codec := query.NewCursorCodec("secret")
+	keys := query.Sort{id}
+
+	first, err := query.Paginate(codec, keys, query.PageRequest{Limit: 10}, fetchNumbers, numberValues)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, first.Items)
+	assert.Empty(t, first.Prev)
+	require.NotEmpty(t, first.Next)
+
+	second, err := query.Paginate(codec, keys, query.PageRequest{Limit: 10, Cursor: first.Next}, fetchNumbers, numberValues)
+	require.NoError(t, err)
+	assert.Equal(t, []int{11, 12, 13, 14, 15, 16, 17, 18, 19, 20}, second.Items)
+
+	last, err := query.Paginate(codec, keys, query.PageRequest{Limit: 10, Cursor: second.Next}, fetchNumbers, numberValues)
+	require.NoError(t, err)
+	assert.Equal(t, []int{21, 22, 23, 24, 25}, last.Items)
+	assert.Empty(t, last.Next)
+
+	back, err := query.Paginate(codec, keys, query.PageRequest{Limit: 10, Cursor: last.Prev}, fetchNumbers, numberValues)
+	require.NoError(t, err)
+	assert.Equal(t, second.Items, back.Items)
+
+	start, err := query.Paginate(codec, keys, query.PageRequest{Limit: 10, Cursor: back.Prev}, fetchNumbers, numberValues)
+	require.NoError(t, err)
+	assert.Equal(t, first.Items, start.Items)
+	assert.Empty(t, start.Prev)
+	assert.NotEmpty(t, start.Next)
+}
+
+func TestPaginateWithOffset(t *testing.T) {
+	codec := query.NewCursorCodec("secret")
+
+	page, err := query.Paginate(codec, query.Sort{id}, query.PageRequest{Limit: 10, Offset: 10}, fetchNumbers, numberValues)
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{11, 12, 13, 14, 15, 16, 17, 18, 19, 20}, page.Items)
+	assert.NotEmpty(t, page.Prev)
+	assert.NotEmpty(t, page.Next)
+}
+
+func TestPaginateRejectsCursorOfAnotherSort(t *testing.T) {
+	codec := query.NewCursorCodec("secret")
+	token := codec.Encode(query.Cursor{Sort: "-id", Values: []string{"5"}})
+
+	_, err := query.Paginate(codec, query.Sort{id}, query.PageRequest{Limit: 10, Cursor: token}, fetchNumbers, numberValues)
+
+	assert.True(t, errors.Is(err, query.ErrInvalidCursor))
+}
+
+func TestParseCountMode(t *testing.T) {
+	for _, s := range []string{"", "exact", "estimated"} {
+		mode, err := query.ParseCountMode(s)
+		require.NoError(t, err)
+		assert.Equal(t, query.CountMode(s), mode)
+	}
+
+	_, err := query.ParseCountMode("all")
+	assert.ErrorIs(t, err, query.ErrInvalidCount)
+}
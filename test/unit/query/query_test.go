@@ -0,0 +1,104 @@
+package query_test
+
+import (
+	"testing"
+
+	"user-management/internal/db/query"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fields = map[string]query.SortField{
+	"lastName": {Column: "LAST_NAME", Type: "text"},
+	"age":      {Column: "AGE", Type: "smallint"},
+}
+
+var id = query.SortField{Name: "id", Column: "ID", Type: "uuid"}
+
+func TestBuilder(t *testing.T) {
+	sort := query.Sort{{Name: "age", Column: "AGE", Desc: true}}.With(id)
+
+	stmt, args := query.Select("ID, NAME", "USERS").
+		Where("DELETED_AT IS NULL").
+		Where("NAME ILIKE ? OR EMAIL ILIKE ?", "%a%", "%a%").
+		Where("AGE >= ?", 18).
+		OrderBy(sort).
+		Page(10, 20).
+		SQL()
+
+	assert.Equal(t, "SELECT ID, NAME FROM USERS WHERE (DELETED_AT IS NULL) AND (NAME ILIKE $1 OR EMAIL ILIKE $2) AND (AGE >= $3) ORDER BY AGE DESC, ID ASC LIMIT $4 OFFSET $5", stmt)
+	assert.Equal(t, []any{"%a%", "%a%", 18, 10, 20}, args)
+}
+
+func TestBuilderSeek(t *testing.T) {
+	keys := query.Sort{{Name: "age", Column: "AGE", Type: "smallint", Desc: true}}.With(id)
+
+	stmt, args := query.Select("ID", "USERS").
+		Where("DELETED_AT IS NULL").
+		Seek(keys, []string{"30", "a1"}).
+		OrderBy(keys).
+		SQL()
+
+	assert.Equal(t, "SELECT ID FROM USERS WHERE (DELETED_AT IS NULL) AND ((AGE < $1::text::smallint) OR (AGE = $2::text::smallint AND ID > $3::text::uuid)) ORDER BY AGE DESC, ID ASC", stmt)
+	assert.Equal(t, []any{"30", "30", "a1"}, args)
+}
+
+func TestBuilderCountSQL(t *testing.T) {
+	stmt, args := query.Select("ID", "USERS").
+		Where("AGE >= ?", 18).
+		CountSQL()
+
+	assert.Equal(t, "SELECT COUNT(*) FROM USERS WHERE (AGE >= $1)", stmt)
+	assert.Equal(t, []any{18}, args)
+}
+
+func TestBuilderPanicsOnArgumentMismatch(t *testing.T) {
+	assert.Panics(t, func() { query.Select("ID", "USERS").Where("AGE >= ?") })
+	assert.Panics(t, func() { query.Select("ID", "USERS").Where("AGE >= 1", 1) })
+}
+
+func TestLikePatternsEscapeWildcards(t *testing.T) {
+	assert.Equal(t, `%50\%\_off\\%`, query.Contains(`50%_off\`))
+	assert.Equal(t, `AA%`, query.Prefix("AA"))
+}
+
+func TestParseSort(t *testing.T) {
+	def := query.Sort{{Name: "lastName", Column: "LAST_NAME", Type: "text"}}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    query.Sort
+		wantErr bool
+	}{
+		{name: "Default", value: "", want: def},
+		{name: "Ascending and descending", value: "lastName,-age", want: query.Sort{
+			{Name: "lastName", Column: "LAST_NAME", Type: "text"},
+			{Name: "age", Column: "AGE", Type: "smallint", Desc: true},
+		}},
+		{name: "Unknown field", value: "password", wantErr: true},
+		{name: "Injection", value: "age;DROP TABLE USERS", wantErr: true},
+		{name: "Duplicate field", value: "age,-age", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := query.ParseSort(tt.value, fields, def)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, query.ErrInvalidSort)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSortString(t *testing.T) {
+	sort, err := query.ParseSort("-age,lastName", fields, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "-age,lastName,id", sort.With(id).String())
+	assert.Equal(t, "age,-lastName,-id", sort.With(id).Reverse().String())
+}
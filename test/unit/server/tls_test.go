@@ -0,0 +1,218 @@
+package server_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"user-management/internal/config"
+	"user-management/internal/server"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// certificate is a certificate with its key, signed by parent or self-signed.
+type certificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+func newCertificate(t *testing.T, name string, parent *certificate, isCA bool) *certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &certificate{cert: cert, key: key, der: der}
+}
+
+// write writes the certificate and its key as PEM files to dir.
+func (c *certificate) write(t *testing.T, dir string, name string) (certFile string, keyFile string) {
+	t.Helper()
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func (c *certificate) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+// serve starts a TLS server using the config of reloader.
+func serve(t *testing.T, reloader *server.CertReloader) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) > 0 {
+			w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+		}
+	}))
+	srv.TLS = reloader.TLSConfig()
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// get requests url trusting ca and presenting the client certificate, if any.
+func get(url string, ca *certificate, client *certificate) (*http.Response, error) {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	cfg := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+	if client != nil {
+		cfg.Certificates = []tls.Certificate{client.tlsCertificate()}
+	}
+	c := &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}}
+	defer c.CloseIdleConnections()
+	return c.Get(url)
+}
+
+func TestNewCertReloaderRejectsInvalidConfig(t *testing.T) {
+	dir := t.TempDir()
+	ca := newCertificate(t, "ca", nil, true)
+	certFile, keyFile := newCertificate(t, "localhost", ca, false).write(t, dir, "server")
+
+	tests := []struct {
+		name string
+		cfg  config.TLS
+		want string
+	}{
+		{name: "Missing key", cfg: config.TLS{CertFile: certFile}, want: "requires both certFile and keyFile"},
+		{name: "Unknown client auth", cfg: config.TLS{CertFile: certFile, KeyFile: keyFile, ClientAuth: "always"}, want: `unknown tls clientAuth "always"`},
+		{name: "Client auth without CAs", cfg: config.TLS{CertFile: certFile, KeyFile: keyFile, ClientAuth: server.ClientAuthRequire}, want: "requires a clientCAFile"},
+		{name: "Unknown version", cfg: config.TLS{CertFile: certFile, KeyFile: keyFile, MinVersion: "1.0"}, want: `unknown tls minVersion "1.0"`},
+		{name: "Missing file", cfg: config.TLS{CertFile: certFile, KeyFile: filepath.Join(dir, "missing.key")}, want: "missing.key"},
+		{name: "Empty CA file", cfg: config.TLS{CertFile: certFile, KeyFile: keyFile, ClientCAFile: keyFile, ClientAuth: server.ClientAuthOptional}, want: "no certificates found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := server.NewCertReloader(tt.cfg)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestCertReloaderReloadsCertificate(t *testing.T) {
+	dir := t.TempDir()
+	oldCA := newCertificate(t, "old-ca", nil, true)
+	newCA := newCertificate(t, "new-ca", nil, true)
+	certFile, keyFile := newCertificate(t, "localhost", oldCA, false).write(t, dir, "server")
+
+	reloader, err := server.NewCertReloader(config.TLS{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+	srv := serve(t, reloader)
+
+	res, err := get(srv.URL, oldCA, nil)
+	require.NoError(t, err)
+	res.Body.Close()
+
+	newCertificate(t, "localhost", newCA, false).write(t, dir, "server")
+	require.NoError(t, reloader.Reload())
+
+	_, err = get(srv.URL, oldCA, nil)
+	assert.Error(t, err, "the old certificate is no longer served")
+	res, err = get(srv.URL, newCA, nil)
+	require.NoError(t, err)
+	res.Body.Close()
+}
+
+func TestCertReloaderKeepsCertificateOnFailedReload(t *testing.T) {
+	dir := t.TempDir()
+	ca := newCertificate(t, "ca", nil, true)
+	certFile, keyFile := newCertificate(t, "localhost", ca, false).write(t, dir, "server")
+
+	reloader, err := server.NewCertReloader(config.TLS{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+	srv := serve(t, reloader)
+
+	require.NoError(t, os.WriteFile(keyFile, []byte("not a key"), 0o600))
+	assert.Error(t, reloader.Reload())
+
+	res, err := get(srv.URL, ca, nil)
+	require.NoError(t, err)
+	res.Body.Close()
+}
+
+func TestCertReloaderClientAuth(t *testing.T) {
+	dir := t.TempDir()
+	ca := newCertificate(t, "ca", nil, true)
+	certFile, keyFile := newCertificate(t, "localhost", ca, false).write(t, dir, "server")
+	caFile, _ := ca.write(t, dir, "ca")
+	client := newCertificate(t, "pricing-job", ca, false)
+	stranger := newCertificate(t, "stranger", newCertificate(t, "other-ca", nil, true), false)
+
+	tests := []struct {
+		name       string
+		clientAuth string
+		client     *certificate
+		wantErr    bool
+		wantPeer   string
+	}{
+		{name: "Required and presented", clientAuth: server.ClientAuthRequire, client: client, wantPeer: "pricing-job"},
+		{name: "Required but missing", clientAuth: server.ClientAuthRequire, wantErr: true},
+		{name: "Required but untrusted", clientAuth: server.ClientAuthRequire, client: stranger, wantErr: true},
+		{name: "Optional and presented", clientAuth: server.ClientAuthOptional, client: client, wantPeer: "pricing-job"},
+		{name: "Optional and missing", clientAuth: server.ClientAuthOptional},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reloader, err := server.NewCertReloader(config.TLS{
+				CertFile:     certFile,
+				KeyFile:      keyFile,
+				ClientCAFile: caFile,
+				ClientAuth:   tt.clientAuth,
+			})
+			require.NoError(t, err)
+			srv := serve(t, reloader)
+
+			res, err := get(srv.URL, ca, tt.client)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			peer, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPeer, string(peer))
+		})
+	}
+}
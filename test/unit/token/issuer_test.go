@@ -0,0 +1,252 @@
+package token_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"user-management/internal/config"
+	"user-management/internal/middleware"
+	"user-management/internal/token"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const hsSecret = "0123456789abcdef0123456789abcdef"
+
+func writeEdDSAKey(t *testing.T) string {
+	t.Helper()
+
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "ed25519.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	return path
+}
+
+func TestIssuer_IssueAndVerify(t *testing.T) {
+	tests := []struct {
+		name string
+		key  func(t *testing.T) config.SigningKey
+	}{
+		{
+			name: "HS256",
+			key: func(t *testing.T) config.SigningKey {
+				return config.SigningKey{Id: "hs", Algorithm: token.AlgorithmHS256, Secret: hsSecret}
+			},
+		},
+		{
+			name: "EdDSA",
+			key: func(t *testing.T) config.SigningKey {
+				return config.SigningKey{Id: "ed", Algorithm: token.AlgorithmEdDSA, PrivateKeyFile: writeEdDSAKey(t)}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer, err := token.NewIssuer(config.Auth{SigningKeys: []config.SigningKey{tt.key(t)}})
+			require.NoError(t, err)
+
+			userId := uuid.New()
+			raw, expiresAt, err := issuer.Issue(middleware.Principal{
+				UserID:      userId,
+				Email:       "john.doe@example.com",
+				Roles:       []string{"operator"},
+				Permissions: []string{"instruments:read", "instruments:write"},
+			})
+			require.NoError(t, err)
+			assert.WithinDuration(t, time.Now().Add(15*time.Minute), expiresAt, 5*time.Second)
+
+			principal, err := issuer.Authenticate(context.Background(), raw)
+			require.NoError(t, err)
+			assert.Equal(t, userId, principal.UserID)
+			assert.Equal(t, "john.doe@example.com", principal.Email)
+			assert.Equal(t, []string{"operator"}, principal.Roles)
+			assert.True(t, principal.HasPermission("instruments:write"))
+			assert.False(t, principal.HasPermission("users:delete"))
+		})
+	}
+}
+
+func TestIssuer_VerifyRejectsTamperedToken(t *testing.T) {
+	issuer, err := token.NewIssuer(config.Auth{})
+	require.NoError(t, err)
+
+	raw, _, err := issuer.Issue(middleware.Principal{UserID: uuid.New(), Email: "john.doe@example.com"})
+	require.NoError(t, err)
+
+	_, err = issuer.Verify(raw[:len(raw)-2] + "xx")
+	assert.ErrorIs(t, err, token.ErrInvalidToken)
+}
+
+func TestIssuer_VerifyRejectsExpiredToken(t *testing.T) {
+	issuer, err := token.NewIssuer(config.Auth{
+		SigningKeys: []config.SigningKey{{Id: "hs", Algorithm: token.AlgorithmHS256, Secret: hsSecret}},
+	})
+	require.NoError(t, err)
+
+	claims := token.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "user-management",
+			Subject:   uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Hour)),
+		},
+	}
+	expired := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	expired.Header["kid"] = "hs"
+
+	raw, err := expired.SignedString([]byte(hsSecret))
+	require.NoError(t, err)
+
+	_, err = issuer.Verify(raw)
+	assert.ErrorIs(t, err, token.ErrInvalidToken)
+}
+
+func TestIssuer_VerifyRejectsOtherIssuer(t *testing.T) {
+	keys := []config.SigningKey{{Id: "hs", Algorithm: token.AlgorithmHS256, Secret: hsSecret}}
+
+	other, err := token.NewIssuer(config.Auth{Issuer: "someone-else", SigningKeys: keys})
+	require.NoError(t, err)
+	issuer, err := token.NewIssuer(config.Auth{SigningKeys: keys})
+	require.NoError(t, err)
+
+	raw, _, err := other.Issue(middleware.Principal{UserID: uuid.New(), Email: "john.doe@example.com"})
+	require.NoError(t, err)
+
+	_, err = issuer.Verify(raw)
+	assert.ErrorIs(t, err, token.ErrInvalidToken)
+}
+
+func TestIssuer_MfaChallenge(t *testing.T) {
+	issuer, err := token.NewIssuer(config.Auth{})
+	require.NoError(t, err)
+
+	userId := uuid.New()
+
+	raw, expiresAt, err := issuer.IssueMfaChallenge(userId)
+	require.NoError(t, err)
+
+	verified, err := issuer.VerifyMfaChallenge(raw)
+	require.NoError(t, err)
+	assert.Equal(t, userId, verified.UserID)
+	assert.NotEqual(t, uuid.Nil, verified.ID)
+	assert.WithinDuration(t, expiresAt, verified.ExpiresAt, time.Second, "attempts are kept until the challenge expires")
+
+	other, _, err := issuer.IssueMfaChallenge(userId)
+	require.NoError(t, err)
+	second, err := issuer.VerifyMfaChallenge(other)
+	require.NoError(t, err)
+	assert.NotEqual(t, verified.ID, second.ID, "failed codes are counted per challenge")
+
+	_, err = issuer.Authenticate(context.Background(), raw)
+	assert.ErrorIs(t, err, token.ErrInvalidToken, "a challenge must not be accepted as access token")
+}
+
+func TestIssuer_AccessTokenIsNotMfaChallenge(t *testing.T) {
+	issuer, err := token.NewIssuer(config.Auth{})
+	require.NoError(t, err)
+
+	raw, _, err := issuer.Issue(middleware.Principal{UserID: uuid.New(), Email: "john.doe@example.com"})
+	require.NoError(t, err)
+
+	_, err = issuer.VerifyMfaChallenge(raw)
+	assert.ErrorIs(t, err, token.ErrInvalidToken)
+}
+
+func TestIssuer_KeyRotation(t *testing.T) {
+	oldKey := config.SigningKey{Id: "2025-01", Algorithm: token.AlgorithmHS256, Secret: hsSecret}
+	newKey := config.SigningKey{Id: "2025-06", Algorithm: token.AlgorithmEdDSA, PrivateKeyFile: writeEdDSAKey(t)}
+
+	before, err := token.NewIssuer(config.Auth{SigningKeys: []config.SigningKey{oldKey}})
+	require.NoError(t, err)
+
+	raw, _, err := before.Issue(middleware.Principal{UserID: uuid.New(), Email: "john.doe@example.com"})
+	require.NoError(t, err)
+
+	after, err := token.NewIssuer(config.Auth{
+		ActiveKeyId: newKey.Id,
+		SigningKeys: []config.SigningKey{oldKey, newKey},
+	})
+	require.NoError(t, err)
+
+	_, err = after.Verify(raw)
+	assert.NoError(t, err, "tokens signed by a retired key must verify while it is configured")
+
+	retired, err := token.NewIssuer(config.Auth{SigningKeys: []config.SigningKey{newKey}})
+	require.NoError(t, err)
+
+	_, err = retired.Verify(raw)
+	assert.ErrorIs(t, err, token.ErrInvalidToken)
+}
+
+func TestNewIssuer_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Auth
+	}{
+		{
+			name: "Short HS256 secret",
+			cfg:  config.Auth{SigningKeys: []config.SigningKey{{Id: "hs", Algorithm: token.AlgorithmHS256, Secret: "short"}}},
+		},
+		{
+			name: "Unknown algorithm",
+			cfg:  config.Auth{SigningKeys: []config.SigningKey{{Id: "x", Algorithm: "none"}}},
+		},
+		{
+			name: "Missing key id",
+			cfg:  config.Auth{SigningKeys: []config.SigningKey{{Algorithm: token.AlgorithmHS256, Secret: hsSecret}}},
+		},
+		{
+			name: "Unknown active key",
+			cfg: config.Auth{
+				ActiveKeyId: "missing",
+				SigningKeys: []config.SigningKey{{Id: "hs", Algorithm: token.AlgorithmHS256, Secret: hsSecret}},
+			},
+		},
+		{
+			name: "Missing private key file",
+			cfg:  config.Auth{SigningKeys: []config.SigningKey{{Id: "ed", Algorithm: token.AlgorithmEdDSA, PrivateKeyFile: "/does/not/exist.pem"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := token.NewIssuer(tt.cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestIssuer_JWKSPublishesOnlyPublicKeys(t *testing.T) {
+	issuer, err := token.NewIssuer(config.Auth{
+		ActiveKeyId: "ed",
+		SigningKeys: []config.SigningKey{
+			{Id: "hs", Algorithm: token.AlgorithmHS256, Secret: hsSecret},
+			{Id: "ed", Algorithm: token.AlgorithmEdDSA, PrivateKeyFile: writeEdDSAKey(t)},
+		},
+	})
+	require.NoError(t, err)
+
+	jwks := issuer.JWKS()
+
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "ed", jwks.Keys[0].Kid)
+	assert.Equal(t, "OKP", jwks.Keys[0].Kty)
+	assert.Equal(t, "Ed25519", jwks.Keys[0].Crv)
+	assert.NotEmpty(t, jwks.Keys[0].X)
+}
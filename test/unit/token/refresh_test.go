@@ -0,0 +1,96 @@
+package token_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+	"time"
+	"user-management/internal/db"
+	"user-management/internal/db/sqlc"
+	"user-management/internal/token"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsumeRevokedRefreshToken(t *testing.T) {
+	tests := []struct {
+		name      string
+		reason    string
+		revokeAll bool
+	}{
+		{name: "Rotated token reused", reason: token.RevokedRotated, revokeAll: true},
+		{name: "Logged out token retried", reason: token.RevokedLoggedOut},
+		{name: "Token of ended sessions retried", reason: token.RevokedSessionsEnded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now().UTC()
+			d := &tokenDriver{row: []driver.Value{uuid.NewString(), uuid.NewString(), "hash", now.Add(time.Hour), now, now, tt.reason}}
+			conn := sql.OpenDB(tokenConnector{d})
+			defer conn.Close()
+
+			service := token.NewService(nil, token.NewRepository(sqlc.New(conn)), time.Hour)
+
+			_, err := service.Consume(context.Background(), "refresh-token")
+			assert.ErrorIs(t, err, token.ErrInvalidRefreshToken)
+			if tt.revokeAll {
+				assert.Equal(t, []string{"RevokeUserRefreshTokens"}, d.execs)
+			} else {
+				assert.Empty(t, d.execs)
+			}
+		})
+	}
+}
+
+// tokenDriver returns row for every query and records the names of the
+// statements executed through it.
+type tokenDriver struct {
+	row   []driver.Value
+	execs []string
+}
+
+func (d *tokenDriver) Open(string) (driver.Conn, error) { return tokenConn{d}, nil }
+
+type tokenConn struct {
+	driver *tokenDriver
+}
+
+func (c tokenConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c tokenConn) Close() error                        { return nil }
+func (c tokenConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+func (c tokenConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.driver.execs = append(c.driver.execs, db.QueryName(query))
+	return driver.RowsAffected(1), nil
+}
+
+func (c tokenConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &tokenRows{row: c.driver.row}, nil
+}
+
+type tokenRows struct {
+	row []driver.Value
+}
+
+func (r *tokenRows) Columns() []string { return make([]string, len(r.row)) }
+func (r *tokenRows) Close() error      { return nil }
+
+func (r *tokenRows) Next(dest []driver.Value) error {
+	if r.row == nil {
+		return io.EOF
+	}
+	copy(dest, r.row)
+	r.row = nil
+	return nil
+}
+
+type tokenConnector struct {
+	driver *tokenDriver
+}
+
+func (c tokenConnector) Connect(context.Context) (driver.Conn, error) { return c.driver.Open("") }
+func (c tokenConnector) Driver() driver.Driver                        { return c.driver }
@@ -0,0 +1,132 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"user-management/internal/common/apperror"
+	"user-management/internal/config"
+	"user-management/internal/tracing"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// record installs a tracer provider recording the ended spans.
+func record(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	_, err := tracing.Setup(context.Background(), config.Tracing{Exporter: tracing.ExporterNone})
+	require.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestSetupRejectsUnknownExporter(t *testing.T) {
+	_, err := tracing.Setup(context.Background(), config.Tracing{Exporter: "jaeger"})
+	assert.ErrorContains(t, err, `unknown tracing exporter "jaeger"`)
+}
+
+func TestMiddleware(t *testing.T) {
+	recorder := record(t)
+
+	var traceID string
+	r := chi.NewRouter()
+	r.Use(tracing.Middleware)
+	// RequestID passes a copy of the request on, like the middlewares of the
+	// server.
+	r.Use(chimiddleware.RequestID)
+	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		traceID = tracing.TraceID(r.Context())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET /users/{id}", spans[0].Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+	assert.Contains(t, spans[0].Attributes(), attribute.String("http.route", "/users/{id}"))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+}
+
+func TestEnd(t *testing.T) {
+	recorder := record(t)
+
+	for _, err := range []error{
+		nil,
+		apperror.New(apperror.NotFound, "user_not_found", "user not found"),
+		errors.New("connection reset"),
+	} {
+		_, span := tracing.Start(context.Background(), "op")
+		tracing.End(span, &err)
+	}
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Empty(t, spans[0].Events())
+	assert.Equal(t, codes.Unset, spans[1].Status().Code, "client errors do not fail the span")
+	assert.Len(t, spans[1].Events(), 1)
+	assert.Equal(t, codes.Error, spans[2].Status().Code)
+}
+
+func TestObserveQuery(t *testing.T) {
+	recorder := record(t)
+
+	ctx, parent := tracing.Start(context.Background(), "user.Service.GetUserById")
+	_, end := tracing.ObserveQuery(ctx, "FindUserById")
+	end(errors.New("connection reset"))
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "FindUserById", spans[0].Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+}
+
+func TestTraceID(t *testing.T) {
+	record(t)
+	assert.Empty(t, tracing.TraceID(context.Background()))
+
+	ctx, span := tracing.Start(context.Background(), "op")
+	defer span.End()
+	assert.Equal(t, span.SpanContext().TraceID().String(), tracing.TraceID(ctx))
+}
+
+func TestLogHandler(t *testing.T) {
+	record(t)
+	var buf bytes.Buffer
+	logger := slog.New(tracing.LogHandler(slog.NewTextHandler(&buf, nil))).With("component", "test")
+
+	ctx, span := tracing.Start(context.Background(), "op")
+	defer span.End()
+	logger.InfoContext(ctx, "traced")
+	logger.Info("untraced")
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	assert.Contains(t, string(lines[0]), "trace_id="+span.SpanContext().TraceID().String())
+	assert.Contains(t, string(lines[0]), "component=test")
+	assert.NotContains(t, string(lines[1]), "trace_id")
+}
@@ -0,0 +1,302 @@
+package transfer_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"user-management/internal/common/apperror"
+	"user-management/internal/transfer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		path    string
+		want    transfer.Format
+		wantErr bool
+	}{
+		{name: "From extension", path: "users.CSV", want: transfer.CSV},
+		{name: "NDJSON extension", path: "users.ndjson", want: transfer.NDJSON},
+		{name: "JSON lines extension", path: "users.jsonl", want: transfer.NDJSON},
+		{name: "Flag wins", format: "ndjson", path: "users.csv", want: transfer.NDJSON},
+		{name: "Unknown extension", path: "users.xlsx", wantErr: true},
+		{name: "Unknown format", format: "xml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := transfer.ParseFormat(tt.format, tt.path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func readAll(t *testing.T, r transfer.Reader) []transfer.Record {
+	var records []transfer.Record
+	for {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return records
+		}
+		require.NoError(t, err)
+		records = append(records, rec)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	in := "\uFEFFsymbol, name ,last_price\nAAPL,Apple Inc.,226.43\nMSFT, Microsoft \nX,Y,1,extra\n"
+
+	r := transfer.NewReader(transfer.CSV, strings.NewReader(in))
+	records := readAll(t, r)
+
+	assert.Equal(t, []string{"symbol", "name", "last_price"}, r.Header())
+	require.Len(t, records, 3)
+	assert.Equal(t, 2, records[0].Line)
+	assert.Equal(t, map[string]string{"symbol": "AAPL", "name": "Apple Inc.", "last_price": "226.43"}, records[0].Values)
+	assert.Equal(t, map[string]string{"symbol": "MSFT", "name": "Microsoft"}, records[1].Values)
+	assert.NoError(t, records[1].Err)
+	assert.Error(t, records[2].Err)
+}
+
+func TestNDJSONReader(t *testing.T) {
+	in := `{"symbol":"AAPL","last_price":226.43,"active":true,"exchange":null}
+
+{"symbol":
+{"symbol":"X","tags":["a"]}
+{"symbol":"MSFT"}`
+
+	records := readAll(t, transfer.NewReader(transfer.NDJSON, strings.NewReader(in)))
+
+	require.Len(t, records, 4)
+	assert.Equal(t, map[string]string{"symbol": "AAPL", "last_price": "226.43", "active": "true", "exchange": ""}, records[0].Values)
+	assert.Equal(t, 3, records[1].Line)
+	assert.Error(t, records[1].Err)
+	assert.Error(t, records[2].Err)
+	assert.Equal(t, 5, records[3].Line)
+	assert.Equal(t, "MSFT", records[3].Values["symbol"])
+}
+
+type row struct {
+	Name      string
+	Price     float64
+	DeletedAt *time.Time
+}
+
+var columns = []transfer.Column[row]{
+	{Name: "name", Value: func(r row) any { return r.Name }},
+	{Name: "price", Value: func(r row) any { return r.Price }},
+	{Name: "deletedAt", Value: func(r row) any { return r.DeletedAt }},
+}
+
+func TestWriter(t *testing.T) {
+	deletedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	rows := []row{{Name: "Apple, Inc.", Price: 226.43}, {Name: "Gone", Price: 1, DeletedAt: &deletedAt}}
+
+	t.Run("CSV", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := transfer.NewWriter(transfer.CSV, &buf, columns)
+		for _, r := range rows {
+			require.NoError(t, w.Write(r))
+		}
+		require.NoError(t, w.Close())
+
+		assert.Equal(t, "name,price,deletedAt\n\"Apple, Inc.\",226.43,\nGone,1,2024-05-01T12:00:00Z\n", buf.String())
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := transfer.NewWriter(transfer.NDJSON, &buf, columns)
+		for _, r := range rows {
+			require.NoError(t, w.Write(r))
+		}
+		require.NoError(t, w.Close())
+
+		assert.Equal(t, `{"name":"Apple, Inc.","price":226.43,"deletedAt":null}`+"\n"+`{"name":"Gone","price":1,"deletedAt":"2024-05-01T12:00:00Z"}`+"\n", buf.String())
+	})
+
+	t.Run("CSV without rows has a header", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := transfer.NewWriter(transfer.CSV, &buf, columns)
+		require.NoError(t, w.Close())
+
+		assert.Equal(t, "name,price,deletedAt\n", buf.String())
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := transfer.NewWriter(transfer.JSON, &buf, columns)
+		for _, r := range rows {
+			require.NoError(t, w.Write(r))
+		}
+		require.NoError(t, w.Close())
+
+		var got []map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+		require.Len(t, got, 2)
+		assert.Equal(t, "Apple, Inc.", got[0]["name"])
+		assert.Equal(t, "2024-05-01T12:00:00Z", got[1]["deletedAt"])
+	})
+
+	t.Run("JSON without rows is an empty array", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := transfer.NewWriter(transfer.JSON, &buf, columns)
+		require.NoError(t, w.Close())
+
+		assert.JSONEq(t, "[]", buf.String())
+	})
+}
+
+func TestSelectColumns(t *testing.T) {
+	selected, err := transfer.SelectColumns(columns, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name", "price", "deletedAt"}, transfer.Names(selected))
+
+	selected, err = transfer.SelectColumns(columns, "price, name")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"price", "name"}, transfer.Names(selected))
+
+	_, err = transfer.SelectColumns(columns, "name,secret")
+	assert.ErrorContains(t, err, `unknown column "secret"`)
+}
+
+func serveExport(t *testing.T, target string, accept string, rows []row, exportErr error) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	transfer.ServeExport(rec, req, "rows", columns, func(ctx context.Context, fn func(row) error) error {
+		for _, r := range rows {
+			if err := fn(r); err != nil {
+				return err
+			}
+		}
+		return exportErr
+	})
+	return rec
+}
+
+func TestServeExport(t *testing.T) {
+	rows := []row{{Name: "Apple", Price: 1.5}}
+
+	t.Run("JSON by default", func(t *testing.T) {
+		rec := serveExport(t, "/rows/export", "", rows, nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="rows.json"`, rec.Header().Get("Content-Disposition"))
+		assert.JSONEq(t, `[{"name":"Apple","price":1.5,"deletedAt":null}]`, rec.Body.String())
+	})
+
+	t.Run("CSV with selected columns", func(t *testing.T) {
+		rec := serveExport(t, "/rows/export?columns=price,name", "text/csv", rows, nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "price,name\n1.5,Apple\n", rec.Body.String())
+	})
+
+	t.Run("NDJSON preferred by quality", func(t *testing.T) {
+		rec := serveExport(t, "/rows/export", "application/json;q=0.5, application/x-ndjson", rows, nil)
+
+		assert.Equal(t, "application/x-ndjson; charset=utf-8", rec.Header().Get("Content-Type"))
+	})
+
+	t.Run("Unknown column", func(t *testing.T) {
+		rec := serveExport(t, "/rows/export?columns=secret", "", rows, nil)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "unknown_column")
+	})
+
+	t.Run("Not acceptable", func(t *testing.T) {
+		rec := serveExport(t, "/rows/export", "application/xml", rows, nil)
+
+		assert.Equal(t, http.StatusNotAcceptable, rec.Code)
+	})
+
+	t.Run("Error before the first row", func(t *testing.T) {
+		rec := serveExport(t, "/rows/export", "", nil, apperror.New(apperror.Validation, "invalid_filter", "invalid filter"))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid_filter")
+	})
+}
+
+var errTaken = apperror.New(apperror.Conflict, "name_taken", "name is already in use")
+
+func apply(ctx context.Context, rec transfer.Record) (transfer.Action, error) {
+	f := transfer.NewFields(rec)
+	f.Float64("price")
+	if err := f.Err(); err != nil {
+		return transfer.Rejected, err
+	}
+
+	switch rec.Values["name"] {
+	case "taken":
+		return transfer.Rejected, errTaken
+	case "broken":
+		return transfer.Rejected, errors.New("connection reset")
+	case "same":
+		return transfer.Unchanged, nil
+	case "old":
+		return transfer.Updated, nil
+	}
+	return transfer.Created, nil
+}
+
+func TestImport(t *testing.T) {
+	in := "name,price\nnew,1\nold,2\nsame,3\ntaken,4\nbad,x\n"
+
+	var rejected bytes.Buffer
+	rejects := transfer.NewRejects(transfer.CSV, &rejected)
+	summary, err := transfer.Import(context.Background(), transfer.NewReader(transfer.CSV, strings.NewReader(in)), rejects, apply, nil)
+	require.NoError(t, err)
+	require.NoError(t, rejects.Flush())
+
+	assert.Equal(t, 5, summary.Records)
+	assert.Equal(t, map[transfer.Action]int{transfer.Created: 1, transfer.Updated: 1, transfer.Unchanged: 1, transfer.Rejected: 2}, summary.Actions)
+	assert.Equal(t, "5 records: 1 created, 1 updated, 1 unchanged, 2 rejected", summary.String())
+	assert.Equal(t, "name,price,line,error\ntaken,4,5,name is already in use\nbad,x,6,price: must be a number\n", rejected.String())
+}
+
+func TestImportNDJSONRejects(t *testing.T) {
+	in := "{\"name\":\"taken\"}\nnot json\n"
+
+	var rejected bytes.Buffer
+	rejects := transfer.NewRejects(transfer.NDJSON, &rejected)
+	_, err := transfer.Import(context.Background(), transfer.NewReader(transfer.NDJSON, strings.NewReader(in)), rejects, apply, nil)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(rejected.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, `{"line":1,"error":"name is already in use","record":{"name":"taken"}}`, lines[0])
+	assert.Contains(t, lines[1], `"record":"not json"`)
+}
+
+func TestImportStopsOnInternalError(t *testing.T) {
+	in := "name\nnew\nbroken\nnew\n"
+
+	summary, err := transfer.Import(context.Background(), transfer.NewReader(transfer.CSV, strings.NewReader(in)), nil, apply, nil)
+
+	assert.ErrorContains(t, err, "line 3: connection reset")
+	assert.Equal(t, 2, summary.Records)
+}
@@ -0,0 +1,30 @@
+package user_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"user-management/internal/db/sqlc"
+	"user-management/internal/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateUserRejectsStatus(t *testing.T) {
+	d := &recordingDriver{}
+	conn := sql.OpenDB(connector{d})
+	defer conn.Close()
+
+	service := user.NewService(user.NewRepository(conn, sqlc.New(conn)), nil, false)
+	status := user.Suspended
+
+	_, err := service.CreateUser(context.Background(), &user.UserCreateRequest{
+		FirstName: "Jane",
+		LastName:  "Doe",
+		Email:     "jane@example.com",
+		Status:    &status,
+	})
+
+	assert.ErrorIs(t, err, user.ErrStatusNotSettable)
+	assert.NotContains(t, d.statements, "CreateUser")
+}
@@ -0,0 +1,88 @@
+package user_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"user-management/internal/db"
+)
+
+// recordingDriver records the names of the statements run through it. Every
+// statement succeeds, queries return the rows given for their name, or none.
+type recordingDriver struct {
+	mu         sync.Mutex
+	statements []string
+	commits    int
+	rows       map[string][][]driver.Value
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) {
+	return &recordingConn{driver: d}, nil
+}
+
+func (d *recordingDriver) record(query string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.statements = append(d.statements, db.QueryName(query))
+}
+
+type recordingConn struct {
+	driver *recordingDriver
+}
+
+func (c *recordingConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *recordingConn) Close() error                        { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error)           { return recordingTx{driver: c.driver}, nil }
+
+func (c *recordingConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.driver.record(query)
+	return driver.RowsAffected(1), nil
+}
+
+func (c *recordingConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.driver.record(query)
+	return &fakeRows{values: c.driver.rows[db.QueryName(query)]}, nil
+}
+
+type recordingTx struct {
+	driver *recordingDriver
+}
+
+func (tx recordingTx) Commit() error {
+	tx.driver.mu.Lock()
+	defer tx.driver.mu.Unlock()
+	tx.driver.commits++
+	return nil
+}
+
+func (tx recordingTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	values [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	if len(r.values) == 0 {
+		return nil
+	}
+	return make([]string, len(r.values[0]))
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+type connector struct {
+	driver *recordingDriver
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) { return c.driver.Open("") }
+func (c connector) Driver() driver.Driver                        { return c.driver }
@@ -0,0 +1,67 @@
+package user_test
+
+import (
+	"net/url"
+	"testing"
+
+	"user-management/internal/db/query"
+	"user-management/internal/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	q := url.Values{
+		"status":       {"Active,Suspended", "Locked"},
+		"email_domain": {"@example.com"},
+		"min_age":      {"18"},
+		"max_age":      {"65"},
+		"name_prefix":  {"Che"},
+		"q":            {"viharagama"},
+		"sort":         {"lastName,-age"},
+	}
+
+	f, err := user.ParseFilter(q)
+	require.NoError(t, err)
+
+	assert.Equal(t, []user.UserStatus{user.Active, user.Suspended, user.Locked}, f.Statuses)
+	assert.Equal(t, "example.com", f.EmailDomain)
+	assert.Equal(t, int16(18), *f.MinAge)
+	assert.Equal(t, int16(65), *f.MaxAge)
+	assert.Equal(t, "Che", f.NamePrefix)
+	assert.Equal(t, "viharagama", f.Query)
+	require.Len(t, f.Sort, 2)
+	assert.Equal(t, "AGE", f.Sort[1].Column)
+	assert.True(t, f.Sort[1].Desc)
+}
+
+func TestParseFilterDefaults(t *testing.T) {
+	f, err := user.ParseFilter(url.Values{})
+	require.NoError(t, err)
+
+	assert.Empty(t, f.Statuses)
+	assert.Nil(t, f.MinAge)
+	require.Len(t, f.Sort, 2)
+	assert.Equal(t, "lastName", f.Sort[0].Name)
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	tests := map[string]struct {
+		q    url.Values
+		want error
+	}{
+		"Unknown status":     {q: url.Values{"status": {"Sleeping"}}, want: query.ErrInvalidFilter},
+		"Invalid age":        {q: url.Values{"min_age": {"old"}}, want: query.ErrInvalidFilter},
+		"Negative age":       {q: url.Values{"max_age": {"-1"}}, want: query.ErrInvalidFilter},
+		"Inverted age range": {q: url.Values{"min_age": {"40"}, "max_age": {"30"}}, want: query.ErrInvalidFilter},
+		"Unknown sort field": {q: url.Values{"sort": {"passwordHash"}}, want: query.ErrInvalidSort},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := user.ParseFilter(tt.q)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
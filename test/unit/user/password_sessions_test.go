@@ -0,0 +1,27 @@
+package user_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"user-management/internal/db/sqlc"
+	"user-management/internal/user"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetPasswordRevokesSessions(t *testing.T) {
+	d := &recordingDriver{}
+	conn := sql.OpenDB(connector{d})
+	defer conn.Close()
+
+	service := user.NewService(user.NewRepository(conn, sqlc.New(conn)), nil, false)
+
+	require.NoError(t, service.SetPassword(context.Background(), uuid.NewString(), "N3w-S3cret-password"))
+
+	assert.Contains(t, d.statements, "UpdateUserPassword")
+	assert.Contains(t, d.statements, "RevokeUserRefreshTokens", "refresh tokens must not outlive the password")
+	assert.Equal(t, 1, d.commits, "the password and the sessions change together")
+}
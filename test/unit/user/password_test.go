@@ -0,0 +1,84 @@
+package user_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"user-management/internal/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashPassword_ProducesArgon2idHash(t *testing.T) {
+	hash, err := user.HashPassword("S3cret-password")
+
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$"))
+	assert.NotContains(t, hash, "S3cret-password")
+}
+
+func TestHashPassword_UsesRandomSalt(t *testing.T) {
+	first, err := user.HashPassword("S3cret-password")
+	require.NoError(t, err)
+
+	second, err := user.HashPassword("S3cret-password")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first, second)
+}
+
+func TestVerifyPassword(t *testing.T) {
+	hash, err := user.HashPassword("S3cret-password")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		password string
+		want     bool
+	}{
+		{name: "Matching password", password: "S3cret-password", want: true},
+		{name: "Wrong password", password: "wrong-password", want: false},
+		{name: "Different case", password: "s3cret-password", want: false},
+		{name: "Empty password", password: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := user.VerifyPassword(hash, tt.password)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestVerifyPassword_InvalidHash(t *testing.T) {
+	invalidHashes := []string{
+		"",
+		"plaintext",
+		"$2a$10$abcdefghijklmnopqrstuv",
+		"$argon2id$v=19$m=65536,t=3,p=2$salt",
+		"$argon2id$v=18$m=65536,t=3,p=2$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=65536,t=3,p=2$!!!$aGFzaA",
+	}
+
+	for _, hash := range invalidHashes {
+		t.Run("Invalid_"+hash, func(t *testing.T) {
+			ok, err := user.VerifyPassword(hash, "password")
+			assert.ErrorIs(t, err, user.ErrInvalidPasswordHash)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestUser_PasswordHashIsNotSerialized(t *testing.T) {
+	u := user.NewUser("John", "Doe", "john.doe@example.com", "+1234567890", 30, user.Active)
+	u.PasswordHash = "$argon2id$v=19$m=65536,t=3,p=2$c2FsdA$aGFzaA"
+
+	b, err := json.Marshal(u)
+	require.NoError(t, err)
+
+	assert.NotContains(t, string(b), "argon2id")
+	assert.NotContains(t, strings.ToLower(string(b)), "password")
+}
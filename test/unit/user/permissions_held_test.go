@@ -0,0 +1,90 @@
+package user_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"user-management/internal/db/sqlc"
+	"user-management/internal/mfa"
+	"user-management/internal/middleware"
+	"user-management/internal/rbac"
+	"user-management/internal/user"
+	"user-management/internal/validation"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTakeoverOfUserHoldingMorePermissions covers the ways a holder of
+// users:write could log in as another user: resetting their password,
+// changing their email before a password reset and resetting their MFA.
+func TestTakeoverOfUserHoldingMorePermissions(t *testing.T) {
+	admin := uuid.New()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		change string
+	}{
+		{name: "Password reset", method: http.MethodPut, path: "/users/" + admin.String() + "/password", body: `{"newPassword":"N3w-S3cret-password"}`, change: "UpdateUserPassword"},
+		{name: "Email change", method: http.MethodPatch, path: "/users/" + admin.String(), body: `{"email":"attacker@example.com"}`, change: "UpdateUser"},
+		{name: "MFA reset", method: http.MethodDelete, path: "/users/" + admin.String() + "/mfa", change: "DisableUserMfa"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			send := func(permissions ...string) (*recordingDriver, *httptest.ResponseRecorder) {
+				d := &recordingDriver{rows: map[string][][]driver.Value{
+					"FindUserById":        {{admin.String(), "Ada", "Admin", "ada@example.com", "+94768680618", int64(30), "Active", nil, nil, true, nil, nil, nil, int64(1)}},
+					"ListUserPermissions": {{rbac.PermUsersWrite}, {rbac.PermRolesWrite}},
+				}}
+				conn := sql.OpenDB(connector{d})
+				defer conn.Close()
+
+				req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+				req.Header.Set("Content-Type", "application/json")
+				w := httptest.NewRecorder()
+				newRouter(conn, &middleware.Principal{UserID: uuid.New(), Permissions: permissions}).ServeHTTP(w, req)
+				return d, w
+			}
+
+			d, w := send(rbac.PermUsersRead, rbac.PermUsersWrite)
+			assert.Equal(t, http.StatusForbidden, w.Code)
+			assert.Contains(t, w.Body.String(), `"code":"permissions_exceeded"`)
+			assert.NotContains(t, d.statements, tt.change)
+
+			d, _ = send(rbac.PermUsersRead, rbac.PermUsersWrite, rbac.PermRolesWrite)
+			assert.Contains(t, d.statements, tt.change, "callers holding every permission of the user are allowed")
+		})
+	}
+}
+
+func newRouter(conn *sql.DB, principal *middleware.Principal) http.Handler {
+	queries := sqlc.New(conn)
+	validate := validator.New()
+	validation.RegisterValidations(validate)
+
+	users := user.NewService(user.NewRepository(conn, queries), nil, false)
+	userHandler := user.NewHandler(users, validate, nil, 1)
+	mfaHandler := mfa.NewHandler(mfa.NewService(mfa.NewRepository(conn, queries), users, "test"), validate)
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), middleware.PrincipalKey, principal)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, middleware.IfMatchKey, int64(0))))
+		})
+	})
+	r.Put("/users/{id}/password", userHandler.ChangeUserPassword)
+	r.Patch("/users/{id}", userHandler.UpdateUserById)
+	r.Delete("/users/{id}/mfa", mfaHandler.ResetUserMfa)
+	return r
+}
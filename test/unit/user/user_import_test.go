@@ -0,0 +1,38 @@
+package user_test
+
+import (
+	"testing"
+
+	"user-management/internal/common/apperror"
+	"user-management/internal/transfer"
+	"user-management/internal/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportFromRecord(t *testing.T) {
+	rec := transfer.Record{Values: map[string]string{
+		"id":        "ignored",
+		"firstName": "Jane",
+		"lastName":  "Doe",
+		"email":     "jane@example.com",
+		"age":       "30",
+		"status":    "Suspended",
+	}}
+
+	got, err := user.ImportFromRecord(rec)
+
+	require.NoError(t, err)
+	assert.Equal(t, user.UserImport{FirstName: "Jane", LastName: "Doe", Email: "jane@example.com", Age: 30, Status: "Suspended"}, got)
+}
+
+func TestImportFromRecordInvalidAge(t *testing.T) {
+	rec := transfer.Record{Values: map[string]string{"email": "jane@example.com", "age": "thirty"}}
+
+	_, err := user.ImportFromRecord(rec)
+
+	var appErr *apperror.Error
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, []apperror.FieldError{{Field: "age", Message: "must be a whole number"}}, appErr.Fields)
+}
@@ -1,11 +1,13 @@
 package user_test
 
 import (
+	"encoding/json"
 	"testing"
 
 	"user-management/internal/user"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUserStatus_String(t *testing.T) {
@@ -24,6 +26,26 @@ func TestUserStatus_String(t *testing.T) {
 			status: user.InActive,
 			want:   "InActive",
 		},
+		{
+			name:   "PendingVerification status returns 'PendingVerification'",
+			status: user.PendingVerification,
+			want:   "PendingVerification",
+		},
+		{
+			name:   "Suspended status returns 'Suspended'",
+			status: user.Suspended,
+			want:   "Suspended",
+		},
+		{
+			name:   "Locked status returns 'Locked'",
+			status: user.Locked,
+			want:   "Locked",
+		},
+		{
+			name:   "Deleted status returns 'Deleted'",
+			status: user.Deleted,
+			want:   "Deleted",
+		},
 	}
 
 	for _, tt := range tests {
@@ -132,8 +154,12 @@ func TestParseUserStatus(t *testing.T) {
 
 func TestParseUserStatus_ValidCases(t *testing.T) {
 	validCases := map[string]user.UserStatus{
-		"Active":   user.Active,
-		"InActive": user.InActive,
+		"Active":              user.Active,
+		"InActive":            user.InActive,
+		"PendingVerification": user.PendingVerification,
+		"Suspended":           user.Suspended,
+		"Locked":              user.Locked,
+		"Deleted":             user.Deleted,
 	}
 
 	for input, expected := range validCases {
@@ -155,6 +181,7 @@ func TestParseUserStatus_InvalidCases(t *testing.T) {
 		"inactive",
 		"pending",
 		"suspended",
+		"LOCKED",
 		"0",
 		"1",
 		" Active",
@@ -181,9 +208,7 @@ func TestParseUserStatus_ErrorMessage(t *testing.T) {
 }
 
 func TestParseUserStatus_RoundTrip(t *testing.T) {
-	statuses := []user.UserStatus{user.Active, user.InActive}
-
-	for _, status := range statuses {
+	for _, status := range user.Statuses() {
 		t.Run("RoundTrip_"+status.String(), func(t *testing.T) {
 			str := status.String()
 			parsed, err := user.ParseUserStatus(str)
@@ -192,3 +217,53 @@ func TestParseUserStatus_RoundTrip(t *testing.T) {
 		})
 	}
 }
+
+func TestUserStatus_JSONRoundTrip(t *testing.T) {
+	for _, status := range user.Statuses() {
+		t.Run(status.String(), func(t *testing.T) {
+			b, err := json.Marshal(status)
+			require.NoError(t, err)
+			assert.Equal(t, `"`+status.String()+`"`, string(b))
+
+			var decoded user.UserStatus
+			require.NoError(t, json.Unmarshal(b, &decoded))
+			assert.Equal(t, status, decoded)
+		})
+	}
+}
+
+func TestUserStatus_MarshalUnknown(t *testing.T) {
+	_, err := json.Marshal(user.UserStatus(99))
+	assert.Error(t, err)
+}
+
+func TestUserStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from user.UserStatus
+		to   user.UserStatus
+		want bool
+	}{
+		{user.PendingVerification, user.Active, true},
+		{user.PendingVerification, user.Suspended, false},
+		{user.Active, user.InActive, true},
+		{user.Active, user.Suspended, true},
+		{user.Active, user.Locked, true},
+		{user.Active, user.Deleted, true},
+		{user.Active, user.PendingVerification, false},
+		{user.Active, user.Active, false},
+		{user.InActive, user.Active, true},
+		{user.InActive, user.Locked, false},
+		{user.Suspended, user.Active, true},
+		{user.Suspended, user.Locked, false},
+		{user.Locked, user.Active, true},
+		{user.Locked, user.Suspended, true},
+		{user.Deleted, user.Active, true},
+		{user.Deleted, user.Deleted, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from.String()+"_to_"+tt.to.String(), func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.from.CanTransitionTo(tt.to))
+		})
+	}
+}